@@ -59,7 +59,9 @@ require (
 	github.com/jonboulle/clockwork v0.1.0 // indirect
 	github.com/json-iterator/go v0.0.0-20180701071628-ab8a2e0c74be
 	github.com/kr/pretty v0.1.0 // indirect
+	github.com/lib/pq v1.1.1
 	github.com/lithammer/dedent v1.1.0 // indirect
+	github.com/mattn/go-sqlite3 v1.10.0
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.1 // indirect
@@ -105,5 +107,5 @@ require (
 	k8s.io/kube-proxy v0.0.0-20190703212322-69d540a3479c // indirect
 	k8s.io/kubelet v0.0.0-20190704010802-f16c4cee528c // indirect
 	k8s.io/kubernetes v0.0.0-00010101000000-000000000000
-	sigs.k8s.io/yaml v1.1.0 // indirect
+	sigs.k8s.io/yaml v1.1.0
 )
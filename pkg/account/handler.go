@@ -10,9 +10,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"gopkg.in/asaskevich/govalidator.v8"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/message"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -83,17 +85,35 @@ func (h *Handler) Create(rw http.ResponseWriter, r *http.Request) {
 
 // ListAll retrieves all cloud accounts
 func (h *Handler) ListAll(rw http.ResponseWriter, r *http.Request) {
-	accounts, err := h.service.GetAll(r.Context())
+	opts, err := paging.FromQuery(r.URL.Query())
+	if err != nil {
+		message.SendValidationFailed(rw, err)
+		return
+	}
+
+	provider := clouds.Name(r.URL.Query().Get("provider"))
+
+	accounts, next, err := h.service.List(r.Context(), provider, opts)
 	if err != nil {
 		if sgerrors.IsNotFound(err) {
 			message.SendNotFound(rw, "accounts", err)
 			return
 		}
 
+		if paging.IsInvalid(err) {
+			message.SendValidationFailed(rw, err)
+			return
+		}
+
 		logrus.Errorf("account handler: list all %v", err)
 		message.SendUnknownError(rw, err)
 		return
 	}
+
+	if next != "" {
+		rw.Header().Set(paging.ContinueHeader, next)
+	}
+
 	if err := json.NewEncoder(rw).Encode(accounts); err != nil {
 		logrus.Errorf("account handler: list all %v", err)
 		message.SendUnknownError(rw, err)
@@ -17,7 +17,9 @@ import (
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/util"
 )
@@ -30,6 +32,30 @@ func (m *MockValidator) ValidateCredentials(cloudAccount *model.CloudAccount) er
 	return m.validate(cloudAccount.Credentials)
 }
 
+// azureProvider requires all azure credentials to be set
+type azureProvider struct {
+	providers.Provider
+}
+
+func (azureProvider) Name() clouds.Name {
+	return clouds.Azure
+}
+
+func (azureProvider) ValidateCredentials(creds map[string]string) error {
+	for _, k := range []string{
+		clouds.AzureTenantID,
+		clouds.AzureSubscriptionID,
+		clouds.AzureClientID,
+		clouds.AzureClientSecret,
+	} {
+		if creds[k] == "" {
+			return sgerrors.ErrInvalidCredentials
+		}
+	}
+
+	return nil
+}
+
 func fixtures() (*Handler, *testutils.MockStorage) {
 	mockStorage := new(testutils.MockStorage)
 	return &Handler{
@@ -45,6 +71,8 @@ func init() {
 }
 
 func TestEndpoint_CreateSuccess(t *testing.T) {
+	providers.Register(azureProvider{})
+
 	e, m := fixtures()
 	m.On("Put", mock.Anything, mock.Anything, mock.Anything,
 		mock.Anything).Return(nil)
@@ -309,9 +337,16 @@ func TestHandler_ListAll(t *testing.T) {
 
 	for _, testCase := range testCases {
 		e, m := fixtures()
-		m.On("GetAll", mock.Anything,
-			mock.Anything, mock.Anything, mock.Anything).
-			Return(testCase.mockResp, testCase.serviceErr)
+		var page *paging.Page
+		if testCase.mockResp != nil {
+			page = &paging.Page{}
+			for _, value := range testCase.mockResp {
+				page.Items = append(page.Items, paging.Item{Value: value})
+			}
+		}
+
+		m.On("List", mock.Anything, mock.Anything, mock.Anything).
+			Return(page, testCase.serviceErr)
 
 		router := mux.NewRouter()
 		e.Register(router)
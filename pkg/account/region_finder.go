@@ -22,6 +22,7 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/clouds/digitaloceansdk"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/util/strset"
@@ -30,49 +31,19 @@ import (
 
 var (
 	ErrNilAccount          = errors.New("nil account")
-	ErrUnsupportedProvider = errors.New("unsupported provider")
+	ErrUnsupportedProvider = sgerrors.ErrUnsupportedProvider
 
 	VMResourceType = "virtualMachines"
 )
 
-//Region represents
-type Region struct {
-	//Human readable name, e.g. New York City 1 or EU West 1 Frankfurt
-	Name string `json:"name"`
-	//API specific ID, e.g. t2.micro
-	ID string `json:"id"`
-
-	//API specific IDs for a node sizes/type
-	AvailableSizes []string
-}
-
-type Size struct {
-	RAM string `json:"ram"`
-	CPU string `json:"cpu"`
-}
-
-//RegionSizes represents aggregated information about available regions/azs and node sizes/types
-type RegionSizes struct {
-	Provider clouds.Name            `json:"provider"`
-	Regions  []*Region              `json:"regions"`
-	Sizes    map[string]interface{} `json:"sizes"`
-}
-
-type ZonesGetter interface {
-	GetZones(context.Context, steps.Config) ([]string, error)
-}
-
-type TypesGetter interface {
-	GetTypes(context.Context, steps.Config) ([]string, error)
-}
-
-//RegionsGetter is used to find a list of available regions(availability zones, etc) with available vm types
-//in a given cloud provider using given account credentials
-type RegionsGetter interface {
-	//GetRegions returns a slice of cloud specific regions/az's
-	//if not found would return an empty slice
-	GetRegions(context.Context) (*RegionSizes, error)
-}
+type (
+	Region        = providers.Region
+	Size          = providers.Size
+	RegionSizes   = providers.RegionSizes
+	ZonesGetter   = providers.ZonesGetter
+	TypesGetter   = providers.TypesGetter
+	RegionsGetter = providers.RegionsGetter
+)
 
 //NewRegionsGetter returns finder attached to corresponding account as it has all credentials for a cloud provider
 func NewRegionsGetter(account *model.CloudAccount, config *steps.Config) (RegionsGetter, error) {
@@ -80,20 +51,12 @@ func NewRegionsGetter(account *model.CloudAccount, config *steps.Config) (Region
 		return nil, ErrNilAccount
 	}
 
-	switch account.Provider {
-	case clouds.DigitalOcean:
-		return NewDOFinder(account)
-	case clouds.AWS:
-		// We need to provide region to AWS even if our
-		// request does not specify region
-		config.AWSConfig.Region = "us-west-1"
-		return NewAWSFinder(account, config)
-	case clouds.GCE:
-		return NewGCEFinder(account, config)
-	case clouds.Azure:
-		return NewAzureFinder(account, config)
+	p, err := providers.Get(account.Provider)
+	if err != nil {
+		return nil, ErrUnsupportedProvider
 	}
-	return nil, ErrUnsupportedProvider
+
+	return p.RegionsGetter(account, config)
 }
 
 //NewZonesGetter returns finder attached to corresponding
@@ -103,13 +66,12 @@ func NewZonesGetter(account *model.CloudAccount, config *steps.Config) (ZonesGet
 		return nil, ErrNilAccount
 	}
 
-	switch account.Provider {
-	case clouds.AWS:
-		return NewAWSFinder(account, config)
-	case clouds.GCE:
-		return NewGCEFinder(account, config)
+	p, err := providers.Get(account.Provider)
+	if err != nil {
+		return nil, ErrUnsupportedProvider
 	}
-	return nil, ErrUnsupportedProvider
+
+	return p.ZonesGetter(account, config)
 }
 
 //NewTypesGetter returns finder attached to corresponding
@@ -119,15 +81,12 @@ func NewTypesGetter(account *model.CloudAccount, config *steps.Config) (TypesGet
 		return nil, ErrNilAccount
 	}
 
-	switch account.Provider {
-	case clouds.AWS:
-		return NewAWSFinder(account, config)
-	case clouds.GCE:
-		return NewGCEFinder(account, config)
-	case clouds.Azure:
-		return NewAzureFinder(account, config)
+	p, err := providers.Get(account.Provider)
+	if err != nil {
+		return nil, ErrUnsupportedProvider
 	}
-	return nil, ErrUnsupportedProvider
+
+	return p.TypesGetter(account, config)
 }
 
 type digitalOceanRegionFinder struct {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -70,7 +71,26 @@ func (c fakeSKUSClient) ListComplete(ctx context.Context) (skus.ResourceSkusResu
 	return c.res, c.err
 }
 
+type doRegionsProvider struct {
+	providers.Provider
+}
+
+func (doRegionsProvider) Name() clouds.Name {
+	return clouds.DigitalOcean
+}
+
+func (doRegionsProvider) RegionsGetter(acc *model.CloudAccount, _ *steps.Config) (providers.RegionsGetter, error) {
+	finder, err := NewDOFinder(acc)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
 func TestGetRegionFinder(t *testing.T) {
+	providers.Register(doRegionsProvider{})
+
 	testCases := []struct {
 		account *model.CloudAccount
 		err     error
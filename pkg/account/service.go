@@ -8,9 +8,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
 )
 
 // Service holds all business logic related to cloud accounts
@@ -50,6 +52,34 @@ func (s *Service) GetAll(ctx context.Context) ([]model.CloudAccount, error) {
 	return accounts, nil
 }
 
+// List retrieves a page of cloud accounts of the provider or accounts of all
+// providers if it is empty, along with continue token of the next page.
+func (s *Service) List(ctx context.Context, provider clouds.Name, opts paging.Options) ([]model.CloudAccount, string, error) {
+	accounts := make([]model.CloudAccount, 0)
+
+	page, err := storage.ListFiltered(ctx, s.repository, s.storagePrefix, opts, func(item paging.Item) (bool, error) {
+		ca := model.CloudAccount{}
+		if err := json.Unmarshal(item.Value, &ca); err != nil {
+			logrus.Warningf("failed to convert stored data to cloud account struct")
+			logrus.Debugf("corrupted data: %s", string(item.Value))
+			return false, nil
+		}
+
+		if provider != "" && ca.Provider != provider {
+			return false, nil
+		}
+
+		accounts = append(accounts, ca)
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return accounts, page.Continue, nil
+}
+
 // Get retrieves a user by it's accountName, returns nil if not found
 func (s *Service) Get(ctx context.Context, accountName string) (*model.CloudAccount, error) {
 	res, err := s.repository.Get(ctx, s.storagePrefix, accountName)
@@ -86,6 +116,7 @@ func (s *Service) Create(ctx context.Context, account *model.CloudAccount) error
 		return sgerrors.ErrAlreadyExists
 	}
 
+	account.SchemaVersion = model.CloudAccountSchemaVersion
 	rawJSON, err := json.Marshal(account)
 	if err != nil {
 		return err
@@ -96,6 +127,7 @@ func (s *Service) Create(ctx context.Context, account *model.CloudAccount) error
 
 // Update cloud account
 func (s *Service) Update(ctx context.Context, account *model.CloudAccount) error {
+	account.SchemaVersion = model.CloudAccountSchemaVersion
 	rawJSON, err := json.Marshal(account)
 	if err != nil {
 		return errors.WithStack(err)
@@ -0,0 +1,410 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+)
+
+const (
+	// Version is a version of the archive format
+	Version = 1
+
+	manifestName = "manifest.json"
+	recordsName  = "records.jsonl"
+	logsDir      = "logs/"
+
+	// snapshotAttempts is amount of attempts to read storage
+	// without concurrent modifications.
+	snapshotAttempts = 5
+)
+
+var ErrNotEmpty = errors.New("storage is not empty")
+
+// Manifest describes content of the archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Records   int       `json:"records"`
+	Logs      int       `json:"logs"`
+	// Consistent is false when storage has been modified
+	// during every attempt to take a snapshot.
+	Consistent bool `json:"consistent"`
+}
+
+// Record is a single key value pair of the storage, value is
+// written exactly as it is stored, encrypted values stay encrypted.
+type Record struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Backup writes gzipped tar archive with all records of the storage
+// and logs of the stored tasks found in logDir.
+func Backup(ctx context.Context, s storage.Interface, logDir string, w io.Writer) (*Manifest, error) {
+	var (
+		records    []Record
+		consistent bool
+		err        error
+	)
+
+	for attempt := 1; attempt <= snapshotAttempts && !consistent; attempt++ {
+		records, consistent, err = snapshot(ctx, s)
+		if err != nil {
+			return nil, errors.Wrap(err, "snapshot")
+		}
+	}
+
+	if !consistent {
+		logrus.Warn("backup: storage has been modified during backup, " +
+			"archive may be inconsistent")
+	}
+
+	logs := taskLogs(records, logDir)
+
+	manifest := &Manifest{
+		Version:    Version,
+		CreatedAt:  time.Now().UTC(),
+		Records:    len(records),
+		Logs:       len(logs),
+		Consistent: consistent,
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal manifest")
+	}
+
+	if err := writeFile(tw, manifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0)
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal %s", r.Key)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	if err := writeFile(tw, recordsName, buf, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	for _, name := range logs {
+		if err := writeLog(tw, logDir, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "close tar")
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, errors.Wrap(err, "close gzip")
+	}
+
+	return manifest, nil
+}
+
+// Restore writes records and task logs from the archive to the storage
+// and logDir. Storage must be empty unless overwrite is set, in this case
+// keys that are missing in the archive are removed from the storage.
+func Restore(ctx context.Context, s storage.Interface, logDir string, r io.Reader, overwrite bool) (*Manifest, error) {
+	existing, err := s.ListKeys(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "list keys")
+	}
+
+	if len(existing) > 0 && !overwrite {
+		return nil, ErrNotEmpty
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "gzip")
+	}
+	defer gz.Close()
+
+	var (
+		manifest *Manifest
+		records  []Record
+		logs     []string
+		tmpDir   string
+	)
+
+	// Logs are unpacked to temporary directory first, so
+	// nothing is changed until the whole archive is read.
+	if logDir != "" {
+		tmpDir, err = ioutil.TempDir(logDir, ".restore")
+		if err != nil {
+			return nil, errors.Wrap(err, "create temp dir")
+		}
+		defer os.RemoveAll(tmpDir)
+	}
+
+	tr := tar.NewReader(gz)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read archive")
+		}
+
+		if manifest == nil && header.Name != manifestName {
+			return nil, errors.Errorf("%s must be the first file of archive", manifestName)
+		}
+
+		switch {
+		case header.Name == manifestName:
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, errors.Wrap(err, "decode manifest")
+			}
+
+			if manifest.Version != Version {
+				return nil, errors.Errorf("unsupported archive version %d", manifest.Version)
+			}
+		case header.Name == recordsName:
+			records, err = readRecords(tr)
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(header.Name, logsDir):
+			name := path.Base(header.Name)
+
+			if name != header.Name[len(logsDir):] || !strings.HasSuffix(name, ".log") {
+				return nil, errors.Errorf("wrong log file name %s", header.Name)
+			}
+
+			if tmpDir != "" {
+				if err := extract(tr, filepath.Join(tmpDir, name)); err != nil {
+					return nil, err
+				}
+			}
+			logs = append(logs, name)
+		default:
+			return nil, errors.Errorf("unexpected file %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, errors.Errorf("%s not found", manifestName)
+	}
+
+	if len(records) != manifest.Records || len(logs) != manifest.Logs {
+		return nil, errors.Errorf("archive is truncated, expected %d records %d logs, "+
+			"actual %d records %d logs", manifest.Records, manifest.Logs, len(records), len(logs))
+	}
+
+	restored := make(map[string]struct{}, len(records))
+
+	for _, r := range records {
+		if err := s.Put(ctx, "", r.Key, r.Value); err != nil {
+			return nil, errors.Wrapf(err, "put %s", r.Key)
+		}
+		restored[r.Key] = struct{}{}
+	}
+
+	for _, key := range existing {
+		if _, ok := restored[key]; ok {
+			continue
+		}
+
+		if err := s.Delete(ctx, "", key); err != nil && !sgerrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "delete %s", key)
+		}
+	}
+
+	for _, name := range logs {
+		if tmpDir == "" {
+			break
+		}
+
+		if err := os.Rename(filepath.Join(tmpDir, name), filepath.Join(logDir, name)); err != nil {
+			return nil, errors.Wrapf(err, "restore log %s", name)
+		}
+	}
+
+	return manifest, nil
+}
+
+// snapshot reads all records, records are consistent if storage
+// has not been modified while they were read.
+func snapshot(ctx context.Context, s storage.Interface) ([]Record, bool, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := s.Watch(watchCtx, "")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "watch")
+	}
+
+	keys, err := s.ListKeys(ctx, "")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "list keys")
+	}
+
+	records := make([]Record, 0, len(keys))
+	changed := false
+
+	for _, key := range keys {
+		value, err := s.Get(ctx, "", key)
+		if sgerrors.IsNotFound(err) {
+			changed = true
+			continue
+		}
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "get %s", key)
+		}
+
+		records = append(records, Record{
+			Key:   key,
+			Value: value,
+		})
+	}
+
+	// closed channel means events have been missed as well
+	select {
+	case <-events:
+		changed = true
+	default:
+	}
+
+	return records, !changed, nil
+}
+
+// taskLogs returns names of existing log files of the stored tasks
+func taskLogs(records []Record, logDir string) []string {
+	logs := make([]string, 0)
+
+	if logDir == "" {
+		return logs
+	}
+
+	for _, r := range records {
+		if !strings.HasPrefix(r.Key, workflows.Prefix) {
+			continue
+		}
+
+		name := util.MakeFileName(strings.TrimPrefix(r.Key, workflows.Prefix))
+		if name != filepath.Base(name) {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(logDir, name)); err == nil {
+			logs = append(logs, name)
+		}
+	}
+
+	return logs
+}
+
+func readRecords(r io.Reader) ([]Record, error) {
+	records := make([]Record, 0)
+	scanner := bufio.NewScanner(r)
+	// Records may contain big task configs
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "decode record %d", len(records)+1)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read records")
+	}
+
+	return records, nil
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "write %s header", name)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return errors.Wrapf(err, "write %s", name)
+	}
+
+	return nil
+}
+
+func writeLog(tw *tar.Writer, logDir, name string) error {
+	f, err := os.Open(filepath.Join(logDir, name))
+	if err != nil {
+		return errors.Wrapf(err, "open log %s", name)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "stat log %s", name)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:    logsDir + name,
+		Mode:    0600,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "write log %s header", name)
+	}
+
+	// Log may still be written by running task, copy
+	// only the part that has been declared in header.
+	if _, err := io.CopyN(tw, f, info.Size()); err != nil {
+		return errors.Wrapf(err, "write log %s", name)
+	}
+
+	return nil
+}
+
+func extract(r io.Reader, fileName string) error {
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return errors.Wrapf(err, "create %s", fileName)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.Wrapf(err, "extract %s", fileName)
+	}
+
+	return nil
+}
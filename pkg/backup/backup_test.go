@@ -0,0 +1,125 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/supergiant/control/pkg/storage/file"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+)
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewInMemoryRepository()
+
+	src.Put(ctx, "/supergiant/kubes/", "k1", []byte(`{"id":"k1"}`))
+	src.Put(ctx, "/supergiant/account/", "aws", []byte(`{"name":"aws"}`))
+	src.Put(ctx, workflows.Prefix, "t1", []byte(`{"id":"t1"}`))
+	src.Put(ctx, workflows.Prefix, "t2", []byte(`{"id":"t2"}`))
+
+	srcLogDir, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(srcLogDir)
+
+	ioutil.WriteFile(filepath.Join(srcLogDir, util.MakeFileName("t1")), []byte("task log"), 0600)
+	ioutil.WriteFile(filepath.Join(srcLogDir, "other.log"), []byte("other log"), 0600)
+
+	buf := &bytes.Buffer{}
+	manifest, err := Backup(ctx, src, srcLogDir, buf)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if manifest.Records != 4 || manifest.Logs != 1 || !manifest.Consistent {
+		t.Errorf("Wrong manifest %+v", manifest)
+	}
+
+	// Restore to another type of storage
+	dstLogDir, err := ioutil.TempDir("", "backup-dst")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dstLogDir)
+
+	dst, err := file.NewFileRepository(filepath.Join(dstLogDir, "supergiant.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	dst.Put(ctx, "/supergiant/kubes/", "k2", []byte(`{"id":"k2"}`))
+
+	if _, err := Restore(ctx, dst, dstLogDir, bytes.NewReader(buf.Bytes()), false); err != ErrNotEmpty {
+		t.Errorf("Expected error %v actual %v", ErrNotEmpty, err)
+	}
+
+	manifest, err = Restore(ctx, dst, dstLogDir, bytes.NewReader(buf.Bytes()), true)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if manifest.Records != 4 || manifest.Logs != 1 {
+		t.Errorf("Wrong manifest %+v", manifest)
+	}
+
+	expected, _ := src.ListKeys(ctx, "")
+	actual, _ := dst.ListKeys(ctx, "")
+
+	if len(expected) != len(actual) {
+		t.Fatalf("Wrong keys expected %v actual %v", expected, actual)
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("Wrong key expected %s actual %s", expected[i], actual[i])
+		}
+
+		expectedValue, _ := src.Get(ctx, "", expected[i])
+		actualValue, _ := dst.Get(ctx, "", actual[i])
+
+		if !bytes.Equal(expectedValue, actualValue) {
+			t.Errorf("Wrong value of %s expected %s actual %s",
+				expected[i], expectedValue, actualValue)
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dstLogDir, util.MakeFileName("t1")))
+	if err != nil || string(data) != "task log" {
+		t.Errorf("Wrong task log %s error %v", data, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dstLogDir, "other.log")); !os.IsNotExist(err) {
+		t.Errorf("Log of unknown task must not be restored %v", err)
+	}
+}
+
+func TestRestoreTruncated(t *testing.T) {
+	ctx := context.Background()
+	src := memory.NewInMemoryRepository()
+	src.Put(ctx, "/supergiant/kubes/", "k1", []byte(`{"id":"k1"}`))
+
+	buf := &bytes.Buffer{}
+	if _, err := Backup(ctx, src, "", buf); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	dst := memory.NewInMemoryRepository()
+	truncated := buf.Bytes()[:buf.Len()/2]
+
+	if _, err := Restore(ctx, dst, "", bytes.NewReader(truncated), false); err == nil {
+		t.Errorf("Error must not be nil")
+	}
+
+	if keys, _ := dst.ListKeys(ctx, ""); len(keys) != 0 {
+		t.Errorf("Nothing must be restored from broken archive %v", keys)
+	}
+}
@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+)
+
+// Handler is a http controller for backup and restore of the storage
+type Handler struct {
+	storage storage.Interface
+	logDir  string
+}
+
+func NewHandler(s storage.Interface, logDir string) *Handler {
+	return &Handler{
+		storage: s,
+		logDir:  logDir,
+	}
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc("/backup", h.Backup).Methods(http.MethodGet)
+	r.HandleFunc("/restore", h.Restore).Methods(http.MethodPost)
+}
+
+// Backup sends archive with all stored records and task logs
+func (h *Handler) Backup(rw http.ResponseWriter, r *http.Request) {
+	// Archive is written to temporary file first, so
+	// error can be reported before response is started.
+	f, err := ioutil.TempFile("", "sg-backup")
+	if err != nil {
+		message.SendUnknownError(rw, err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	manifest, err := Backup(r.Context(), h.storage, h.logDir, f)
+	if err != nil {
+		logrus.Errorf("backup: %v", err)
+		message.SendUnknownError(rw, err)
+		return
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		message.SendUnknownError(rw, err)
+		return
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		message.SendUnknownError(rw, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("supergiant-%s.tar.gz",
+		manifest.CreatedAt.Format("20060102-150405"))
+
+	rw.Header().Set("Content-Type", "application/gzip")
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	rw.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if _, err := io.Copy(rw, f); err != nil {
+		logrus.Errorf("backup: send archive: %v", err)
+	}
+}
+
+// Restore replaces stored records and task logs with the ones from
+// archive sent in request body, non empty storage is overwritten only
+// when overwrite query parameter is set to true.
+func (h *Handler) Restore(rw http.ResponseWriter, r *http.Request) {
+	overwrite, _ := strconv.ParseBool(r.URL.Query().Get("overwrite"))
+
+	manifest, err := Restore(r.Context(), h.storage, h.logDir, r.Body, overwrite)
+	if err != nil {
+		logrus.Errorf("restore: %v", err)
+
+		if err == ErrNotEmpty {
+			message.SendMessage(rw, message.New("Storage is not empty, use overwrite=true to replace it",
+				err.Error(), sgerrors.AlreadyExists, ""), http.StatusConflict)
+			return
+		}
+
+		message.SendUnknownError(rw, err)
+		return
+	}
+
+	logrus.Infof("restore: %d records and %d logs of backup taken at %s have been restored",
+		manifest.Records, manifest.Logs, manifest.CreatedAt.Format(time.RFC3339))
+
+	if err := json.NewEncoder(rw).Encode(manifest); err != nil {
+		message.SendUnknownError(rw, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
 	_ "net/http/pprof"
 	"net/url"
@@ -17,15 +18,22 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/supergiant/control/pkg/account"
 	"github.com/supergiant/control/pkg/api"
+	"github.com/supergiant/control/pkg/backup"
 	"github.com/supergiant/control/pkg/jwt"
 	"github.com/supergiant/control/pkg/kube"
+	"github.com/supergiant/control/pkg/migrations"
 	"github.com/supergiant/control/pkg/profile"
+	awsprovider "github.com/supergiant/control/pkg/providers/aws"
+	azureprovider "github.com/supergiant/control/pkg/providers/azure"
+	doprovider "github.com/supergiant/control/pkg/providers/digitalocean"
+	gceprovider "github.com/supergiant/control/pkg/providers/gce"
 	"github.com/supergiant/control/pkg/provisioner"
 	"github.com/supergiant/control/pkg/proxy"
 	sshRunner "github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/sghelm"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/encrypted"
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/user"
 	"github.com/supergiant/control/pkg/workflows"
@@ -47,8 +55,10 @@ import (
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
 	"github.com/supergiant/control/pkg/workflows/steps/network"
+	"github.com/supergiant/control/pkg/workflows/steps/plugin"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
 	"github.com/supergiant/control/pkg/workflows/steps/prometheus"
+	"github.com/supergiant/control/pkg/workflows/steps/provider"
 	"github.com/supergiant/control/pkg/workflows/steps/ssh"
 	"github.com/supergiant/control/pkg/workflows/steps/storageclass"
 	"github.com/supergiant/control/pkg/workflows/steps/tiller"
@@ -98,10 +108,33 @@ type Config struct {
 	StorageMode  string
 	StorageURI   string
 	TemplatesDir string
+	// WorkflowsDir holds yaml and json workflow definitions
+	WorkflowsDir string
 	LogDir       string
+	// TaskLogFormat is either txt or json, see workflows.LogFormat
+	TaskLogFormat string
+	// PluginsDir holds executables that implement steps, see plugin package
+	PluginsDir string
+
+	// MasterKeyFile contains keys used to encrypt stored values,
+	// encrypted.MasterKeyEnv is used when file is not set.
+	MasterKeyFile string
 
 	SpawnInterval time.Duration
 
+	// StepConcurrency limits number of independent steps
+	// of a task that are run at the same time.
+	StepConcurrency int
+
+	// RecoveryPolicy is applied to kubes and tasks left
+	// in progress by previous run, see kube.RecoveryPolicy.
+	RecoveryPolicy string
+
+	// TaskRetention is applied to finished tasks and their logs every
+	// TaskGCInterval, janitor is not run if policy removes nothing.
+	TaskRetention  workflows.RetentionPolicy
+	TaskGCInterval time.Duration
+
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
@@ -172,6 +205,22 @@ func validate(cfg *Config) error {
 		return errors.New("spawn interval must not be 0")
 	}
 
+	if cfg.RecoveryPolicy != "" {
+		if _, err := kube.ParseRecoveryPolicy(cfg.RecoveryPolicy); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TaskLogFormat != "" {
+		if _, err := workflows.ParseLogFormat(cfg.TaskLogFormat); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TaskRetention.Enabled() && cfg.TaskGCInterval <= 0 {
+		return errors.New("task gc interval must be positive")
+	}
+
 	return nil
 }
 
@@ -180,11 +229,14 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	router := mux.NewRouter()
 
 	protectedAPI := router.PathPrefix("/v1/api").Subrouter()
-	repository, err := storage.GetStorage(cfg.StorageMode, cfg.StorageURI)
+	repository, err := getStorage(cfg)
 
 	if err != nil {
-		return nil, errors.Wrapf(err, "get storage type %s uri %s",
-			cfg.StorageMode, cfg.StorageURI)
+		return nil, err
+	}
+
+	if _, err := migrate(repository, false); err != nil {
+		return nil, err
 	}
 
 	accountService := account.NewService(account.DefaultStoragePrefix, repository)
@@ -264,12 +316,35 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	amazon.InitCreateTagsStep(amazon.GetEC2)
 	apply.Init()
 	azure.Init()
+	provider.Init()
+
+	awsprovider.Init()
+	azureprovider.Init()
+	doprovider.Init()
+	gceprovider.Init()
 
+	workflows.SetStepConcurrency(cfg.StepConcurrency)
+	if cfg.TaskLogFormat != "" {
+		format, _ := workflows.ParseLogFormat(cfg.TaskLogFormat)
+		workflows.SetLogFormat(format)
+	}
 	workflows.Init()
+	if err := plugin.Load(cfg.PluginsDir); err != nil {
+		return nil, errors.Wrap(err, "load plugins")
+	}
+	if err := workflows.LoadDefinitions(cfg.WorkflowsDir); err != nil {
+		return nil, errors.Wrap(err, "load workflows")
+	}
+
+	backupHandler := backup.NewHandler(rawStorage(repository), cfg.LogDir)
+	backupHandler.Register(protectedAPI)
 
 	taskHandler := workflows.NewTaskHandler(repository, sshRunner.NewRunner, accountService, cfg.LogDir)
 	taskHandler.Register(protectedAPI)
 
+	definitionHandler := workflows.NewDefinitionHandler()
+	definitionHandler.Register(protectedAPI)
+
 	helmService, err := sghelm.NewService(repository)
 	if err != nil {
 		return nil, errors.Wrap(err, "new helm service")
@@ -300,6 +375,19 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 		repository, apiProxy, cfg.LogDir)
 	kubeHandler.Register(protectedAPI)
 
+	if cfg.RecoveryPolicy != "" {
+		policy, _ := kube.ParseRecoveryPolicy(cfg.RecoveryPolicy)
+		if err := kube.NewRecovery(kubeHandler, policy).Run(context.Background()); err != nil {
+			logrus.Errorf("recover interrupted kubes: %v", err)
+		}
+	}
+
+	if cfg.TaskRetention.Enabled() {
+		janitor := workflows.NewJanitor(repository, rawStorage(repository), cfg.LogDir,
+			cfg.TaskRetention, kubeService.TasksInUse)
+		go janitor.Run(context.Background(), cfg.TaskGCInterval)
+	}
+
 	authMiddleware := api.Middleware{
 		TokenService: jwtService,
 	}
@@ -318,6 +406,120 @@ func configureApplication(cfg *Config) (*mux.Router, error) {
 	return router, nil
 }
 
+// getStorage returns storage configured by cfg, values are encrypted
+// when master key is provided.
+func getStorage(cfg *Config) (storage.Interface, error) {
+	repository, err := storage.GetStorage(cfg.StorageMode, cfg.StorageURI)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "get storage type %s uri %s",
+			cfg.StorageMode, cfg.StorageURI)
+	}
+
+	keyring, err := encrypted.LoadKeyring(cfg.MasterKeyFile)
+
+	if errors.Cause(err) == encrypted.ErrNoMasterKey {
+		logrus.Warnf("master key is not set, secrets will be stored in plain text")
+		return repository, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "load master keys")
+	}
+
+	return encrypted.NewRepository(repository, keyring), nil
+}
+
+// Reencrypt encrypts all stored values with the primary master key,
+// it must be run while control plane is stopped.
+func Reencrypt(cfg *Config) (int, error) {
+	repository, err := getStorage(cfg)
+
+	if err != nil {
+		return 0, err
+	}
+
+	encryptedRepository, ok := repository.(*encrypted.Repository)
+
+	if !ok {
+		return 0, errors.Wrap(encrypted.ErrNoMasterKey, "reencrypt")
+	}
+
+	return encryptedRepository.Reencrypt(context.Background(), "")
+}
+
+// rawStorage returns storage that holds values as they are written,
+// encrypted values are not decrypted so backups never contain them
+// in plain text.
+func rawStorage(repository storage.Interface) storage.Interface {
+	if encryptedRepository, ok := repository.(*encrypted.Repository); ok {
+		return encryptedRepository.Storage()
+	}
+
+	return repository
+}
+
+// Backup writes archive with all stored records and task logs to w.
+func Backup(cfg *Config, w io.Writer) (*backup.Manifest, error) {
+	repository, err := getStorage(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Backup(context.Background(), rawStorage(repository), cfg.LogDir, w)
+}
+
+// Restore loads archive created by Backup to the configured storage, that
+// may be of a different type than the original one. Encrypted values can
+// be read only with the same master keys.
+func Restore(cfg *Config, r io.Reader, overwrite bool) (*backup.Manifest, error) {
+	repository, err := getStorage(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Restore(context.Background(), rawStorage(repository), cfg.LogDir, r, overwrite)
+}
+
+// Migrate upgrades stored records to the current schema versions, in dry
+// run mode it only reports records that would be migrated or can not be.
+func Migrate(cfg *Config, dryRun bool) (*migrations.Report, error) {
+	repository, err := getStorage(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate(repository, dryRun)
+}
+
+func migrate(repository storage.Interface, dryRun bool) (*migrations.Report, error) {
+	registry, err := migrations.Default()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "migrations")
+	}
+
+	report, err := registry.Run(context.Background(), repository, dryRun)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "run migrations")
+	}
+
+	if len(report.Failed) > 0 && !dryRun {
+		return report, errors.Errorf("%d records can not be migrated, "+
+			"nothing has been changed: %s", len(report.Failed), report)
+	}
+
+	if len(report.Migrated) > 0 {
+		logrus.Infof("migrations: %s", report)
+	}
+
+	return report, nil
+}
+
 func ensureHelmRepositories(svc sghelm.Servicer) {
 	if svc == nil {
 		return
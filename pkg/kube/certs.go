@@ -3,7 +3,6 @@ package kube
 import (
 	"bytes"
 	"context"
-	"io/ioutil"
 	"path/filepath"
 	"strings"
 
@@ -33,17 +32,19 @@ type Bundle struct {
 // Certs handles keys and certificates for kube components.
 type Certs struct {
 	path string
-	r    runner.Runner
+	r    runner.FileTransfer
 }
 
 // NewCerts returns a configured Certs.
 func NewCerts(path string, r runner.Runner) (*Certs, error) {
-	if r == nil {
+	// files are downloaded to not pass their content through logs
+	ft, ok := r.(runner.FileTransfer)
+	if !ok {
 		return nil, ErrInvalidRunner
 	}
 
 	return &Certs{
-		r:    r,
+		r:    ft,
 		path: path,
 	}, nil
 }
@@ -71,23 +72,12 @@ func (c *Certs) BundleFor(ctx context.Context, name string) (*Bundle, error) {
 }
 
 func (c *Certs) getFile(ctx context.Context, path string) ([]byte, error) {
-	stdout := &bytes.Buffer{}
-	cmd, err := runner.NewCommand(ctx, catCmd(path), stdout, ioutil.Discard)
-
-	if err != nil {
-		return nil, errors.Wrapf(err, "new command")
+	buf := &bytes.Buffer{}
+	if err := c.r.Download(ctx, path, buf); err != nil {
+		return nil, errors.Wrapf(err, "download %s", path)
 	}
 
-	err = c.r.Run(cmd)
-	if err != nil {
-		return nil, errors.Wrapf(err, "run %q", cmd.Script)
-	}
-
-	return stdout.Bytes(), nil
-}
-
-func catCmd(path string) string {
-	return "/usr/bin/cat " + path
+	return buf.Bytes(), nil
 }
 
 func keyName(name string) string {
@@ -2,6 +2,7 @@ package kube
 
 import (
 	"context"
+	"io"
 	"strings"
 	"testing"
 
@@ -27,12 +28,16 @@ func newFakeRunner(path string, err error) *fakeRunner {
 	return &fakeRunner{path, err}
 }
 
-func (r *fakeRunner) Run(cmd *runner.Command) error {
-	if cmd.Script == "" {
-		return nil
-	}
+func (r *fakeRunner) Run(*runner.Command) error {
+	return nil
+}
+
+func (r *fakeRunner) Upload(context.Context, runner.File, io.Reader, int64) error {
+	return nil
+}
 
-	if !strings.HasSuffix(cmd.Script, r.path) {
+func (r *fakeRunner) Download(ctx context.Context, path string, w io.Writer) error {
+	if !strings.HasSuffix(path, r.path) {
 		return nil
 	}
 
@@ -40,7 +45,7 @@ func (r *fakeRunner) Run(cmd *runner.Command) error {
 		return r.err
 	}
 
-	cmd.Out.Write(fakeFile)
+	w.Write(fakeFile)
 
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -19,13 +20,16 @@ import (
 	"github.com/supergiant/control/pkg/message"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/proxy"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows"
 	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"golang.org/x/crypto/ssh"
 	"gopkg.in/asaskevich/govalidator.v8"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,6 +58,8 @@ type nodeProvisioner interface {
 		*steps.Config) ([]string, error)
 	// Method that cancels newly added nodes to working cluster
 	Cancel(string) error
+	// Method that resumes interrupted tasks of nodes added to working cluster
+	RestartNodesProvisioning(context.Context, *steps.Config, []string) error
 }
 
 type kubeProvisioner interface {
@@ -84,13 +90,6 @@ type MetricResponse struct {
 	} `json:"data"`
 }
 
-type SpotRequest struct {
-	SpotPrice        string `json:"spotPrice"`
-	MachineType      string `json:"machineType"`
-	MachineCount     int64  `json:"machineCount"`
-	AvailabilityZone string `json:"availabilityZone"`
-}
-
 // Handler is a http controller for a kube entity.
 type Handler struct {
 	svc             Interface
@@ -203,6 +202,7 @@ func (h *Handler) Register(r *mux.Router) {
 
 	r.HandleFunc("/kubes/{kubeID}/machines", h.addMachine).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/machines/{nodename}", h.deleteMachine).Methods(http.MethodDelete)
+	r.HandleFunc("/kubes/{kubeID}/machines/{nodename}/hostkey", h.setHostKey).Methods(http.MethodPut)
 
 	r.HandleFunc("/kubes/{kubeID}/spot", h.addSpotMachine).Methods(http.MethodPost)
 	r.HandleFunc("/kubes/{kubeID}/spot/{machineType}/price", h.spotMachinePrice).Methods(http.MethodGet)
@@ -224,7 +224,18 @@ func (h *Handler) getTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := h.getKubeTasks(r.Context(), id)
+	opts, err := paging.FromQuery(r.URL.Query())
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	filter := workflows.TaskFilter{
+		Status: statuses.Status(r.URL.Query().Get("status")),
+		Type:   r.URL.Query().Get("type"),
+	}
+
+	tasks, next, err := h.listKubeTasks(r.Context(), id, filter, opts)
 
 	if err != nil {
 		if sgerrors.IsNotFound(err) {
@@ -232,11 +243,20 @@ func (h *Handler) getTasks(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if paging.IsInvalid(err) {
+			message.SendValidationFailed(w, err)
+			return
+		}
+
 		message.SendUnknownError(w, err)
 		return
 	}
 
-	if len(tasks) == 0 {
+	if next != "" {
+		w.Header().Set(paging.ContinueHeader, next)
+	}
+
+	if len(tasks) == 0 && opts.Continue == "" {
 		http.Error(w, "", http.StatusNotFound)
 		return
 	}
@@ -345,12 +365,33 @@ func (h *Handler) getKube(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) listKubes(w http.ResponseWriter, r *http.Request) {
-	kubes, err := h.svc.ListAll(r.Context())
+	opts, err := paging.FromQuery(r.URL.Query())
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	filter := ListFilter{
+		State:       model.KubeState(r.URL.Query().Get("state")),
+		Provider:    clouds.Name(r.URL.Query().Get("provider")),
+		AccountName: r.URL.Query().Get("accountName"),
+	}
+
+	kubes, next, err := h.svc.List(r.Context(), filter, opts)
 	if err != nil {
+		if paging.IsInvalid(err) {
+			message.SendValidationFailed(w, err)
+			return
+		}
+
 		message.SendUnknownError(w, err)
 		return
 	}
 
+	if next != "" {
+		w.Header().Set(paging.ContinueHeader, next)
+	}
+
 	if err = json.NewEncoder(w).Encode(kubes); err != nil {
 		message.SendUnknownError(w, err)
 	}
@@ -383,9 +424,7 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
-
-	if err != nil {
+	if err := h.startDeleteKube(r.Context(), k, forceDelete); err != nil {
 		if sgerrors.IsNotFound(err) {
 			http.NotFound(w, r)
 			return
@@ -395,6 +434,20 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// startDeleteKube runs task that deletes cloud resources of the kube, the kube
+// is removed when the task succeeds or regardless of the result if forced.
+func (h *Handler) startDeleteKube(ctx context.Context, k *model.Kube, forceDelete bool) error {
+	kubeID := k.ID
+
+	acc, err := h.accountService.Get(ctx, k.AccountName)
+
+	if err != nil {
+		return errors.Wrapf(err, "get account %s", k.AccountName)
+	}
+
 	config := &steps.Config{
 		Provider:         k.Provider,
 		ClusterID:        k.ID,
@@ -407,52 +460,43 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 	t, err := workflows.NewTask(config, workflows.DeleteCluster, h.repo)
 
 	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
+		return errors.Wrap(err, "new delete task")
 	}
 
 	// Load things specific to cloud provider
 	err = util.LoadCloudSpecificDataFromKube(k, config)
 
 	if err != nil {
-		message.SendUnknownError(w, err)
-		return
+		return errors.Wrap(err, "load cloud specific data")
 	}
 
 	err = util.FillCloudAccountCredentials(acc, config)
 
 	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-		message.SendUnknownError(w, err)
-		return
+		return errors.Wrap(err, "fill cloud account credentials")
 	}
 
 	fileName := util.MakeFileName(t.ID)
 	writer, err := h.getWriter(fileName)
 
 	if err != nil {
-		message.SendUnknownError(w, err)
-		return
+		return errors.Wrap(err, "get task writer")
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), time.Minute*10)
-	errChan := t.Run(ctx, *config, writer)
+	runCtx, _ := context.WithTimeout(context.Background(), time.Minute*10)
+	errChan := t.Run(runCtx, *config, writer)
 
 	go func(t *workflows.Task) {
-		// Update kube with deleting state
-		k.State = model.StateDeleting
-		// Append delete task ID to kube tasks so that task can be deleted too.
-		k.Tasks[workflows.DeleteTask] = []string{t.ID}
-
-		err = h.svc.Create(context.Background(), k)
+		err := h.svc.Update(context.Background(), kubeID, func(k *model.Kube) error {
+			// Update kube with deleting state
+			k.State = model.StateDeleting
+			// Append delete task ID to kube tasks so that task can be deleted too.
+			if k.Tasks == nil {
+				k.Tasks = make(map[string][]string)
+			}
+			k.Tasks[workflows.DeleteTask] = []string{t.ID}
+			return nil
+		})
 
 		if err != nil {
 			logrus.Errorf("update cluster %s caused %v", kubeID, err)
@@ -469,7 +513,7 @@ func (h *Handler) deleteKube(w http.ResponseWriter, r *http.Request) {
 		}
 	}(t)
 
-	w.WriteHeader(http.StatusAccepted)
+	return nil
 }
 
 func (h *Handler) getKubeconfig(w http.ResponseWriter, r *http.Request) {
@@ -658,10 +702,17 @@ func (h *Handler) addMachine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Add tasks ids to kube object
-	k.Tasks[workflows.NodeTask] = append(k.Tasks[workflows.NodeTask], tasks...)
+	// Add tasks ids to kube object, cluster monitor is already running
+	// at this point and may update the kube concurrently.
+	err = h.svc.Update(ctx, k.ID, func(k *model.Kube) error {
+		if k.Tasks == nil {
+			k.Tasks = make(map[string][]string)
+		}
+		k.Tasks[workflows.NodeTask] = append(k.Tasks[workflows.NodeTask], tasks...)
+		return nil
+	})
 
-	if err := h.svc.Create(ctx, k); err != nil {
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -771,16 +822,18 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 
 	// Update cluster state when deletion completes
 	go func() {
-		// Set node to deleting state
-		nodeToDelete, ok := k.Nodes[nodeName]
-
-		if !ok {
+		if _, ok := k.Nodes[nodeName]; !ok {
 			logrus.Errorf("Node %s not found", nodeName)
 			return
 		}
-		nodeToDelete.State = model.MachineStateDeleting
-		k.Nodes[nodeName] = nodeToDelete
-		err := h.svc.Create(context.Background(), k)
+
+		// Set node to deleting state
+		err := h.svc.Update(context.Background(), kubeID, func(k *model.Kube) error {
+			if n, ok := k.Nodes[nodeName]; ok {
+				n.State = model.MachineStateDeleting
+			}
+			return nil
+		})
 
 		if err != nil {
 			logrus.Errorf("update cluster %s caused %v", kubeID, err)
@@ -793,10 +846,11 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Delete node from cluster object
-		delete(k.Nodes, nodeName)
-		// Save cluster object to etcd
 		logrus.Infof("delete node %s from cluster %s", nodeName, kubeID)
-		err = h.svc.Create(context.Background(), k)
+		err = h.svc.Update(context.Background(), kubeID, func(k *model.Kube) error {
+			delete(k.Nodes, nodeName)
+			return nil
+		})
 
 		if err != nil {
 			logrus.Errorf("update cluster %s caused %v", kubeID, err)
@@ -805,6 +859,56 @@ func (h *Handler) deleteMachine(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// setHostKey replaces ssh host key of a machine, e.g. after it has been
+// rebuilt. An empty key makes the machine trusted on the next contact.
+func (h *Handler) setHostKey(w http.ResponseWriter, r *http.Request) {
+	type hostKeyRequest struct {
+		HostKey string `json:"hostKey"`
+	}
+
+	vars := mux.Vars(r)
+	kubeID := vars["kubeID"]
+	nodeName := vars["nodename"]
+
+	req := hostKeyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	if req.HostKey != "" {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.HostKey)); err != nil {
+			message.SendValidationFailed(w, errors.Wrap(err, "parse host key"))
+			return
+		}
+	}
+
+	err := h.svc.Update(r.Context(), kubeID, func(k *model.Kube) error {
+		m := k.Masters[nodeName]
+		if m == nil {
+			m = k.Nodes[nodeName]
+		}
+		if m == nil {
+			return errors.Wrapf(sgerrors.ErrNotFound, "machine %s", nodeName)
+		}
+
+		m.HostKey = req.HostKey
+		return nil
+	})
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, nodeName, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	logrus.Infof("host key of machine %s in kube %s has been set to %q",
+		nodeName, kubeID, req.HostKey)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // TODO(stgleb): Create separte task service to manage task object lifecycle
 func (h *Handler) getKubeTasks(ctx context.Context, kubeID string) ([]*workflows.Task, error) {
 	k, err := h.svc.Get(ctx, kubeID)
@@ -841,6 +945,62 @@ func (h *Handler) getKubeTasks(ctx context.Context, kubeID string) ([]*workflows
 	return tasks, nil
 }
 
+// listKubeTasks reads tasks of the kube in order of their ids, reading
+// stops as soon as a page of tasks that match filter is collected.
+func (h *Handler) listKubeTasks(ctx context.Context, kubeID string, filter workflows.TaskFilter,
+	opts paging.Options) ([]*workflows.Task, string, error) {
+	after, err := paging.DecodeToken(workflows.Prefix, opts.Continue)
+	if err != nil {
+		return nil, "", err
+	}
+
+	k, err := h.svc.Get(ctx, kubeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ids := make([]string, 0)
+	for _, taskSet := range k.Tasks {
+		for _, taskID := range taskSet {
+			if workflows.Prefix+taskID > after {
+				ids = append(ids, taskID)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	tasks := make([]*workflows.Task, 0)
+
+	for i, taskID := range ids {
+		if i > 0 && ids[i-1] == taskID {
+			continue
+		}
+
+		if opts.Limit > 0 && len(tasks) == opts.Limit {
+			return tasks, paging.EncodeToken(workflows.Prefix + ids[i-1]), nil
+		}
+
+		t, err := h.repo.Get(ctx, workflows.Prefix, taskID)
+
+		// Tasks may not be created yet
+		if err != nil {
+			logrus.Debugf("task %s not found", taskID)
+			continue
+		}
+
+		task := &workflows.Task{}
+		if err := json.Unmarshal(t, task); err != nil {
+			return nil, "", errors.Wrapf(err, "get task %s", taskID)
+		}
+
+		if filter.Matches(task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, "", nil
+}
+
 func (h *Handler) deleteClusterTasks(ctx context.Context, kubeID string) error {
 	tasks, err := h.getKubeTasks(ctx, kubeID)
 
@@ -1192,12 +1352,9 @@ func (h *Handler) restartKubeProvisioning(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	logrus.Debugf("Get cloud profile %s", k.ProfileID)
-	kubeProfile, err := h.profileSvc.Get(r.Context(), k.ProfileID)
-
-	if err != nil {
+	if err := h.restartProvisioning(r.Context(), k); err != nil {
 		if sgerrors.IsNotFound(err) {
-			message.SendNotFound(w, k.ProfileID, err)
+			message.SendNotFound(w, kubeID, err)
 			return
 		}
 
@@ -1205,11 +1362,52 @@ func (h *Handler) restartKubeProvisioning(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// restartProvisioning runs tasks of the kube again, steps that
+// have succeeded already are skipped.
+func (h *Handler) restartProvisioning(ctx context.Context, k *model.Kube) error {
+	kubeProfile, config, err := h.restartConfig(ctx, k)
+
+	if err != nil {
+		return err
+	}
+
+	logrus.Debugf("Restart cluster %s provisioning", k.ID)
+	err = h.kubeProvisioner.RestartClusterProvisioning(ctx,
+		kubeProfile, config, k.Tasks)
+
+	return errors.Wrap(err, "restart cluster provisioning")
+}
+
+// restartNodes resumes node tasks interrupted while being added to the kube.
+func (h *Handler) restartNodes(ctx context.Context, k *model.Kube, taskIDs []string) error {
+	_, config, err := h.restartConfig(ctx, k)
+
+	if err != nil {
+		return err
+	}
+
+	logrus.Debugf("Restart nodes provisioning of cluster %s", k.ID)
+	err = h.nodeProvisioner.RestartNodesProvisioning(ctx, config, taskIDs)
+
+	return errors.Wrap(err, "restart nodes provisioning")
+}
+
+// restartConfig builds config of interrupted tasks of the kube
+func (h *Handler) restartConfig(ctx context.Context, k *model.Kube) (*profile.Profile, *steps.Config, error) {
+	logrus.Debugf("Get cloud profile %s", k.ProfileID)
+	kubeProfile, err := h.profileSvc.Get(ctx, k.ProfileID)
+
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "get profile %s", k.ProfileID)
+	}
+
 	config, err := steps.NewConfigFromKube(kubeProfile, k)
 	if err != nil {
 		logrus.Errorf("New config %v", err.Error())
-		message.SendUnknownError(w, err)
-		return
+		return nil, nil, errors.Wrap(err, "new config")
 	}
 
 	logrus.Debugf("load clout specific data from kube %s", k.ID)
@@ -1217,45 +1415,24 @@ func (h *Handler) restartKubeProvisioning(w http.ResponseWriter, r *http.Request
 	err = util.LoadCloudSpecificDataFromKube(k, config)
 
 	if err != nil {
-		message.SendUnknownError(w, err)
-		return
+		return nil, nil, errors.Wrap(err, "load cloud specific data")
 	}
 
 	logrus.Debugf("Get cloud account %s", k.AccountName)
-	acc, err := h.accountService.Get(r.Context(), k.AccountName)
+	acc, err := h.accountService.Get(ctx, k.AccountName)
 
 	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
+		return nil, nil, errors.Wrapf(err, "get account %s", k.AccountName)
 	}
 
 	logrus.Debug("Fill config with cloud account credentials")
 	err = util.FillCloudAccountCredentials(acc, config)
 
 	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			http.NotFound(w, r)
-			return
-		}
-		message.SendUnknownError(w, err)
-		return
+		return nil, nil, errors.Wrap(err, "fill cloud account credentials")
 	}
 
-	logrus.Debugf("Restart cluster %s provisioning", k.ID)
-	err = h.kubeProvisioner.RestartClusterProvisioning(r.Context(),
-		kubeProfile, config, k.Tasks)
-
-	if err != nil {
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	w.WriteHeader(http.StatusAccepted)
+	return kubeProfile, config, nil
 }
 
 func (h *Handler) importKube(w http.ResponseWriter, r *http.Request) {
@@ -1745,7 +1922,7 @@ func (h *Handler) addSpotMachine(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// TODO(stgleb): Add machine count here
-	req := &SpotRequest{}
+	req := &providers.SpotRequest{}
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
 		message.SendInvalidJSON(w, err)
@@ -26,13 +26,22 @@ import (
 	"github.com/supergiant/control/pkg/message"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	awsprovider "github.com/supergiant/control/pkg/providers/aws"
+	doprovider "github.com/supergiant/control/pkg/providers/digitalocean"
 	"github.com/supergiant/control/pkg/proxy"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
+func init() {
+	awsprovider.Init()
+	doprovider.Init()
+}
+
 var (
 	errFake = errors.New("fake error")
 
@@ -138,7 +147,9 @@ func (b *bufferCloser) Close() error {
 const (
 	serviceCreate            = "Create"
 	serviceGet               = "Get"
+	serviceUpdate            = "Update"
 	serviceListAll           = "ListAll"
+	serviceList              = "List"
 	serviceDelete            = "Delete"
 	serviceListKubeResources = "ListKubeResources"
 	serviceListNodes         = "ListNodes"
@@ -156,6 +167,12 @@ func (m *mockNodeProvisioner) ProvisionNodes(ctx context.Context, nodeProfile []
 	return val, args.Error(1)
 }
 
+func (m *mockNodeProvisioner) RestartNodesProvisioning(ctx context.Context,
+	config *steps.Config, taskIDs []string) error {
+	args := m.Called(ctx, config, taskIDs)
+	return args.Error(0)
+}
+
 func (m *mockNodeProvisioner) Cancel(clusterID string) error {
 	args := m.Called(clusterID)
 	val, ok := args.Get(0).(error)
@@ -181,6 +198,10 @@ func (m *kubeServiceMock) Get(ctx context.Context, name string) (*model.Kube, er
 	}
 	return val, args.Error(1)
 }
+func (m *kubeServiceMock) Update(ctx context.Context, name string, fn func(*model.Kube) error) error {
+	args := m.Called(ctx, name, fn)
+	return args.Error(0)
+}
 func (m *kubeServiceMock) KubeConfigFor(ctx context.Context, kname, user string) ([]byte, error) {
 	args := m.Called(ctx, kname, user)
 	val, ok := args.Get(0).([]byte)
@@ -198,6 +219,15 @@ func (m *kubeServiceMock) ListAll(ctx context.Context) ([]model.Kube, error) {
 	return val, args.Error(1)
 }
 
+func (m *kubeServiceMock) List(ctx context.Context, filter ListFilter, opts paging.Options) ([]model.Kube, string, error) {
+	args := m.Called(ctx, filter, opts)
+	val, ok := args.Get(0).([]model.Kube)
+	if !ok {
+		return nil, "", args.Error(2)
+	}
+	return val, args.String(1), args.Error(2)
+}
+
 func (m *kubeServiceMock) Delete(ctx context.Context, name string) error {
 	args := m.Called(ctx, name)
 	return args.Error(0)
@@ -446,7 +476,11 @@ func TestHandler_getKube(t *testing.T) {
 
 func TestHandler_listKubes(t *testing.T) {
 	tcs := []struct {
+		query        string
+		filter       ListFilter
+		opts         paging.Options
 		serviceKubes []model.Kube
+		serviceNext  string
 		serviceError error
 
 		expectedStatus  int
@@ -465,6 +499,37 @@ func TestHandler_listKubes(t *testing.T) {
 				},
 			},
 		},
+		{ // TC#3
+			query: "?limit=1&continue=token&state=failed&provider=aws&accountName=test",
+			filter: ListFilter{
+				State:       model.StateFailed,
+				Provider:    clouds.AWS,
+				AccountName: "test",
+			},
+			opts: paging.Options{
+				Limit:    1,
+				Continue: "token",
+			},
+			expectedStatus: http.StatusOK,
+			serviceKubes: []model.Kube{
+				{
+					Name: "success",
+				},
+			},
+			serviceNext: "next",
+		},
+		{ // TC#4
+			query:           "?limit=-1",
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrCode: sgerrors.ValidationFailed,
+		},
+		{ // TC#5
+			query:           "?continue=wrong",
+			opts:            paging.Options{Continue: "wrong"},
+			serviceError:    paging.ErrInvalidToken,
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrCode: sgerrors.ValidationFailed,
+		},
 	}
 
 	for i, tc := range tcs {
@@ -474,10 +539,11 @@ func TestHandler_listKubes(t *testing.T) {
 			nil, nil, nil, nil, "")
 
 		// prepare
-		req, err := http.NewRequest(http.MethodGet, "/kubes", nil)
+		req, err := http.NewRequest(http.MethodGet, "/kubes"+tc.query, nil)
 		require.Equalf(t, nil, err, "TC#%d: create request: %v", i+1, err)
 
-		svc.On(serviceListAll, mock.Anything).Return(tc.serviceKubes, tc.serviceError)
+		svc.On(serviceList, mock.Anything, tc.filter, tc.opts).
+			Return(tc.serviceKubes, tc.serviceNext, tc.serviceError)
 		rr := httptest.NewRecorder()
 
 		router := mux.NewRouter().SkipClean(true)
@@ -488,6 +554,7 @@ func TestHandler_listKubes(t *testing.T) {
 
 		// check
 		require.Equalf(t, tc.expectedStatus, rr.Code, "TC#%d", i+1)
+		require.Equalf(t, tc.serviceNext, rr.Header().Get(paging.ContinueHeader), "TC#%d", i+1)
 
 		if tc.expectedErrCode != sgerrors.ErrorCode(0) {
 			m := new(message.Message)
@@ -596,11 +663,15 @@ func TestHandler_deleteKube(t *testing.T) {
 		svc.On(serviceGet, mock.Anything, tc.kubeName).Return(tc.kube, tc.getKubeError)
 		svc.On(serviceDelete, mock.Anything, tc.kubeName).Return(tc.deleteKubeError)
 		svc.On(serviceCreate, mock.Anything, mock.Anything).Return(nil)
+		svc.On(serviceUpdate, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		accSvc.On(serviceGet, mock.Anything, tc.accountName).Return(tc.account, tc.getAccountError)
 		mockRepo := new(testutils.MockStorage)
 		mockRepo.On("Put", mock.Anything, mock.Anything,
 			mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("PutIfRevision", mock.Anything,
+			mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Return(1, nil)
 		mockRepo.On("Delete", mock.Anything,
 			mock.Anything, mock.Anything).Return(nil)
 		mockRepo.On("GetAll", mock.Anything,
@@ -961,6 +1032,8 @@ func TestAddNodeToKube(t *testing.T) {
 			Return(testCase.kube, testCase.kubeServiceErr)
 		svc.On(serviceCreate, mock.Anything, mock.Anything).
 			Return(nil)
+		svc.On(serviceUpdate, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
 
 		profileSvc := new(mockProfileService)
 		profileSvc.On("Get", mock.Anything,
@@ -1153,6 +1226,8 @@ func TestDeleteNodeFromKube(t *testing.T) {
 			Return(testCase.kube, testCase.kubeServiceErr)
 		svc.On(serviceCreate, mock.Anything, testCase.kube).
 			Return(mock.Anything)
+		svc.On(serviceUpdate, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
 
 		accService := new(accServiceMock)
 		accService.On("Get", mock.Anything, mock.Anything).
@@ -1161,6 +1236,9 @@ func TestDeleteNodeFromKube(t *testing.T) {
 		mockRepo := new(testutils.MockStorage)
 		mockRepo.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 			Return(nil)
+		mockRepo.On("PutIfRevision", mock.Anything,
+			mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Return(1, nil)
 
 		mockRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).
 			Return(nil)
@@ -1186,6 +1264,104 @@ func TestDeleteNodeFromKube(t *testing.T) {
 	}
 }
 
+func TestHandler_setHostKey(t *testing.T) {
+	hostKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEMKnSg8xTEB/ot0xx5y2z5KN+98M7cuRZ55NmcanjIk"
+
+	tcs := []struct {
+		description string
+		machine     string
+		body        string
+		kube        *model.Kube
+
+		expectedStatus  int
+		expectedHostKey string
+	}{
+		{
+			description:    "malformed json",
+			machine:        "master-1",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "invalid key",
+			machine:        "master-1",
+			body:           `{"hostKey":"ssh-rsa invalid"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "kube not found",
+			machine:        "master-1",
+			body:           `{"hostKey":""}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "machine not found",
+			machine:     "node-2",
+			body:        `{"hostKey":""}`,
+			kube: &model.Kube{
+				Masters: map[string]*model.Machine{"master-1": {Name: "master-1"}},
+				Nodes:   map[string]*model.Machine{"node-1": {Name: "node-1"}},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "rekey master",
+			machine:     "master-1",
+			body:        `{"hostKey":"` + hostKey + `"}`,
+			kube: &model.Kube{
+				Masters: map[string]*model.Machine{"master-1": {Name: "master-1", HostKey: "old"}},
+			},
+			expectedStatus:  http.StatusNoContent,
+			expectedHostKey: hostKey,
+		},
+		{
+			description: "reset node key",
+			machine:     "node-1",
+			body:        `{"hostKey":""}`,
+			kube: &model.Kube{
+				Nodes: map[string]*model.Machine{"node-1": {Name: "node-1", HostKey: "old"}},
+			},
+			expectedStatus:  http.StatusNoContent,
+			expectedHostKey: "",
+		},
+	}
+
+	for _, tc := range tcs {
+		svc := new(kubeServiceMock)
+		call := svc.On(serviceUpdate, mock.Anything, "test", mock.Anything)
+		call.Run(func(args mock.Arguments) {
+			if tc.kube == nil {
+				call.ReturnArguments = mock.Arguments{sgerrors.ErrNotFound}
+				return
+			}
+			fn := args.Get(2).(func(*model.Kube) error)
+			call.ReturnArguments = mock.Arguments{fn(tc.kube)}
+		})
+
+		h := NewHandler(svc, nil, nil, nil, nil, nil, nil, "")
+		router := mux.NewRouter()
+		h.Register(router)
+
+		req, err := http.NewRequest(http.MethodPut, "/kubes/test/machines/"+tc.machine+"/hostkey",
+			strings.NewReader(tc.body))
+		require.NoError(t, err, tc.description)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		require.Equal(t, tc.expectedStatus, rr.Code, tc.description)
+		if tc.expectedStatus != http.StatusNoContent {
+			continue
+		}
+
+		m := tc.kube.Masters[tc.machine]
+		if m == nil {
+			m = tc.kube.Nodes[tc.machine]
+		}
+		require.Equal(t, tc.expectedHostKey, m.HostKey, tc.description)
+	}
+}
+
 func TestKubeTasks(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -1472,6 +1648,57 @@ func TestGetTasks(t *testing.T) {
 	}
 }
 
+func TestListKubeTasks(t *testing.T) {
+	svc := new(kubeServiceMock)
+	svc.On(serviceGet, mock.Anything, "test").Return(&model.Kube{
+		ID: "test",
+		Tasks: map[string][]string{
+			workflows.MasterTask: {"3", "1"},
+			workflows.NodeTask:   {"2", "4"},
+		},
+	}, nil)
+
+	repo := &testutils.MockStorage{}
+	for _, id := range []string{"1", "2", "3"} {
+		status := statuses.Success
+		if id == "2" {
+			status = statuses.Error
+		}
+		repo.On("Get", mock.Anything, workflows.Prefix, id).
+			Return([]byte(fmt.Sprintf(`{"id":"%s","status":"%s"}`, id, status)), nil)
+	}
+	repo.On("Get", mock.Anything, workflows.Prefix, "4").
+		Return(nil, sgerrors.ErrNotFound)
+
+	h := Handler{
+		repo: repo,
+		svc:  svc,
+	}
+
+	filter := workflows.TaskFilter{Status: statuses.Success}
+	tasks, next, err := h.listKubeTasks(context.Background(), "test", filter, paging.Options{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "1", tasks[0].ID)
+	require.NotEmpty(t, next)
+
+	tasks, next, err = h.listKubeTasks(context.Background(), "test", filter,
+		paging.Options{Limit: 1, Continue: next})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "3", tasks[0].ID)
+
+	tasks, next, err = h.listKubeTasks(context.Background(), "test", filter,
+		paging.Options{Limit: 1, Continue: next})
+	require.NoError(t, err)
+	require.Empty(t, tasks)
+	require.Empty(t, next)
+
+	_, _, err = h.listKubeTasks(context.Background(), "test", filter,
+		paging.Options{Continue: paging.EncodeToken("/supergiant/kubes/test")})
+	require.Equal(t, paging.ErrInvalidToken, err)
+}
+
 func TestHandler_installRelease(t *testing.T) {
 	tcs := []struct {
 		rlsInp string
@@ -2489,6 +2716,9 @@ func TestImportKube(t *testing.T) {
 		mockRepo := new(testutils.MockStorage)
 		mockRepo.On("Put", mock.Anything, mock.Anything,
 			mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("PutIfRevision", mock.Anything,
+			mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Return(1, nil)
 
 		h := NewHandler(svc, accSvc,
 			profileSvc, nil,
@@ -0,0 +1,231 @@
+package kube
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+)
+
+// RecoveryPolicy tells what to do with kubes and tasks that
+// have been left in progress by previous run of control plane.
+type RecoveryPolicy string
+
+const (
+	// RecoveryResume restarts provisioning and deletion of kubes and adding
+	// of nodes to operational kubes from the last successful step, kubes
+	// being upgraded are marked as failed.
+	RecoveryResume RecoveryPolicy = "resume"
+	// RecoveryFail marks all interrupted kubes and tasks as failed
+	RecoveryFail RecoveryPolicy = "fail"
+	// RecoveryNone leaves interrupted kubes and tasks as they are
+	RecoveryNone RecoveryPolicy = "none"
+)
+
+// InterruptedReason is set as error message of steps that
+// have been running when control plane was stopped.
+const InterruptedReason = "interrupted by control plane restart"
+
+// interruptedStates are states of kubes that have a task running
+var interruptedStates = []model.KubeState{
+	model.StateProvisioning,
+	model.StateDeleting,
+	model.StateUpgrading,
+}
+
+func ParseRecoveryPolicy(policy string) (RecoveryPolicy, error) {
+	switch p := RecoveryPolicy(policy); p {
+	case RecoveryResume, RecoveryFail, RecoveryNone:
+		return p, nil
+	}
+
+	return "", errors.Errorf("unknown recovery policy %q", policy)
+}
+
+// Recovery finds kubes and tasks that have been interrupted by restart
+// of control plane, it must be run before API starts serving requests.
+type Recovery struct {
+	h      *Handler
+	policy RecoveryPolicy
+}
+
+func NewRecovery(h *Handler, policy RecoveryPolicy) *Recovery {
+	return &Recovery{
+		h:      h,
+		policy: policy,
+	}
+}
+
+// Run resumes or fails interrupted kubes according to the policy, tasks
+// in executing state that have not been resumed along with their kubes
+// are marked as failed along with their machines.
+func (r *Recovery) Run(ctx context.Context) error {
+	if r.policy == RecoveryNone {
+		return nil
+	}
+
+	tasks, _, err := workflows.ListTasks(ctx, r.h.repo,
+		workflows.TaskFilter{Status: statuses.Executing}, paging.Options{})
+	if err != nil {
+		return errors.Wrap(err, "list executing tasks")
+	}
+
+	executing := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		executing[task.ID] = true
+	}
+
+	// tasks that are run again by resumed kubes
+	resumed := make(map[string]bool)
+
+	for _, state := range interruptedStates {
+		kubes, _, err := r.h.svc.List(ctx, ListFilter{State: state}, paging.Options{})
+		if err != nil {
+			return errors.Wrapf(err, "list %s kubes", state)
+		}
+
+		for i := range kubes {
+			r.recoverKube(ctx, &kubes[i], resumed)
+		}
+	}
+
+	if r.policy == RecoveryResume {
+		if err := r.recoverNodes(ctx, executing, resumed); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range tasks {
+		if resumed[task.ID] {
+			continue
+		}
+
+		if err := r.failTask(ctx, task.ID); err != nil {
+			logrus.Errorf("recovery: fail task %s: %v", task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Recovery) recoverKube(ctx context.Context, k *model.Kube, resumed map[string]bool) {
+	var err error
+
+	switch {
+	case r.policy == RecoveryResume && k.State == model.StateProvisioning:
+		logrus.Infof("recovery: resume provisioning of kube %s", k.ID)
+		if err = r.h.restartProvisioning(ctx, k); err == nil {
+			for _, ids := range k.Tasks {
+				for _, id := range ids {
+					resumed[id] = true
+				}
+			}
+			return
+		}
+	case r.policy == RecoveryResume && k.State == model.StateDeleting:
+		// interrupted delete task is replaced by a new one
+		logrus.Infof("recovery: resume deletion of kube %s", k.ID)
+		if err = r.h.startDeleteKube(ctx, k, false); err == nil {
+			return
+		}
+	}
+
+	if err != nil {
+		logrus.Errorf("recovery: kube %s: %v", k.ID, err)
+	}
+
+	logrus.Infof("recovery: mark %s kube %s as failed", k.State, k.ID)
+	err = r.h.svc.Update(ctx, k.ID, func(k *model.Kube) error {
+		k.State = model.StateFailed
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("recovery: update kube %s: %v", k.ID, err)
+	}
+}
+
+// recoverNodes resumes executing tasks of nodes being added to operational kubes.
+func (r *Recovery) recoverNodes(ctx context.Context, executing, resumed map[string]bool) error {
+	kubes, _, err := r.h.svc.List(ctx,
+		ListFilter{State: model.StateOperational}, paging.Options{})
+	if err != nil {
+		return errors.Wrapf(err, "list %s kubes", model.StateOperational)
+	}
+
+	for i := range kubes {
+		var ids []string
+		for _, id := range kubes[i].Tasks[workflows.NodeTask] {
+			if executing[id] {
+				ids = append(ids, id)
+			}
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		logrus.Infof("recovery: resume adding nodes to kube %s", kubes[i].ID)
+		if err := r.h.restartNodes(ctx, &kubes[i], ids); err != nil {
+			logrus.Errorf("recovery: kube %s: %v", kubes[i].ID, err)
+			continue
+		}
+
+		for _, id := range ids {
+			resumed[id] = true
+		}
+	}
+
+	return nil
+}
+
+func (r *Recovery) failTask(ctx context.Context, id string) error {
+	task, err := workflows.LoadTask(ctx, id, r.h.repo)
+	if err != nil {
+		return errors.Wrap(err, "load task")
+	}
+
+	logrus.Infof("recovery: mark task %s as failed", id)
+	if err := task.Interrupt(ctx, InterruptedReason); err != nil {
+		return err
+	}
+
+	return r.failMachine(ctx, task)
+}
+
+// failMachine moves machine of the interrupted task to error state,
+// otherwise it would be shown as being provisioned forever.
+func (r *Recovery) failMachine(ctx context.Context, task *workflows.Task) error {
+	if task.Config == nil || task.Config.ClusterID == "" || task.Config.Node.Name == "" {
+		return nil
+	}
+
+	name := task.Config.Node.Name
+	err := r.h.svc.Update(ctx, task.Config.ClusterID, func(k *model.Kube) error {
+		for _, machines := range []map[string]*model.Machine{k.Masters, k.Nodes} {
+			m := machines[name]
+			if m == nil {
+				continue
+			}
+
+			switch m.State {
+			case model.MachineStatePlanned, model.MachineStateBuilding,
+				model.MachineStateProvisioning:
+				m.State = model.MachineStateError
+			}
+		}
+
+		return nil
+	})
+
+	if sgerrors.IsNotFound(err) {
+		return nil
+	}
+
+	return errors.Wrapf(err, "update kube %s", task.Config.ClusterID)
+}
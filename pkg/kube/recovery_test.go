@@ -0,0 +1,261 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestParseRecoveryPolicy(t *testing.T) {
+	for _, policy := range []RecoveryPolicy{RecoveryResume, RecoveryFail, RecoveryNone} {
+		p, err := ParseRecoveryPolicy(string(policy))
+		require.NoError(t, err)
+		require.Equal(t, policy, p)
+	}
+
+	_, err := ParseRecoveryPolicy("restart")
+	require.Error(t, err)
+}
+
+func TestRecoveryRun(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      RecoveryPolicy
+
+		expectedRestart bool
+		expectedFailed  []string
+		expectedTasks   map[string]statuses.Status
+	}{
+		{
+			description:     "resume",
+			policy:          RecoveryResume,
+			expectedRestart: true,
+			expectedFailed:  []string{"upgrading"},
+			expectedTasks: map[string]statuses.Status{
+				"provisioningTask": statuses.Executing,
+				"upgradingTask":    statuses.Error,
+			},
+		},
+		{
+			description:    "fail",
+			policy:         RecoveryFail,
+			expectedFailed: []string{"provisioning", "upgrading"},
+			expectedTasks: map[string]statuses.Status{
+				"provisioningTask": statuses.Error,
+				"upgradingTask":    statuses.Error,
+			},
+		},
+		{
+			description: "none",
+			policy:      RecoveryNone,
+			expectedTasks: map[string]statuses.Status{
+				"provisioningTask": statuses.Executing,
+				"upgradingTask":    statuses.Executing,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		repo := memory.NewInMemoryRepository()
+		for id := range testCase.expectedTasks {
+			data, err := json.Marshal(&workflows.Task{
+				ID:     id,
+				Status: statuses.Executing,
+				StepStatuses: []workflows.StepStatus{
+					{StepName: "docker", Status: statuses.Executing},
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, repo.Put(context.Background(), workflows.Prefix, id, data))
+		}
+
+		kubes := map[model.KubeState][]model.Kube{
+			model.StateProvisioning: {{
+				ID:          "provisioning",
+				State:       model.StateProvisioning,
+				AccountName: "test",
+				Tasks: map[string][]string{
+					workflows.MasterTask: {"provisioningTask"},
+				},
+			}},
+			model.StateUpgrading: {{
+				ID:    "upgrading",
+				State: model.StateUpgrading,
+				Tasks: map[string][]string{
+					workflows.MasterTask: {"upgradingTask"},
+				},
+			}},
+		}
+
+		svc := new(kubeServiceMock)
+		var failed []string
+		for _, state := range interruptedStates {
+			svc.On(serviceList, mock.Anything, ListFilter{State: state}, mock.Anything).
+				Return(kubes[state], "", nil)
+		}
+		svc.On(serviceList, mock.Anything, ListFilter{State: model.StateOperational}, mock.Anything).
+			Return(nil, "", nil)
+		svc.On(serviceUpdate, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				failed = append(failed, args.String(1))
+			}).Return(nil)
+
+		profileSvc := new(mockProfileService)
+		profileSvc.On("Get", mock.Anything, mock.Anything).
+			Return(&profile.Profile{}, nil)
+
+		accService := new(accServiceMock)
+		accService.On("Get", mock.Anything, mock.Anything).
+			Return(&model.CloudAccount{Provider: clouds.AWS}, nil)
+
+		provisioner := new(mockProvisioner)
+		provisioner.On("RestartClusterProvisioning", mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		h := NewHandler(svc, accService, profileSvc, nil, provisioner,
+			repo, nil, "")
+
+		require.NoError(t, NewRecovery(h, testCase.policy).Run(context.Background()))
+
+		require.Equal(t, testCase.expectedFailed, failed)
+		if testCase.expectedRestart {
+			provisioner.AssertCalled(t, "RestartClusterProvisioning",
+				mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		} else {
+			provisioner.AssertNotCalled(t, "RestartClusterProvisioning",
+				mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+
+		for id, status := range testCase.expectedTasks {
+			task, err := workflows.LoadTask(context.Background(), id, repo)
+			require.NoError(t, err)
+			require.Equal(t, status, task.Status, id)
+
+			if status == statuses.Error {
+				require.Equal(t, InterruptedReason, task.StepStatuses[0].ErrMsg)
+				require.NotNil(t, task.FinishedAt)
+			}
+		}
+	}
+}
+
+func TestRecoveryRunNodes(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      RecoveryPolicy
+		restartErr  error
+
+		expectedRestart bool
+		expectedStatus  statuses.Status
+		expectedMachine model.MachineState
+	}{
+		{
+			description:     "resume",
+			policy:          RecoveryResume,
+			expectedRestart: true,
+			expectedStatus:  statuses.Executing,
+			expectedMachine: model.MachineStateProvisioning,
+		},
+		{
+			description:     "resume error",
+			policy:          RecoveryResume,
+			restartErr:      errors.New("error"),
+			expectedRestart: true,
+			expectedStatus:  statuses.Error,
+			expectedMachine: model.MachineStateError,
+		},
+		{
+			description:     "fail",
+			policy:          RecoveryFail,
+			expectedStatus:  statuses.Error,
+			expectedMachine: model.MachineStateError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		repo := memory.NewInMemoryRepository()
+		data, err := json.Marshal(&workflows.Task{
+			ID:     "nodeTask",
+			Status: statuses.Executing,
+			Config: &steps.Config{
+				ClusterID: "operational",
+				Node:      model.Machine{Name: "node-1"},
+			},
+			StepStatuses: []workflows.StepStatus{
+				{StepName: "docker", Status: statuses.Executing},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.Put(context.Background(), workflows.Prefix, "nodeTask", data))
+
+		k := &model.Kube{
+			ID:          "operational",
+			State:       model.StateOperational,
+			AccountName: "test",
+			Masters:     map[string]*model.Machine{},
+			Nodes: map[string]*model.Machine{
+				"node-1": {Name: "node-1", State: model.MachineStateProvisioning},
+			},
+			Tasks: map[string][]string{
+				workflows.NodeTask: {"nodeTask"},
+			},
+		}
+
+		svc := new(kubeServiceMock)
+		svc.On(serviceList, mock.Anything, ListFilter{State: model.StateOperational}, mock.Anything).
+			Return([]model.Kube{*k}, "", nil)
+		svc.On(serviceList, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, "", nil)
+		svc.On(serviceUpdate, mock.Anything, "operational", mock.Anything).
+			Run(func(args mock.Arguments) {
+				fn := args.Get(2).(func(*model.Kube) error)
+				require.NoError(t, fn(k))
+			}).Return(nil)
+
+		profileSvc := new(mockProfileService)
+		profileSvc.On("Get", mock.Anything, mock.Anything).
+			Return(&profile.Profile{}, nil)
+
+		accService := new(accServiceMock)
+		accService.On("Get", mock.Anything, mock.Anything).
+			Return(&model.CloudAccount{Provider: clouds.AWS}, nil)
+
+		nodeProvisioner := new(mockNodeProvisioner)
+		nodeProvisioner.On("RestartNodesProvisioning", mock.Anything,
+			mock.Anything, []string{"nodeTask"}).Return(testCase.restartErr)
+
+		h := NewHandler(svc, accService, profileSvc, nodeProvisioner, nil,
+			repo, nil, "")
+
+		require.NoError(t, NewRecovery(h, testCase.policy).Run(context.Background()))
+
+		if testCase.expectedRestart {
+			nodeProvisioner.AssertCalled(t, "RestartNodesProvisioning",
+				mock.Anything, mock.Anything, []string{"nodeTask"})
+		} else {
+			nodeProvisioner.AssertNotCalled(t, "RestartNodesProvisioning",
+				mock.Anything, mock.Anything, mock.Anything)
+		}
+
+		task, err := workflows.LoadTask(context.Background(), "nodeTask", repo)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expectedStatus, task.Status)
+		require.Equal(t, testCase.expectedMachine, k.Nodes["node-1"].State)
+	}
+}
@@ -23,12 +23,14 @@ import (
 	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/timeconv"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/kubeconfig"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/sghelm/proxy"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
 )
 
@@ -50,7 +52,9 @@ var (
 type Interface interface {
 	Create(ctx context.Context, k *model.Kube) error
 	Get(ctx context.Context, name string) (*model.Kube, error)
+	Update(ctx context.Context, name string, fn func(k *model.Kube) error) error
 	ListAll(ctx context.Context) ([]model.Kube, error)
+	List(ctx context.Context, filter ListFilter, opts paging.Options) ([]model.Kube, string, error)
 	Delete(ctx context.Context, name string) error
 	KubeConfigFor(ctx context.Context, kname, user string) ([]byte, error)
 	ListKubeResources(ctx context.Context, kname string) ([]byte, error)
@@ -72,6 +76,19 @@ type ServerResourceGetter interface {
 	ServerResources() ([]*metav1.APIResourceList, error)
 }
 
+// ListFilter selects kubes in a listing, empty fields match any value.
+type ListFilter struct {
+	State       model.KubeState
+	Provider    clouds.Name
+	AccountName string
+}
+
+func (f ListFilter) matches(k *model.Kube) bool {
+	return (f.State == "" || f.State == k.State) &&
+		(f.Provider == "" || f.Provider == k.Provider) &&
+		(f.AccountName == "" || f.AccountName == k.AccountName)
+}
+
 // Service manages kubernetes clusters.
 type Service struct {
 	discoveryClientFn func(k *model.Kube) (ServerResourceGetter, error)
@@ -102,6 +119,7 @@ func (s Service) Create(ctx context.Context, k *model.Kube) error {
 	if k.ID == "" {
 		k.ID = uuid.New()[:8]
 	}
+	k.SchemaVersion = model.KubeSchemaVersion
 
 	raw, err := json.Marshal(k)
 	if err != nil {
@@ -134,6 +152,33 @@ func (s Service) Get(ctx context.Context, kubeID string) (*model.Kube, error) {
 	return k, nil
 }
 
+// Update applies fn to the latest stored version of a kube and saves the result,
+// fn is applied again to a fresh copy when the kube has been modified concurrently.
+func (s Service) Update(ctx context.Context, kubeID string, fn func(k *model.Kube) error) error {
+	return storage.Update(ctx, s.storage, s.prefix, kubeID, func(raw []byte) ([]byte, error) {
+		if raw == nil {
+			return nil, sgerrors.ErrNotFound
+		}
+
+		k := &model.Kube{}
+		if err := json.Unmarshal(raw, k); err != nil {
+			return nil, errors.Wrap(err, "unmarshal")
+		}
+
+		if err := fn(k); err != nil {
+			return nil, err
+		}
+		k.SchemaVersion = model.KubeSchemaVersion
+
+		raw, err := json.Marshal(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal")
+		}
+
+		return raw, nil
+	})
+}
+
 // ListAll returns all kubes.
 func (s Service) ListAll(ctx context.Context) ([]model.Kube, error) {
 	rawKubes, err := s.storage.GetAll(ctx, s.prefix)
@@ -153,6 +198,56 @@ func (s Service) ListAll(ctx context.Context) ([]model.Kube, error) {
 	return kubes, nil
 }
 
+// List returns a page of kubes that match filter along with
+// a continue token of the next page.
+func (s Service) List(ctx context.Context, filter ListFilter, opts paging.Options) ([]model.Kube, string, error) {
+	kubes := make([]model.Kube, 0)
+
+	page, err := storage.ListFiltered(ctx, s.storage, s.prefix, opts, func(item paging.Item) (bool, error) {
+		k := model.Kube{}
+		if err := json.Unmarshal(item.Value, &k); err != nil {
+			return false, errors.Wrap(err, "unmarshal")
+		}
+
+		if !filter.matches(&k) {
+			return false, nil
+		}
+
+		kubes = append(kubes, k)
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, "", errors.Wrap(err, "storage: list")
+	}
+
+	return kubes, page.Continue, nil
+}
+
+// TasksInUse returns IDs of tasks of kubes that are not operational,
+// provisioning of such kubes may be restarted and needs their tasks.
+func (s Service) TasksInUse(ctx context.Context) (map[string]bool, error) {
+	kubes, err := s.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[string]bool)
+	for _, k := range kubes {
+		if k.State == model.StateOperational {
+			continue
+		}
+
+		for _, taskIDs := range k.Tasks {
+			for _, taskID := range taskIDs {
+				inUse[taskID] = true
+			}
+		}
+	}
+
+	return inUse, nil
+}
+
 // Delete deletes a kube with a specified name.
 func (s Service) Delete(ctx context.Context, kubeID string) error {
 	return s.storage.Delete(ctx, s.prefix, kubeID)
@@ -266,8 +361,9 @@ func (s Service) GetCerts(ctx context.Context, kname, cname string) (*Bundle, er
 
 	// TODO(stgleb): pass host info here
 	r, err := ssh.NewRunner(ssh.Config{
-		User: kube.SSHConfig.User,
-		Key:  []byte(kube.SSHConfig.PublicKey),
+		User:     kube.SSHConfig.User,
+		Key:      []byte(kube.SSHConfig.PublicKey),
+		Bastions: ssh.Bastions(kube.SSHConfig.Bastions),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "setup runner")
@@ -24,6 +24,7 @@ import (
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/sghelm/proxy"
+	"github.com/supergiant/control/pkg/storage/memory"
 	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/testutils/storage"
 )
@@ -160,6 +161,45 @@ func TestKubeServiceCreate(t *testing.T) {
 	}
 }
 
+func TestKubeServiceUpdate(t *testing.T) {
+	prefix := DefaultStoragePrefix
+	repo := memory.NewInMemoryRepository()
+	service := NewService(prefix, repo, nil)
+
+	err := service.Update(context.Background(), "1234", func(k *model.Kube) error {
+		return nil
+	})
+	require.True(t, sgerrors.IsNotFound(err), "unexpected error %v", err)
+
+	require.NoError(t, service.Create(context.Background(), &model.Kube{
+		ID:    "1234",
+		State: model.StateProvisioning,
+	}))
+
+	calls := 0
+	err = service.Update(context.Background(), "1234", func(k *model.Kube) error {
+		calls++
+		// Simulate concurrent writer on the first attempt
+		if calls == 1 {
+			require.NoError(t, service.Create(context.Background(), &model.Kube{
+				ID:    "1234",
+				Name:  "concurrent",
+				State: model.StateProvisioning,
+			}))
+		}
+
+		k.State = model.StateOperational
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	k, err := service.Get(context.Background(), "1234")
+	require.NoError(t, err)
+	require.Equal(t, "concurrent", k.Name)
+	require.Equal(t, model.StateOperational, k.State)
+}
+
 func TestKubeServiceGetAll(t *testing.T) {
 	testCases := []struct {
 		data [][]byte
@@ -196,6 +236,18 @@ func TestKubeServiceGetAll(t *testing.T) {
 	}
 }
 
+func TestService_TasksInUse(t *testing.T) {
+	m := new(testutils.MockStorage)
+	m.On("GetAll", context.Background(), DefaultStoragePrefix).Return([][]byte{
+		[]byte(`{"id":"operational","state":"operational","tasks":{"master":["t1"]}}`),
+		[]byte(`{"id":"failed","state":"failed","tasks":{"master":["t2"],"node":["t3"]}}`),
+	}, nil)
+
+	inUse, err := NewService(DefaultStoragePrefix, m, nil).TasksInUse(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"t2": true, "t3": true}, inUse)
+}
+
 func TestService_InstallRelease(t *testing.T) {
 	tcs := []struct {
 		svc Service
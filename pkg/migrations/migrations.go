@@ -0,0 +1,256 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/storage"
+)
+
+// VersionField is a name of json field that holds schema version
+// of the stored record, records without it have version 0.
+const VersionField = "schemaVersion"
+
+var errUpToDate = errors.New("record is up to date")
+
+// Record is a stored json object decoded to a map, numbers are kept
+// as json.Number so they are written back without precision loss.
+type Record map[string]interface{}
+
+// Migration upgrades record to the Version from the previous one.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(r Record) error
+}
+
+// Kind is a type of records stored under the Prefix.
+type Kind struct {
+	Name       string
+	Prefix     string
+	Version    int
+	Migrations []Migration
+}
+
+// Registry keeps migrations of all kinds of stored records.
+type Registry struct {
+	kinds []*Kind
+}
+
+// RecordReport describes migration of a single record.
+type RecordReport struct {
+	Kind  string `json:"kind"`
+	Key   string `json:"key"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Error string `json:"error,omitempty"`
+}
+
+// Report is a result of migration run, in dry run mode it describes
+// changes that would have been made.
+type Report struct {
+	DryRun   bool            `json:"dryRun"`
+	UpToDate int             `json:"upToDate"`
+	Migrated []*RecordReport `json:"migrated"`
+	Failed   []*RecordReport `json:"failed"`
+}
+
+func (r *Report) String() string {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "dry run: %v, up to date: %d, migrated: %d, failed: %d",
+		r.DryRun, r.UpToDate, len(r.Migrated), len(r.Failed))
+
+	for _, m := range r.Migrated {
+		fmt.Fprintf(buf, "\n%s %s: %d -> %d", m.Kind, m.Key, m.From, m.To)
+	}
+
+	for _, m := range r.Failed {
+		fmt.Fprintf(buf, "\n%s %s: %d -> %d failed: %s",
+			m.Kind, m.Key, m.From, m.To, m.Error)
+	}
+
+	return buf.String()
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds kind of records, migrations must cover every version
+// from 1 to the current version of the kind.
+func (r *Registry) Register(kind Kind) error {
+	for _, k := range r.kinds {
+		if k.Name == kind.Name {
+			return errors.Errorf("kind %s is already registered", kind.Name)
+		}
+	}
+
+	sort.Slice(kind.Migrations, func(i, j int) bool {
+		return kind.Migrations[i].Version < kind.Migrations[j].Version
+	})
+
+	if len(kind.Migrations) != kind.Version {
+		return errors.Errorf("kind %s version %d has %d migrations",
+			kind.Name, kind.Version, len(kind.Migrations))
+	}
+
+	for i, m := range kind.Migrations {
+		if m.Version != i+1 {
+			return errors.Errorf("kind %s has no migration to version %d",
+				kind.Name, i+1)
+		}
+	}
+
+	r.kinds = append(r.kinds, &kind)
+	return nil
+}
+
+// Run upgrades all records of registered kinds to their current versions.
+// Records are checked first and nothing is written if any of them can not
+// be migrated, in dry run mode records are only checked.
+func (r *Registry) Run(ctx context.Context, s storage.Interface, dryRun bool) (*Report, error) {
+	report, err := r.run(ctx, s, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun || len(report.Failed) > 0 || len(report.Migrated) == 0 {
+		report.DryRun = dryRun
+		return report, nil
+	}
+
+	return r.run(ctx, s, false)
+}
+
+func (r *Registry) run(ctx context.Context, s storage.Interface, dryRun bool) (*Report, error) {
+	report := &Report{
+		DryRun:   dryRun,
+		Migrated: make([]*RecordReport, 0),
+		Failed:   make([]*RecordReport, 0),
+	}
+
+	for _, kind := range r.kinds {
+		keys, err := s.ListKeys(ctx, kind.Prefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list %s", kind.Name)
+		}
+
+		for _, key := range keys {
+			rr := &RecordReport{
+				Kind: kind.Name,
+				Key:  key,
+				To:   kind.Version,
+			}
+
+			if dryRun {
+				var value []byte
+				value, err = s.Get(ctx, "", key)
+				if err == nil {
+					_, err = kind.migrate(value, rr)
+				}
+			} else {
+				err = storage.Update(ctx, s, "", key, func(value []byte) ([]byte, error) {
+					return kind.migrate(value, rr)
+				})
+			}
+
+			switch {
+			case err == errUpToDate:
+				report.UpToDate++
+			case err != nil:
+				rr.Error = err.Error()
+				report.Failed = append(report.Failed, rr)
+			default:
+				report.Migrated = append(report.Migrated, rr)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (k *Kind) migrate(value []byte, rr *RecordReport) ([]byte, error) {
+	if value == nil {
+		return nil, errUpToDate
+	}
+
+	record := Record{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&record); err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+
+	version, err := record.Version()
+	if err != nil {
+		return nil, err
+	}
+	rr.From = version
+
+	if version == k.Version {
+		return nil, errUpToDate
+	}
+
+	if version > k.Version {
+		return nil, errors.Errorf("record version %d is newer than supported %d",
+			version, k.Version)
+	}
+
+	for _, m := range k.Migrations[version:] {
+		if err := m.Apply(record); err != nil {
+			return nil, errors.Wrapf(err, "migrate to version %d", m.Version)
+		}
+		record[VersionField] = m.Version
+	}
+
+	return json.Marshal(record)
+}
+
+// Version returns schema version of the record
+func (r Record) Version() (int, error) {
+	v, ok := r[VersionField]
+	if !ok || v == nil {
+		return 0, nil
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, errors.Errorf("wrong %s type %T", VersionField, v)
+	}
+
+	version, err := n.Int64()
+	if err != nil {
+		return 0, errors.Wrap(err, VersionField)
+	}
+
+	return int(version), nil
+}
+
+// Object returns nested object by path, nil is returned if any of
+// path elements is missing or is not an object.
+func (r Record) Object(path ...string) Record {
+	current := r
+
+	for _, name := range path {
+		next, ok := current[name].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+
+	return current
+}
+
+// String returns string field value or empty string
+func (r Record) String(name string) string {
+	s, _ := r[name].(string)
+	return s
+}
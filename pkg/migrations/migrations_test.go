@@ -0,0 +1,255 @@
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/kube"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/workflows"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	testCases := []struct {
+		description string
+		kind        Kind
+		hasErr      bool
+	}{
+		{
+			description: "success",
+			kind: Kind{
+				Name:    "test",
+				Version: 2,
+				Migrations: []Migration{
+					{Version: 2},
+					{Version: 1},
+				},
+			},
+		},
+		{
+			description: "missing migration",
+			kind: Kind{
+				Name:    "test",
+				Version: 2,
+				Migrations: []Migration{
+					{Version: 2},
+				},
+			},
+			hasErr: true,
+		},
+		{
+			description: "gap",
+			kind: Kind{
+				Name:    "test",
+				Version: 2,
+				Migrations: []Migration{
+					{Version: 1},
+					{Version: 3},
+				},
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		err := NewRegistry().Register(testCase.kind)
+
+		if testCase.hasErr != (err != nil) {
+			t.Errorf("Unexpected error value %v", err)
+		}
+	}
+
+	r := NewRegistry()
+	r.Register(Kind{Name: "test"})
+
+	if err := r.Register(Kind{Name: "test"}); err == nil {
+		t.Errorf("Duplicate kind must not be registered")
+	}
+}
+
+func TestRegistry_Run(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryRepository()
+
+	repo.Put(ctx, "/test/", "old", []byte(`{"name":"old","big":1234567890123456789}`))
+	repo.Put(ctx, "/test/", "current", []byte(`{"schemaVersion":2,"name":"current"}`))
+
+	r := NewRegistry()
+	err := r.Register(Kind{
+		Name:    "test",
+		Prefix:  "/test/",
+		Version: 2,
+		Migrations: []Migration{
+			{
+				Version: 1,
+				Apply: func(r Record) error {
+					r["first"] = true
+					return nil
+				},
+			},
+			{
+				Version: 2,
+				Apply: func(r Record) error {
+					r["second"] = r["first"]
+					return nil
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	report, err := r.Run(ctx, repo, true)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !report.DryRun || report.UpToDate != 1 || len(report.Migrated) != 1 {
+		t.Errorf("Wrong dry run report %s", report)
+	}
+
+	data, _ := repo.Get(ctx, "/test/", "old")
+	if string(data) != `{"name":"old","big":1234567890123456789}` {
+		t.Errorf("Record must not be changed in dry run %s", data)
+	}
+
+	report, err = r.Run(ctx, repo, false)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if report.DryRun || len(report.Migrated) != 1 || report.Migrated[0].From != 0 {
+		t.Errorf("Wrong report %s", report)
+	}
+
+	data, _ = repo.Get(ctx, "/test/", "old")
+	if string(data) != `{"big":1234567890123456789,"first":true,"name":"old","schemaVersion":2,"second":true}` {
+		t.Errorf("Wrong migrated record %s", data)
+	}
+}
+
+func TestRegistry_RunFailed(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryRepository()
+
+	repo.Put(ctx, "/test/", "1", []byte(`{"name":"1"}`))
+	repo.Put(ctx, "/test/", "2", []byte(`{"name":"2","fail":true}`))
+	repo.Put(ctx, "/test/", "3", []byte(`{"name":"3","schemaVersion":5}`))
+
+	r := NewRegistry()
+	r.Register(Kind{
+		Name:    "test",
+		Prefix:  "/test/",
+		Version: 1,
+		Migrations: []Migration{
+			{
+				Version: 1,
+				Apply: func(r Record) error {
+					if r["fail"] == true {
+						return errors.New("fail")
+					}
+					return nil
+				},
+			},
+		},
+	})
+
+	report, err := r.Run(ctx, repo, false)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(report.Failed) != 2 || len(report.Migrated) != 1 {
+		t.Errorf("Wrong report %s", report)
+	}
+
+	data, _ := repo.Get(ctx, "/test/", "1")
+	if string(data) != `{"name":"1"}` {
+		t.Errorf("Nothing must be written when any record fails %s", data)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryRepository()
+
+	repo.Put(ctx, kube.DefaultStoragePrefix, "k1",
+		[]byte(`{"id":"k1","apiPort":"6443","apibindPort":0}`))
+	repo.Put(ctx, workflows.Prefix, "t1",
+		[]byte(`{"id":"t1","config":{"kube":{"apiPort":"443"},"certificatesConfig":{"Username":"root","Password":"1234"}}}`))
+	repo.Put(ctx, profile.DefaultKubeProfilePreifx, "p1",
+		[]byte(`{"id":"p1","user":"root","password":"1234","staticAuth":{"basicAuth":[{"name":"admin"}]}}`))
+	repo.Put(ctx, account.DefaultStoragePrefix, "a1",
+		[]byte(`{"name":"a1","provider":"aws"}`))
+
+	r, err := Default()
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	report, err := r.Run(ctx, repo, false)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(report.Migrated) != 4 || len(report.Failed) != 0 {
+		t.Fatalf("Wrong report %s", report)
+	}
+
+	k := &model.Kube{}
+	data, _ := repo.Get(ctx, kube.DefaultStoragePrefix, "k1")
+	json.Unmarshal(data, k)
+
+	if k.APIServerPort != 6443 || k.SchemaVersion != model.KubeSchemaVersion {
+		t.Errorf("Wrong kube %s", data)
+	}
+
+	task := &workflows.Task{}
+	data, _ = repo.Get(ctx, workflows.Prefix, "t1")
+	if err := json.Unmarshal(data, task); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if task.Config.Kube.APIServerPort != 443 ||
+		len(task.Config.CertificatesConfig.StaticAuth.BasicAuth) != 1 ||
+		task.SchemaVersion != workflows.TaskSchemaVersion {
+		t.Errorf("Wrong task %s", data)
+	}
+
+	p := &profile.Profile{}
+	data, _ = repo.Get(ctx, profile.DefaultKubeProfilePreifx, "p1")
+	json.Unmarshal(data, p)
+
+	if len(p.StaticAuth.BasicAuth) != 2 || p.StaticAuth.BasicAuth[1].Password != "1234" {
+		t.Errorf("Wrong profile %s", data)
+	}
+
+	acc := &model.CloudAccount{}
+	data, _ = repo.Get(ctx, account.DefaultStoragePrefix, "a1")
+	json.Unmarshal(data, acc)
+
+	if acc.SchemaVersion != model.CloudAccountSchemaVersion {
+		t.Errorf("Wrong account %s", data)
+	}
+
+	// Migrated records stay untouched on the next run
+	report, err = r.Run(ctx, repo, false)
+
+	if err != nil || len(report.Migrated) != 0 || report.UpToDate != 4 {
+		t.Errorf("Wrong report %s error %v", report, err)
+	}
+}
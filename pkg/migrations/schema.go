@@ -0,0 +1,142 @@
+package migrations
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/kube"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/workflows"
+)
+
+// Default returns registry with migrations of kubes, tasks,
+// profiles and cloud accounts.
+func Default() (*Registry, error) {
+	r := NewRegistry()
+
+	kinds := []Kind{
+		{
+			Name:    "kube",
+			Prefix:  kube.DefaultStoragePrefix,
+			Version: model.KubeSchemaVersion,
+			Migrations: []Migration{
+				{
+					Version:     1,
+					Description: "move deprecated apiPort to apibindPort",
+					Apply:       moveAPIPort,
+				},
+			},
+		},
+		{
+			Name:    "task",
+			Prefix:  workflows.Prefix,
+			Version: workflows.TaskSchemaVersion,
+			Migrations: []Migration{
+				{
+					Version:     1,
+					Description: "move deprecated kube apiPort and certificates credentials",
+					Apply: func(r Record) error {
+						if k := r.Object("config", "kube"); k != nil {
+							if err := moveAPIPort(k); err != nil {
+								return err
+							}
+						}
+
+						if c := r.Object("config", "certificatesConfig"); c != nil {
+							moveBasicAuth(c, c.String("Username"), c.String("Password"))
+						}
+
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:    "profile",
+			Prefix:  profile.DefaultKubeProfilePreifx,
+			Version: profile.SchemaVersion,
+			Migrations: []Migration{
+				{
+					Version:     1,
+					Description: "move deprecated user and password to static auth",
+					Apply: func(r Record) error {
+						moveBasicAuth(r, r.String("user"), r.String("password"))
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:    "account",
+			Prefix:  account.DefaultStoragePrefix,
+			Version: model.CloudAccountSchemaVersion,
+			Migrations: []Migration{
+				{
+					Version:     1,
+					Description: "add schema version",
+					Apply: func(r Record) error {
+						return nil
+					},
+				},
+			},
+		},
+	}
+
+	for _, kind := range kinds {
+		if err := r.Register(kind); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// moveAPIPort fills apibindPort of the kube from deprecated apiPort
+func moveAPIPort(k Record) error {
+	if port, ok := k["apibindPort"].(json.Number); ok && port.String() != "0" {
+		return nil
+	}
+
+	apiPort := k.String("apiPort")
+	if apiPort == "" {
+		return nil
+	}
+
+	port, err := strconv.ParseInt(apiPort, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	k["apibindPort"] = json.Number(strconv.FormatInt(port, 10))
+	return nil
+}
+
+// moveBasicAuth adds deprecated user credentials to the static auth
+// of the record unless a user with the same name is already there.
+func moveBasicAuth(r Record, user, password string) {
+	if user == "" {
+		return
+	}
+
+	staticAuth := r.Object("staticAuth")
+	if staticAuth == nil {
+		staticAuth = Record{}
+		r["staticAuth"] = map[string]interface{}(staticAuth)
+	}
+
+	users, _ := staticAuth["basicAuth"].([]interface{})
+
+	for _, u := range users {
+		if existing, ok := u.(map[string]interface{}); ok && existing["name"] == user {
+			return
+		}
+	}
+
+	staticAuth["basicAuth"] = append(users, map[string]interface{}{
+		"name":     user,
+		"id":       user,
+		"password": password,
+	})
+}
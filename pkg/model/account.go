@@ -4,9 +4,14 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 )
 
+// CloudAccountSchemaVersion is a version of stored cloud account record.
+const CloudAccountSchemaVersion = 1
+
 // CloudAccount is settings of account in public or private cloud (e.g. AWS, vCenter)
 // Name should be unique.
 type CloudAccount struct {
+	SchemaVersion int `json:"schemaVersion" valid:"-"`
+
 	Name        string            `json:"name" valid:"required, length(1|32)"`
 	Provider    clouds.Name       `json:"provider" valid:"in(aws|digitalocean|gce|azure)"`
 	Credentials map[string]string `json:"credentials" valid:"optional"`
@@ -16,8 +16,14 @@ const (
 	StateUpgrading    KubeState = "upgrading"
 )
 
+// KubeSchemaVersion is a version of stored kube record, it must be
+// increased along with adding a migration for kube records.
+const KubeSchemaVersion = 1
+
 // Kube represents a kubernetes cluster.
 type Kube struct {
+	SchemaVersion int `json:"schemaVersion" valid:"-"`
+
 	ID           string      `json:"id" valid:"-"`
 	State        KubeState   `json:"state"`
 	Name         string      `json:"name" valid:"required"`
@@ -71,6 +77,19 @@ type SSHConfig struct {
 	BootstrapPublicKey  string `json:"bootstrapPublicKey"`
 	PublicKey           string `json:"publicKey"`
 	Timeout             int    `json:"timeout"`
+
+	// Bastions are jump hosts in order connections go through them
+	Bastions []profile.BastionHost `json:"bastions,omitempty"`
+}
+
+// Host returns address machine is reachable at over ssh, machines
+// behind bastions are reached by private ip.
+func (c SSHConfig) Host(m Machine) string {
+	if len(c.Bastions) > 0 && m.PrivateIp != "" {
+		return m.PrivateIp
+	}
+
+	return m.PublicIp
 }
 
 // Auth holds all possible auth parameters.
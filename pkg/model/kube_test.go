@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/supergiant/control/pkg/profile"
+)
+
+func TestSSHConfig_Host(t *testing.T) {
+	m := Machine{
+		PublicIp:  "1.2.3.4",
+		PrivateIp: "10.0.0.2",
+	}
+
+	if host := (SSHConfig{}).Host(m); host != m.PublicIp {
+		t.Errorf("expected public ip %s actual %s", m.PublicIp, host)
+	}
+
+	c := SSHConfig{
+		Bastions: []profile.BastionHost{
+			{
+				Host: "5.6.7.8",
+			},
+		},
+	}
+
+	if host := c.Host(m); host != m.PrivateIp {
+		t.Errorf("expected private ip %s actual %s", m.PrivateIp, host)
+	}
+
+	if host := c.Host(Machine{PublicIp: m.PublicIp}); host != m.PublicIp {
+		t.Errorf("expected public ip %s actual %s", m.PublicIp, host)
+	}
+}
@@ -41,6 +41,9 @@ type Machine struct {
 	State            MachineState `json:"state"`
 	Name             string       `json:"name"`
 	SelfLink         string       `json:"selfLink"`
+	// HostKey is a public ssh key of the machine in authorized_keys format
+	// that is captured on first contact, connections are verified against it.
+	HostKey string `json:"hostKey,omitempty"`
 }
 
 func (m Machine) String() string {
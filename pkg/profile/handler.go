@@ -9,7 +9,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"gopkg.in/asaskevich/govalidator.v8"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
 )
 
 type Handler struct {
@@ -74,12 +76,29 @@ func (h *Handler) CreateProfile(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetProfiles(w http.ResponseWriter, r *http.Request) {
-	profiles, err := h.service.GetAll(r.Context())
+	opts, err := paging.FromQuery(r.URL.Query())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	provider := clouds.Name(r.URL.Query().Get("provider"))
+
+	profiles, next, err := h.service.List(r.Context(), provider, opts)
+	if err != nil {
+		if paging.IsInvalid(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if next != "" {
+		w.Header().Set(paging.ContinueHeader, next)
+	}
+
 	if err := json.NewEncoder(w).Encode(profiles); err != nil {
 		logrus.Error(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/testutils"
 )
 
@@ -320,8 +321,16 @@ func TestService_GetAll(t *testing.T) {
 
 	for _, testCase := range testCases {
 		mockRepo := &testutils.MockStorage{}
-		mockRepo.On("GetAll", mock.Anything, mock.Anything).
-			Return(testCase.getAllData, testCase.repoErr)
+		var page *paging.Page
+		if testCase.getAllData != nil {
+			page = &paging.Page{}
+			for _, value := range testCase.getAllData {
+				page.Items = append(page.Items, paging.Item{Value: value})
+			}
+		}
+
+		mockRepo.On("List", mock.Anything, mock.Anything, mock.Anything).
+			Return(page, testCase.repoErr)
 		svc := &Service{
 			prefix:             "prefix",
 			kubeProfileStorage: mockRepo,
@@ -2,7 +2,12 @@ package profile
 
 import "github.com/supergiant/control/pkg/clouds"
 
+// SchemaVersion is a version of stored profile record.
+const SchemaVersion = 1
+
 type Profile struct {
+	SchemaVersion int `json:"schemaVersion" valid:"-"`
+
 	ID string `json:"id" valid:"required"`
 
 	MasterProfiles []NodeProfile `json:"masterProfiles" valid:"-"`
@@ -44,11 +49,31 @@ type Profile struct {
 	// ExposedAddresses is a list of cidr/port pairs that will be exposes
 	// by cloud provider security groups.
 	ExposedAddresses []Addresses `json:"exposedAddresses" valid:"-"`
+
+	// RollbackOnFailure removes cloud resources created by provisioning
+	// tasks of the cluster when they fail instead of leaving them for retry.
+	RollbackOnFailure bool `json:"rollbackOnFailure" valid:"-"`
+
+	// Bastions is a chain of jump hosts machines of the cluster are reached
+	// through, so clusters in private subnets can be provisioned.
+	Bastions []BastionHost `json:"bastions" valid:"-"`
 }
 
 type NodeProfile map[string]string
 type CloudSpecificSettings map[string]string
 
+// BastionHost is a jump host of ssh connections, user and private
+// key of the cluster are used when they are not set.
+type BastionHost struct {
+	Host       string `json:"host"`
+	Port       string `json:"port"`
+	User       string `json:"user"`
+	PrivateKey string `json:"privateKey"`
+	// HostKey is a public key the bastion presents in authorized_keys
+	// format, it is trusted on first use when empty.
+	HostKey string `json:"hostKey"`
+}
+
 // Addresses uses cidr to define an ip list.
 type Addresses struct {
 	CIDR string `json:"cidr"`
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
 )
 
 const DefaultKubeProfilePreifx = "/supergiant/profile"
@@ -43,6 +46,7 @@ func (s *Service) Get(ctx context.Context, profileId string) (*Profile, error) {
 }
 
 func (s *Service) Create(ctx context.Context, profile *Profile) error {
+	profile.SchemaVersion = SchemaVersion
 	profileData, err := json.Marshal(profile)
 
 	if err != nil {
@@ -76,3 +80,29 @@ func (s *Service) GetAll(ctx context.Context) ([]Profile, error) {
 
 	return profiles, nil
 }
+
+// List returns a page of profiles of the provider or profiles of all
+// providers if it is empty, along with continue token of the next page.
+func (s *Service) List(ctx context.Context, provider clouds.Name, opts paging.Options) ([]Profile, string, error) {
+	profiles := make([]Profile, 0)
+
+	page, err := storage.ListFiltered(ctx, s.kubeProfileStorage, s.prefix, opts, func(item paging.Item) (bool, error) {
+		profile := Profile{}
+		if err := json.Unmarshal(item.Value, &profile); err != nil {
+			return false, err
+		}
+
+		if provider != "" && profile.Provider != provider {
+			return false, nil
+		}
+
+		profiles = append(profiles, profile)
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, "", errors.Wrap(err, "list profiles")
+	}
+
+	return profiles, page.Continue, nil
+}
@@ -58,11 +58,11 @@ func TestKubeProfileServiceCreate(t *testing.T) {
 		err     error
 	}{
 		{
-			profile: &Profile{},
+			profile: &Profile{SchemaVersion: SchemaVersion},
 			err:     nil,
 		},
 		{
-			profile: &Profile{},
+			profile: &Profile{SchemaVersion: SchemaVersion},
 			err:     errors.New("test err"),
 		},
 	}
@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/amazon"
+	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
+	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
+	"github.com/supergiant/control/pkg/workflows/steps/ssh"
+)
+
+// Provider provisions kubes in AWS
+type Provider struct{}
+
+// Init registers AWS provider
+func Init() {
+	providers.Register(Provider{})
+}
+
+func (Provider) Name() clouds.Name {
+	return clouds.AWS
+}
+
+func (Provider) BindCredentials(credentials map[string]string, config *steps.Config) error {
+	return util.BindParams(credentials, &config.AWSConfig)
+}
+
+func (Provider) ValidateCredentials(creds map[string]string) error {
+	config := &steps.AWSConfig{}
+	err := util.BindParams(creds, config)
+
+	if err != nil {
+		return err
+	}
+
+	awsCfg := aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials(config.KeyID, config.Secret, ""),
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config: awsCfg,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	ec2Client := ec2.New(sess)
+
+	_, err = ec2Client.DescribeKeyPairs(new(ec2.DescribeKeyPairsInput))
+	return err
+}
+
+func (Provider) BindNodeProfile(nodeProfile profile.NodeProfile, config *steps.Config) error {
+	return util.BindParams(nodeProfile, &config.AWSConfig)
+}
+
+func (Provider) MergeConfig(source *steps.Config, destination *steps.Config) error {
+	return providers.CopySettings(&source.AWSConfig, &destination.AWSConfig)
+}
+
+func (Provider) SaveToKube(config *steps.Config, k *model.Kube) {
+	// Save az to subnets mapping for this cluster
+	k.Subnets = config.AWSConfig.Subnets
+
+	// Copy data got from pre provision step to cloud specific settings of kube
+	k.CloudSpec[clouds.AwsAZ] = config.AWSConfig.AvailabilityZone
+	k.CloudSpec[clouds.AwsVpcCIDR] = config.AWSConfig.VPCCIDR
+	k.CloudSpec[clouds.AwsVpcID] = config.AWSConfig.VPCID
+	k.CloudSpec[clouds.AwsKeyPairName] = config.AWSConfig.KeyPairName
+	k.CloudSpec[clouds.AwsMastersSecGroupID] =
+		config.AWSConfig.MastersSecurityGroupID
+	k.CloudSpec[clouds.AwsNodesSecgroupID] =
+		config.AWSConfig.NodesSecurityGroupID
+	// TODO(stgleb): this must be done for all types of clouds
+	k.CloudSpec[clouds.AwsSshBootstrapPrivateKey] =
+		config.Kube.SSHConfig.BootstrapPrivateKey
+	k.CloudSpec[clouds.AwsUserProvidedSshPublicKey] =
+		config.Kube.SSHConfig.PublicKey
+	k.CloudSpec[clouds.AwsRouteTableID] =
+		config.AWSConfig.RouteTableID
+	k.CloudSpec[clouds.AwsInternetGateWayID] =
+		config.AWSConfig.InternetGatewayID
+	k.CloudSpec[clouds.AwsMasterInstanceProfile] =
+		config.AWSConfig.MastersInstanceProfile
+	k.CloudSpec[clouds.AwsNodeInstanceProfile] =
+		config.AWSConfig.NodesInstanceProfile
+	k.CloudSpec[clouds.AwsImageID] =
+		config.AWSConfig.ImageID
+	k.CloudSpec[clouds.AwsExternalLoadBalancerName] =
+		config.AWSConfig.ExternalLoadBalancerName
+	k.CloudSpec[clouds.AwsInternalLoadBalancerName] =
+		config.AWSConfig.InternalLoadBalancerName
+	k.CloudSpec[clouds.AwsVolumeSize] =
+		config.AWSConfig.VolumeSize
+}
+
+func (Provider) LoadFromKube(k *model.Kube, config *steps.Config) error {
+	// Load AZ -> subnet mapping for cluster
+	config.AWSConfig.Subnets = k.Subnets
+	config.AWSConfig.Region = k.Region
+	config.AWSConfig.AvailabilityZone = k.CloudSpec[clouds.AwsAZ]
+	config.AWSConfig.VPCCIDR = k.CloudSpec[clouds.AwsVpcCIDR]
+	config.AWSConfig.VPCID = k.CloudSpec[clouds.AwsVpcID]
+	config.AWSConfig.KeyPairName = k.CloudSpec[clouds.AwsKeyPairName]
+	config.AWSConfig.MastersSecurityGroupID = k.CloudSpec[clouds.AwsMastersSecGroupID]
+	config.AWSConfig.NodesSecurityGroupID = k.CloudSpec[clouds.AwsNodesSecgroupID]
+	config.AWSConfig.RouteTableID = k.CloudSpec[clouds.AwsRouteTableID]
+	config.AWSConfig.InternetGatewayID = k.CloudSpec[clouds.AwsInternetGateWayID]
+	config.AWSConfig.MastersInstanceProfile = k.CloudSpec[clouds.AwsMasterInstanceProfile]
+	config.AWSConfig.NodesInstanceProfile = k.CloudSpec[clouds.AwsNodeInstanceProfile]
+	config.AWSConfig.ImageID = k.CloudSpec[clouds.AwsImageID]
+	config.Kube.SSHConfig.BootstrapPrivateKey = k.CloudSpec[clouds.AwsSshBootstrapPrivateKey]
+	config.Kube.SSHConfig.PublicKey = k.CloudSpec[clouds.AwsUserProvidedSshPublicKey]
+	config.AWSConfig.ExternalLoadBalancerName = k.CloudSpec[clouds.AwsExternalLoadBalancerName]
+	config.AWSConfig.InternalLoadBalancerName = k.CloudSpec[clouds.AwsInternalLoadBalancerName]
+	config.AWSConfig.VolumeSize = k.CloudSpec[clouds.AwsVolumeSize]
+
+	return nil
+}
+
+// InfraSteps are retried with amazon.RetryPolicy as
+// AWS API throttles clusters created at the same time.
+func (Provider) InfraSteps() []steps.Step {
+	infra := []steps.Step{
+		steps.GetStep(amazon.StepFindAMI),
+		steps.GetStep(amazon.StepCreateVPC),
+		steps.GetStep(amazon.StepCreateSecurityGroups),
+		steps.GetStep(amazon.StepNameCreateInstanceProfiles),
+		steps.GetStep(amazon.ImportKeyPairStepName),
+		steps.GetStep(amazon.StepCreateInternetGateway),
+		steps.GetStep(amazon.StepCreateSubnets),
+		steps.GetStep(amazon.StepCreateRouteTable),
+		steps.GetStep(amazon.StepAssociateRouteTable),
+		steps.GetStep(amazon.StepCreateLoadBalancer),
+	}
+
+	for i, step := range infra {
+		// keep steps that are not registered as they are
+		if step != nil {
+			infra[i] = steps.WithRetry(step, amazon.RetryPolicy)
+		}
+	}
+
+	return infra
+}
+
+func (Provider) CreateMachineStep() steps.Step {
+	return steps.GetStep(amazon.StepNameCreateEC2Instance)
+}
+
+func (Provider) DeleteMachineStep() steps.Step {
+	return steps.GetStep(amazon.DeleteNodeStepName)
+}
+
+func (Provider) RegisterMachineSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(amazon.RegisterInstanceStepName),
+	}
+}
+
+func (Provider) PostStartSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(amazon.StepCreateTags),
+	}
+}
+
+func (Provider) DeleteClusterSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(amazon.DeleteClusterMachinesStepName),
+		steps.GetStep(amazon.DeleteLoadBalancerStepName),
+		steps.GetStep(amazon.DeleteSecurityGroupsStepName),
+		steps.GetStep(amazon.DisassociateRouteTableStepName),
+		steps.GetStep(amazon.DeleteSubnetsStepName),
+		steps.GetStep(amazon.DeleteRouteTableStepName),
+		steps.GetStep(amazon.DeleteInternetGatewayStepName),
+		steps.GetStep(amazon.DeleteKeyPairStepName),
+		steps.GetStep(amazon.DeleteVPCStepName),
+	}
+}
+
+func (Provider) ImportSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(amazon.ImportClusterMachinesStepName),
+		steps.GetStep(amazon.ImportSubnetsStepName),
+		steps.GetStep(amazon.ImportInternetGatewayStepName),
+		steps.GetStep(amazon.ImporRouteTablesStepName),
+		steps.GetStep(ssh.StepName),
+		steps.GetStep(authorizedkeys.StepName),
+		steps.GetStep(bootstraptoken.StepName),
+	}
+}
+
+func (Provider) KubernetesCloudProvider() string {
+	return "aws"
+}
+
+func (Provider) RegionsGetter(acc *model.CloudAccount, config *steps.Config) (providers.RegionsGetter, error) {
+	// We need to provide region to AWS even if our
+	// request does not specify region
+	config.AWSConfig.Region = "us-west-1"
+
+	finder, err := account.NewAWSFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+func (Provider) ZonesGetter(acc *model.CloudAccount, config *steps.Config) (providers.ZonesGetter, error) {
+	finder, err := account.NewAWSFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+func (Provider) TypesGetter(acc *model.CloudAccount, config *steps.Config) (providers.TypesGetter, error) {
+	finder, err := account.NewAWSFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
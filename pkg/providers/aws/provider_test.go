@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestProvider_BindCredentials(t *testing.T) {
+	config := &steps.Config{}
+
+	require.NoError(t, Provider{}.BindCredentials(map[string]string{
+		"access_key":  "1",
+		"secret_key":  "secret-key",
+		"keyPairName": "my-key-pair",
+		"publicKey":   "test-public-key",
+	}, config))
+	require.Equal(t, "1", config.AWSConfig.KeyID)
+	require.Equal(t, "secret-key", config.AWSConfig.Secret)
+	require.Equal(t, "my-key-pair", config.AWSConfig.KeyPairName)
+}
+
+func TestProvider_LoadFromKube(t *testing.T) {
+	k := &model.Kube{
+		Region: "us-east-1",
+		Subnets: map[string]string{
+			"us-east-1a": "subnet",
+		},
+		CloudSpec: map[string]string{
+			clouds.AwsImageID:                "imageId",
+			clouds.AwsVpcID:                  "vpcId",
+			clouds.AwsNodeInstanceProfile:    "nodeProfile",
+			clouds.AwsMasterInstanceProfile:  "masterProfile",
+			clouds.AwsInternetGateWayID:      "internetGWId",
+			clouds.AwsNodesSecgroupID:        "nodesSecurityId",
+			clouds.AwsMastersSecGroupID:      "masterSecGroup",
+			clouds.AwsAZ:                     "az",
+			clouds.AwsRouteTableID:           "routetableid",
+			clouds.AwsKeyPairName:            "keyPairName",
+			clouds.AwsSshBootstrapPrivateKey: "bootstrapKey",
+		},
+	}
+	config := &steps.Config{}
+
+	require.NoError(t, Provider{}.LoadFromKube(k, config))
+	require.Equal(t, "us-east-1", config.AWSConfig.Region)
+	require.Equal(t, k.Subnets, config.AWSConfig.Subnets)
+	require.Equal(t, "imageId", config.AWSConfig.ImageID)
+	require.Equal(t, "vpcId", config.AWSConfig.VPCID)
+	require.Equal(t, "nodeProfile", config.AWSConfig.NodesInstanceProfile)
+	require.Equal(t, "masterProfile", config.AWSConfig.MastersInstanceProfile)
+	require.Equal(t, "internetGWId", config.AWSConfig.InternetGatewayID)
+	require.Equal(t, "nodesSecurityId", config.AWSConfig.NodesSecurityGroupID)
+	require.Equal(t, "masterSecGroup", config.AWSConfig.MastersSecurityGroupID)
+	require.Equal(t, "az", config.AWSConfig.AvailabilityZone)
+	require.Equal(t, "routetableid", config.AWSConfig.RouteTableID)
+	require.Equal(t, "keyPairName", config.AWSConfig.KeyPairName)
+	require.Equal(t, "bootstrapKey", config.Kube.SSHConfig.BootstrapPrivateKey)
+}
+
+func TestProvider_SaveToKube(t *testing.T) {
+	config := &steps.Config{}
+	config.AWSConfig.VPCID = "vpcId"
+	config.AWSConfig.KeyPairName = "keyPairName"
+	config.AWSConfig.Subnets = map[string]string{"us-east-1a": "subnet"}
+
+	k := &model.Kube{
+		CloudSpec: make(map[string]string),
+	}
+	Provider{}.SaveToKube(config, k)
+
+	loaded := &steps.Config{}
+	require.NoError(t, Provider{}.LoadFromKube(k, loaded))
+	require.Equal(t, config.AWSConfig.Subnets, loaded.AWSConfig.Subnets)
+	require.Equal(t, "vpcId", loaded.AWSConfig.VPCID)
+	require.Equal(t, "keyPairName", loaded.AWSConfig.KeyPairName)
+}
+
+func TestProvider_KubernetesCloudProvider(t *testing.T) {
+	if opt := (Provider{}).KubernetesCloudProvider(); opt != "aws" {
+		t.Errorf("Wrong cloud provider expected %q actual %q", "aws", opt)
+	}
+}
@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/amazon"
+)
+
+// RequestSpotInstances requests persistent spot instances, they are tagged
+// as machines of the kube once the request is fulfilled.
+func (Provider) RequestSpotInstances(req *providers.SpotRequest, config *steps.Config) error {
+	svc, err := amazon.GetEC2(config.AWSConfig)
+
+	if err != nil {
+		return errors.Wrap(err, "get EC2 client")
+	}
+
+	config.AWSConfig.InstanceType = req.MachineType
+	volumeSize, err := strconv.ParseInt(config.AWSConfig.VolumeSize, 10, 64)
+
+	if err != nil {
+		return errors.Wrapf(err, "parse volume size %s", config.AWSConfig.VolumeSize)
+	}
+
+	input := &ec2.RequestSpotInstancesInput{
+		Type: aws.String("persistent"),
+		LaunchSpecification: &ec2.RequestSpotLaunchSpecification{
+			IamInstanceProfile: &ec2.IamInstanceProfileSpecification{
+				Name: aws.String(config.AWSConfig.NodesInstanceProfile),
+			},
+			SubnetId:         aws.String(config.AWSConfig.Subnets[req.AvailabilityZone]),
+			SecurityGroupIds: []*string{aws.String(config.AWSConfig.NodesSecurityGroupID)},
+			ImageId:          aws.String(config.AWSConfig.ImageID),
+			InstanceType:     aws.String(config.AWSConfig.InstanceType),
+			KeyName:          aws.String(config.AWSConfig.KeyPairName),
+			BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+				{
+					DeviceName: aws.String("/dev/sda1"),
+					Ebs: &ec2.EbsBlockDevice{
+						DeleteOnTermination: aws.Bool(false),
+						VolumeType:          aws.String("gp2"),
+						VolumeSize:          aws.Int64(volumeSize),
+					},
+				},
+			},
+			UserData: aws.String(base64.StdEncoding.EncodeToString([]byte(
+				fmt.Sprintf("#!/bin/sh\n%s", config.ConfigMap.Data)))),
+		},
+		SpotPrice:     aws.String(req.SpotPrice),
+		ClientToken:   aws.String(uuid.New()),
+		InstanceCount: aws.Int64(req.MachineCount),
+		DryRun:        aws.Bool(config.DryRun),
+		ValidFrom:     aws.Time(time.Now().Add(time.Second * 10)),
+		// TODO(stgleb): pass this as a parameter
+		ValidUntil: aws.Time(time.Now().Add(time.Duration(24*365) * time.Hour)),
+	}
+
+	result, err := svc.RequestSpotInstances(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			logrus.Errorf("request spot instance caused %s", aerr.Message())
+		} else {
+			logrus.Errorf("Error %v", err)
+		}
+		return errors.Wrap(err, "request spot instance")
+	}
+
+	go func() {
+		requestIds := make([]*string, 0)
+
+		for _, spot := range result.SpotInstanceRequests {
+			requestIds = append(requestIds, spot.SpotInstanceRequestId)
+		}
+
+		describeReq := &ec2.DescribeSpotInstanceRequestsInput{
+			DryRun:                 aws.Bool(false),
+			SpotInstanceRequestIds: requestIds,
+		}
+
+		err = svc.WaitUntilSpotInstanceRequestFulfilled(describeReq)
+
+		if err != nil {
+			logrus.Errorf("wait until request full filled %v", err)
+		}
+
+		spotRequests, err := svc.DescribeSpotInstanceRequests(describeReq)
+
+		if err != nil {
+			logrus.Errorf("describe spot instance requests %v", err)
+		}
+
+		logrus.Debugf("Tag spot instance requests and spot instances")
+		for _, instance := range spotRequests.SpotInstanceRequests {
+
+			ec2Tags := []*ec2.Tag{
+				{
+					Key:   aws.String("KubernetesCluster"),
+					Value: aws.String(config.ClusterName),
+				},
+				{
+					Key:   aws.String(clouds.TagClusterID),
+					Value: aws.String(config.ClusterID),
+				},
+				{
+					Key: aws.String("Name"),
+					Value: aws.String(util.MakeNodeName(config.ClusterName,
+						uuid.New()[:4], config.IsMaster)),
+				},
+				{
+					Key:   aws.String("Role"),
+					Value: aws.String(util.MakeRole(config.IsMaster)),
+				},
+			}
+
+			tagInput := &ec2.CreateTagsInput{
+				Resources: []*string{},
+				Tags:      ec2Tags,
+			}
+
+			logrus.Infof("Tag instance %s and request id %s",
+				*instance.InstanceId, *instance.SpotInstanceRequestId)
+			tagInput.Resources = append(tagInput.Resources, instance.InstanceId)
+			tagInput.Resources = append(tagInput.Resources, instance.SpotInstanceRequestId)
+
+			_, err = svc.CreateTags(tagInput)
+
+			if err != nil {
+				logrus.Errorf("tagging spot instances %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// SpotPrices returns prices of Linux spot instances of the type for the last week
+func (Provider) SpotPrices(machineType string, config *steps.Config) ([]string, error) {
+	svc, err := amazon.GetEC2(config.AWSConfig)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "get EC2 client")
+	}
+
+	spotPriceReq := &ec2.DescribeSpotPriceHistoryInput{
+		AvailabilityZone: aws.String(config.AWSConfig.AvailabilityZone),
+		EndTime:          aws.Time(time.Now()),
+		StartTime:        aws.Time(time.Now().Add(time.Hour * -24 * 7)),
+		InstanceTypes:    []*string{aws.String(machineType)},
+	}
+
+	prices, _ := svc.DescribeSpotPriceHistory(spotPriceReq)
+	spotPrices := make([]string, 0)
+
+	for _, spotPrice := range prices.SpotPriceHistory {
+		if strings.EqualFold(*spotPrice.ProductDescription, "Linux/UNIX") {
+			spotPrices = append(spotPrices, *spotPrice.SpotPrice)
+		}
+	}
+
+	return spotPrices, nil
+}
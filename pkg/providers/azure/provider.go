@@ -0,0 +1,148 @@
+package azure
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/azure"
+)
+
+// Provider provisions kubes in Azure
+type Provider struct{}
+
+// Init registers Azure provider
+func Init() {
+	providers.Register(Provider{})
+}
+
+func (Provider) Name() clouds.Name {
+	return clouds.Azure
+}
+
+func (Provider) BindCredentials(credentials map[string]string, config *steps.Config) error {
+	return util.BindParams(credentials, &config.AzureConfig)
+}
+
+func (Provider) ValidateCredentials(creds map[string]string) error {
+	if creds == nil {
+		return util.ErrInvalidCredentials
+	}
+
+	for _, k := range []string{
+		clouds.AzureTenantID,
+		clouds.AzureSubscriptionID,
+		clouds.AzureClientID,
+		clouds.AzureClientSecret,
+	} {
+		creds[k] = strings.TrimSpace(creds[k])
+		if creds[k] == "" {
+			return errors.Wrapf(util.ErrInvalidCredentials, "azure: %s should be provided", k)
+		}
+	}
+
+	return nil
+}
+
+func (Provider) BindNodeProfile(nodeProfile profile.NodeProfile, config *steps.Config) error {
+	return util.BindParams(nodeProfile, &config.AzureConfig)
+}
+
+func (Provider) MergeConfig(source *steps.Config, destination *steps.Config) error {
+	return providers.CopySettings(&source.AzureConfig, &destination.AzureConfig)
+}
+
+func (Provider) SaveToKube(config *steps.Config, k *model.Kube) {
+	k.CloudSpec[clouds.AzureVNetCIDR] = config.AzureConfig.VNetCIDR
+	k.CloudSpec[clouds.AzureVolumeSize] = config.AzureConfig.VolumeSize
+}
+
+func (Provider) LoadFromKube(k *model.Kube, config *steps.Config) error {
+	config.AzureConfig.Location = k.Region
+	config.AzureConfig.VNetCIDR = k.CloudSpec[clouds.AzureVNetCIDR]
+	config.AzureConfig.VolumeSize = k.CloudSpec[clouds.AzureVolumeSize]
+
+	return nil
+}
+
+func (Provider) InfraSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(azure.GetAuthorizerStepName),
+		steps.GetStep(azure.CreateGroupStepName),
+		steps.GetStep(azure.CreateVNetAndSubnetsStepName),
+		steps.GetStep(azure.CreateSecurityGroupStepName),
+		steps.GetStep(azure.CreateLBStepName),
+	}
+}
+
+func (Provider) CreateMachineStep() steps.Step {
+	return steps.GetStep(azure.CreateVMStepName)
+}
+
+func (Provider) DeleteMachineStep() steps.Step {
+	return steps.GetStep(azure.DeleteVMStepName)
+}
+
+func (Provider) RegisterMachineSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) PostStartSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) DeleteClusterSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(azure.GetAuthorizerStepName),
+		steps.GetStep(azure.DeleteClusterStepName),
+	}
+}
+
+func (Provider) ImportSteps() []steps.Step {
+	return nil
+}
+
+// KubernetesCloudProvider is empty as kubernetes is run without
+// cloud provider in Azure.
+func (Provider) KubernetesCloudProvider() string {
+	return ""
+}
+
+func (Provider) RequestSpotInstances(*providers.SpotRequest, *steps.Config) error {
+	return sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) SpotPrices(string, *steps.Config) ([]string, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) RegionsGetter(acc *model.CloudAccount, config *steps.Config) (providers.RegionsGetter, error) {
+	finder, err := account.NewAzureFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+// ZonesGetter returns nothing as Azure zones are not supported yet
+func (Provider) ZonesGetter(*model.CloudAccount, *steps.Config) (providers.ZonesGetter, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) TypesGetter(acc *model.CloudAccount, config *steps.Config) (providers.TypesGetter, error) {
+	finder, err := account.NewAzureFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
@@ -0,0 +1,86 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestProvider_ValidateCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		creds       map[string]string
+		expectedErr error
+	}{
+		{
+			name:        "nil credentials map",
+			expectedErr: util.ErrInvalidCredentials,
+		},
+		{
+			name:        "empty credentials map",
+			creds:       map[string]string{},
+			expectedErr: util.ErrInvalidCredentials,
+		},
+		{
+			name: "invalid client id",
+			creds: map[string]string{
+				clouds.AzureTenantID: "1",
+			},
+			expectedErr: util.ErrInvalidCredentials,
+		},
+		{
+			name: "client credentials",
+			creds: map[string]string{
+				clouds.AzureTenantID:       "11",
+				clouds.AzureSubscriptionID: "33",
+				clouds.AzureClientID:       "22",
+				clouds.AzureClientSecret:   "clientsecret",
+			},
+		},
+	} {
+		err := Provider{}.ValidateCredentials(tc.creds)
+		if errors.Cause(err) != tc.expectedErr {
+			t.Errorf("TC: %s: result='%v', expected='%v'", tc.name, errors.Cause(err), tc.expectedErr)
+		}
+	}
+}
+
+func TestProvider_SaveLoadKube(t *testing.T) {
+	config := &steps.Config{}
+	config.AzureConfig.VNetCIDR = "10.0.0.0/16"
+	config.AzureConfig.VolumeSize = "50"
+
+	k := &model.Kube{
+		Region:    "westeurope",
+		CloudSpec: make(map[string]string),
+	}
+	Provider{}.SaveToKube(config, k)
+
+	loaded := &steps.Config{}
+	if err := (Provider{}).LoadFromKube(k, loaded); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if loaded.AzureConfig.Location != k.Region {
+		t.Errorf("wrong location expected %s actual %s", k.Region, loaded.AzureConfig.Location)
+	}
+	if loaded.AzureConfig.VNetCIDR != config.AzureConfig.VNetCIDR {
+		t.Errorf("wrong vnet cidr expected %s actual %s",
+			config.AzureConfig.VNetCIDR, loaded.AzureConfig.VNetCIDR)
+	}
+	if loaded.AzureConfig.VolumeSize != config.AzureConfig.VolumeSize {
+		t.Errorf("wrong volume size expected %s actual %s",
+			config.AzureConfig.VolumeSize, loaded.AzureConfig.VolumeSize)
+	}
+}
+
+func TestProvider_KubernetesCloudProvider(t *testing.T) {
+	if opt := (Provider{}).KubernetesCloudProvider(); opt != "" {
+		t.Errorf("Wrong cloud provider expected %q actual %q", "", opt)
+	}
+}
@@ -0,0 +1,141 @@
+package digitalocean
+
+import (
+	"context"
+
+	"github.com/digitalocean/godo"
+	"golang.org/x/oauth2"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/clouds/digitaloceansdk"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
+)
+
+// Provider provisions kubes in DigitalOcean
+type Provider struct{}
+
+// Init registers DigitalOcean provider
+func Init() {
+	providers.Register(Provider{})
+}
+
+func (Provider) Name() clouds.Name {
+	return clouds.DigitalOcean
+}
+
+func (Provider) BindCredentials(credentials map[string]string, config *steps.Config) error {
+	return util.BindParams(credentials, &config.DigitalOceanConfig)
+}
+
+func (Provider) ValidateCredentials(credentials map[string]string) error {
+	config := &steps.DOConfig{}
+	err := util.BindParams(credentials, config)
+
+	if err != nil {
+		return err
+	}
+
+	ts := &digitaloceansdk.TokenSource{
+		AccessToken: config.AccessToken,
+	}
+	oauthClient := oauth2.NewClient(oauth2.NoContext, ts)
+	client := godo.NewClient(oauthClient)
+
+	_, _, err = client.Droplets.List(context.Background(), new(godo.ListOptions))
+
+	return err
+}
+
+func (Provider) BindNodeProfile(nodeProfile profile.NodeProfile, config *steps.Config) error {
+	return util.BindParams(nodeProfile, &config.DigitalOceanConfig)
+}
+
+func (Provider) MergeConfig(source *steps.Config, destination *steps.Config) error {
+	return providers.CopySettings(&source.DigitalOceanConfig, &destination.DigitalOceanConfig)
+}
+
+func (Provider) SaveToKube(config *steps.Config, k *model.Kube) {
+	k.CloudSpec[clouds.DigitalOceanExternalLoadBalancerID] = config.DigitalOceanConfig.ExternalLoadBalancerID
+	k.CloudSpec[clouds.DigitalOceanInternalLoadBalancerID] = config.DigitalOceanConfig.InternalLoadBalancerID
+}
+
+func (Provider) LoadFromKube(k *model.Kube, config *steps.Config) error {
+	config.DigitalOceanConfig.ExternalLoadBalancerID = k.CloudSpec[clouds.DigitalOceanExternalLoadBalancerID]
+	config.DigitalOceanConfig.InternalLoadBalancerID = k.CloudSpec[clouds.DigitalOceanInternalLoadBalancerID]
+
+	return nil
+}
+
+func (Provider) InfraSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(digitalocean.CreateLoadBalancerStepName),
+	}
+}
+
+func (Provider) CreateMachineStep() steps.Step {
+	return steps.GetStep(digitalocean.CreateMachineStepName)
+}
+
+func (Provider) DeleteMachineStep() steps.Step {
+	return steps.GetStep(digitalocean.DeleteMachineStepName)
+}
+
+// RegisterMachineSteps returns nothing as DigitalOcean
+// load balancers find machines by tags.
+func (Provider) RegisterMachineSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) PostStartSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) DeleteClusterSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(digitalocean.DeleteClusterMachines),
+		steps.GetStep(digitalocean.DeleteDeleteKeysStepName),
+		steps.GetStep(digitalocean.DeleteLoadBalancerStepName),
+	}
+}
+
+func (Provider) ImportSteps() []steps.Step {
+	return nil
+}
+
+// KubernetesCloudProvider is empty as kubernetes is run without
+// cloud provider in DigitalOcean.
+func (Provider) KubernetesCloudProvider() string {
+	return ""
+}
+
+func (Provider) RequestSpotInstances(*providers.SpotRequest, *steps.Config) error {
+	return sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) SpotPrices(string, *steps.Config) ([]string, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) RegionsGetter(acc *model.CloudAccount, config *steps.Config) (providers.RegionsGetter, error) {
+	finder, err := account.NewDOFinder(acc)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+func (Provider) ZonesGetter(*model.CloudAccount, *steps.Config) (providers.ZonesGetter, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) TypesGetter(*model.CloudAccount, *steps.Config) (providers.TypesGetter, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
@@ -0,0 +1,58 @@
+package digitalocean
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestProvider_BindCredentials(t *testing.T) {
+	config := &steps.Config{}
+
+	require.NoError(t, Provider{}.BindCredentials(map[string]string{
+		"accessToken": "abcd",
+		"fingerprint": "fingerprint",
+		"publicKey":   "test-public-key",
+	}, config))
+	require.Equal(t, "abcd", config.DigitalOceanConfig.AccessToken)
+	require.Equal(t, "fingerprint", config.DigitalOceanConfig.Fingerprint)
+}
+
+func TestProvider_LoadFromKube(t *testing.T) {
+	k := &model.Kube{
+		Region: "fra1",
+		CloudSpec: map[string]string{
+			clouds.DigitalOceanExternalLoadBalancerID: "external",
+			clouds.DigitalOceanInternalLoadBalancerID: "internal",
+		},
+	}
+	config := &steps.Config{}
+
+	require.NoError(t, Provider{}.LoadFromKube(k, config))
+	require.Equal(t, "external", config.DigitalOceanConfig.ExternalLoadBalancerID)
+	require.Equal(t, "internal", config.DigitalOceanConfig.InternalLoadBalancerID)
+}
+
+func TestProvider_SaveToKube(t *testing.T) {
+	config := &steps.Config{}
+	config.DigitalOceanConfig.ExternalLoadBalancerID = "external"
+	config.DigitalOceanConfig.InternalLoadBalancerID = "internal"
+
+	k := &model.Kube{
+		CloudSpec: make(map[string]string),
+	}
+	Provider{}.SaveToKube(config, k)
+
+	require.Equal(t, "external", k.CloudSpec[clouds.DigitalOceanExternalLoadBalancerID])
+	require.Equal(t, "internal", k.CloudSpec[clouds.DigitalOceanInternalLoadBalancerID])
+}
+
+func TestProvider_KubernetesCloudProvider(t *testing.T) {
+	if opt := (Provider{}).KubernetesCloudProvider(); opt != "" {
+		t.Errorf("Wrong cloud provider expected %q actual %q", "", opt)
+	}
+}
@@ -0,0 +1,216 @@
+package gce
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2/jwt"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/dns/v1"
+
+	"github.com/supergiant/control/pkg/account"
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/gce"
+)
+
+// Provider provisions kubes in Google Compute Engine
+type Provider struct{}
+
+// Init registers GCE provider
+func Init() {
+	providers.Register(Provider{})
+}
+
+func (Provider) Name() clouds.Name {
+	return clouds.GCE
+}
+
+func (Provider) BindCredentials(credentials map[string]string, config *steps.Config) error {
+	return util.BindParams(credentials, &config.GCEConfig)
+}
+
+func (Provider) ValidateCredentials(creds map[string]string) error {
+	clientScopes := []string{
+		compute.ComputeScope,
+		compute.CloudPlatformScope,
+		dns.NdevClouddnsReadwriteScope,
+		compute.DevstorageFullControlScope,
+	}
+
+	conf := jwt.Config{
+		Email:      creds[clouds.GCEClientEmail],
+		PrivateKey: []byte(creds[clouds.GCEPrivateKey]),
+		Scopes:     clientScopes,
+		TokenURL:   creds[clouds.GCETokenURI],
+	}
+
+	client := conf.Client(context.Background())
+
+	computeService, err := compute.New(client)
+	if err != nil {
+		logrus.Errorf("Error creating compute object %v", err)
+		return err
+	}
+
+	// find the ubuntu image.
+	_, err = computeService.Images.GetFromFamily(
+		"ubuntu-os-cloud", "ubuntu-1804-lts").Do()
+	if err != nil {
+		logrus.Errorf("Error getting image %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (Provider) BindNodeProfile(nodeProfile profile.NodeProfile, config *steps.Config) error {
+	return util.BindParams(nodeProfile, &config.GCEConfig)
+}
+
+func (Provider) MergeConfig(source *steps.Config, destination *steps.Config) error {
+	return providers.CopySettings(&source.GCEConfig, &destination.GCEConfig)
+}
+
+func (Provider) SaveToKube(config *steps.Config, k *model.Kube) {
+	k.Subnets = config.GCEConfig.AZs
+	k.CloudSpec[clouds.GCETargetPoolName] = config.GCEConfig.TargetPoolName
+	k.CloudSpec[clouds.GCEHealthCheckName] = config.GCEConfig.HealthCheckName
+
+	k.CloudSpec[clouds.GCEExternalIPAddressName] = config.GCEConfig.ExternalAddressName
+	k.CloudSpec[clouds.GCEExternalIPAddress] = config.GCEConfig.ExternalIPAddressLink
+	k.CloudSpec[clouds.GCEExternalForwardingRuleName] = config.GCEConfig.ExternalForwardingRuleName
+
+	k.CloudSpec[clouds.GCEBackendServiceLink] = config.GCEConfig.BackendServiceLink
+	k.CloudSpec[clouds.GCEBackendServiceName] = config.GCEConfig.BackendServiceName
+
+	k.CloudSpec[clouds.GCEInternalIPAddress] = config.GCEConfig.InternalIPAddressLink
+	k.CloudSpec[clouds.GCEInternalIPAddressName] = config.GCEConfig.InternalAddressName
+	k.CloudSpec[clouds.GCEInternalForwardingRuleName] = config.GCEConfig.InternalForwardingRuleName
+
+	k.CloudSpec[clouds.GCENetworkName] = config.GCEConfig.NetworkName
+	k.CloudSpec[clouds.GCENetworkLink] = config.GCEConfig.NetworkLink
+
+	k.CloudSpec[clouds.GCEImageFamily] = config.GCEConfig.ImageFamily
+}
+
+func (Provider) LoadFromKube(k *model.Kube, config *steps.Config) error {
+	config.GCEConfig.Region = k.Region
+	config.GCEConfig.TargetPoolName = k.CloudSpec[clouds.GCETargetPoolName]
+	config.GCEConfig.HealthCheckName = k.CloudSpec[clouds.GCEHealthCheckName]
+
+	config.GCEConfig.BackendServiceName = k.CloudSpec[clouds.GCEBackendServiceName]
+	config.GCEConfig.BackendServiceLink = k.CloudSpec[clouds.GCEBackendServiceLink]
+
+	config.GCEConfig.ExternalAddressName = k.CloudSpec[clouds.GCEExternalIPAddressName]
+	config.GCEConfig.ExternalIPAddressLink = k.CloudSpec[clouds.GCEExternalIPAddress]
+
+	config.GCEConfig.ExternalForwardingRuleName = k.CloudSpec[clouds.GCEExternalForwardingRuleName]
+	config.GCEConfig.InternalForwardingRuleName = k.CloudSpec[clouds.GCEInternalForwardingRuleName]
+
+	config.GCEConfig.InternalIPAddressLink = k.CloudSpec[clouds.GCEInternalIPAddress]
+	config.GCEConfig.InternalAddressName = k.CloudSpec[clouds.GCEInternalIPAddressName]
+
+	config.GCEConfig.NetworkLink = k.CloudSpec[clouds.GCENetworkLink]
+	config.GCEConfig.NetworkName = k.CloudSpec[clouds.GCENetworkName]
+	config.GCEConfig.ImageFamily = k.CloudSpec[clouds.GCEImageFamily]
+
+	config.GCEConfig.AZs = k.Subnets
+
+	config.GCEConfig.InstanceGroupNames = make(map[string]string)
+
+	for az := range k.Subnets {
+		config.GCEConfig.InstanceGroupNames[az] = fmt.Sprintf("%s-%s", az, config.ClusterID)
+	}
+
+	return nil
+}
+
+func (Provider) InfraSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(gce.CreateNetworksStepName),
+		steps.GetStep(gce.CreateIPAddressStepName),
+		steps.GetStep(gce.CreateTargetPullStepName),
+		steps.GetStep(gce.CreateInstanceGroupsStepName),
+		steps.GetStep(gce.CreateHealthCheckStepName),
+		steps.GetStep(gce.CreateBackendServiceStepName),
+		steps.GetStep(gce.CreateForwardingRulesStepName),
+	}
+}
+
+func (Provider) CreateMachineStep() steps.Step {
+	return steps.GetStep(gce.CreateInstanceStepName)
+}
+
+func (Provider) DeleteMachineStep() steps.Step {
+	return steps.GetStep(gce.DeleteNodeStepName)
+}
+
+func (Provider) RegisterMachineSteps() []steps.Step {
+	return nil
+}
+
+// TODO(stgleb): Add non-bootstrap master instances to instance groups
+func (Provider) PostStartSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) DeleteClusterSteps() []steps.Step {
+	return []steps.Step{
+		steps.GetStep(gce.DeleteClusterStepName),
+		steps.GetStep(gce.DeleteForwardingRulesStepName),
+		steps.GetStep(gce.DeleteBackendServicStepName),
+		steps.GetStep(gce.DeleteTargetPoolStepName),
+		steps.GetStep(gce.DeleteInstanceGroupStepName),
+		steps.GetStep(gce.DeleteIpAddressStepName),
+	}
+}
+
+func (Provider) ImportSteps() []steps.Step {
+	return nil
+}
+
+func (Provider) KubernetesCloudProvider() string {
+	return "gce"
+}
+
+func (Provider) RequestSpotInstances(*providers.SpotRequest, *steps.Config) error {
+	return sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) SpotPrices(string, *steps.Config) ([]string, error) {
+	return nil, sgerrors.ErrUnsupportedProvider
+}
+
+func (Provider) RegionsGetter(acc *model.CloudAccount, config *steps.Config) (providers.RegionsGetter, error) {
+	finder, err := account.NewGCEFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+func (Provider) ZonesGetter(acc *model.CloudAccount, config *steps.Config) (providers.ZonesGetter, error) {
+	finder, err := account.NewGCEFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
+
+func (Provider) TypesGetter(acc *model.CloudAccount, config *steps.Config) (providers.TypesGetter, error) {
+	finder, err := account.NewGCEFinder(acc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder, nil
+}
@@ -0,0 +1,168 @@
+// Package providers holds cloud providers control plane is able to provision
+// kubes in. Every cloud is implemented by its own package that registers
+// the provider, the rest of control plane finds it by name of the cloud.
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// Provider is everything that is done differently for every cloud
+type Provider interface {
+	Name() clouds.Name
+
+	// BindCredentials puts credentials of the cloud account to config
+	BindCredentials(credentials map[string]string, config *steps.Config) error
+	// ValidateCredentials checks that cloud API accepts the credentials
+	ValidateCredentials(credentials map[string]string) error
+	// BindNodeProfile puts settings of the machine to config
+	BindNodeProfile(nodeProfile profile.NodeProfile, config *steps.Config) error
+	// MergeConfig copies cloud specific part of source config to destination
+	MergeConfig(source *steps.Config, destination *steps.Config) error
+	// SaveToKube keeps resources created by infra steps in kube, so they
+	// are known when machines are added to the kube or it is deleted.
+	SaveToKube(config *steps.Config, k *model.Kube)
+	// LoadFromKube puts resources saved by SaveToKube back to config
+	LoadFromKube(k *model.Kube, config *steps.Config) error
+
+	// InfraSteps create resources shared by all machines of a kube
+	InfraSteps() []steps.Step
+	CreateMachineStep() steps.Step
+	DeleteMachineStep() steps.Step
+	// RegisterMachineSteps add master to load balancers
+	RegisterMachineSteps() []steps.Step
+	// PostStartSteps are run once all machines of a kube are provisioned
+	PostStartSteps() []steps.Step
+	DeleteClusterSteps() []steps.Step
+	// ImportSteps describe existing kube, nil means import is not supported
+	ImportSteps() []steps.Step
+
+	// KubernetesCloudProvider is a value of cloud provider option of kubernetes
+	// components, it is empty for clouds kubernetes has no provider for.
+	KubernetesCloudProvider() string
+	// RequestSpotInstances adds spot machines to the kube, SpotPrices returns
+	// prices of spot machines of the type, sgerrors.ErrUnsupportedProvider
+	// is returned when the cloud has no spot machines.
+	RequestSpotInstances(req *SpotRequest, config *steps.Config) error
+	SpotPrices(machineType string, config *steps.Config) ([]string, error)
+
+	// RegionsGetter, ZonesGetter and TypesGetter return finders of resources
+	// available with the account, sgerrors.ErrUnsupportedProvider is returned
+	// when the cloud has nothing to find.
+	RegionsGetter(account *model.CloudAccount, config *steps.Config) (RegionsGetter, error)
+	ZonesGetter(account *model.CloudAccount, config *steps.Config) (ZonesGetter, error)
+	TypesGetter(account *model.CloudAccount, config *steps.Config) (TypesGetter, error)
+}
+
+// SpotRequest describes spot machines added to the kube
+type SpotRequest struct {
+	SpotPrice        string `json:"spotPrice"`
+	MachineType      string `json:"machineType"`
+	MachineCount     int64  `json:"machineCount"`
+	AvailabilityZone string `json:"availabilityZone"`
+}
+
+// Region is a region of the cloud along with machine types available there
+type Region struct {
+	//Human readable name, e.g. New York City 1 or EU West 1 Frankfurt
+	Name string `json:"name"`
+	//API specific ID, e.g. t2.micro
+	ID string `json:"id"`
+
+	//API specific IDs for a node sizes/type
+	AvailableSizes []string
+}
+
+type Size struct {
+	RAM string `json:"ram"`
+	CPU string `json:"cpu"`
+}
+
+// RegionSizes represents aggregated information about available regions/azs and node sizes/types
+type RegionSizes struct {
+	Provider clouds.Name            `json:"provider"`
+	Regions  []*Region              `json:"regions"`
+	Sizes    map[string]interface{} `json:"sizes"`
+}
+
+type ZonesGetter interface {
+	GetZones(context.Context, steps.Config) ([]string, error)
+}
+
+type TypesGetter interface {
+	GetTypes(context.Context, steps.Config) ([]string, error)
+}
+
+// RegionsGetter is used to find a list of available regions(availability zones, etc) with available vm types
+// in a given cloud provider using given account credentials
+type RegionsGetter interface {
+	//GetRegions returns a slice of cloud specific regions/az's
+	//if not found would return an empty slice
+	GetRegions(context.Context) (*RegionSizes, error)
+}
+
+var (
+	m         sync.RWMutex
+	providers = make(map[clouds.Name]Provider)
+)
+
+// Register makes provider available by name of its cloud,
+// provider registered before with the same name is replaced.
+func Register(p Provider) {
+	m.Lock()
+	defer m.Unlock()
+	providers[p.Name()] = p
+}
+
+// Get returns provider of the cloud, sgerrors.ErrUnknownProvider
+// is returned if the cloud has no registered provider.
+func Get(name clouds.Name) (Provider, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, errors.Wrapf(sgerrors.ErrUnknownProvider, "provider %s", name)
+	}
+
+	return p, nil
+}
+
+// List returns registered providers sorted by name
+func List() []Provider {
+	m.RLock()
+	defer m.RUnlock()
+
+	list := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, p)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+
+	return list
+}
+
+// CopySettings copies cloud settings between configs, settings are passed
+// through JSON, so fields that are not marshalled keep their values.
+func CopySettings(source interface{}, destination interface{}) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return errors.Wrap(err, "marshal settings")
+	}
+
+	return errors.Wrap(json.Unmarshal(data, destination), "unmarshal settings")
+}
@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type fakeProvider struct {
+	Provider
+	name clouds.Name
+}
+
+func (p fakeProvider) Name() clouds.Name {
+	return p.name
+}
+
+func TestRegistry(t *testing.T) {
+	Register(fakeProvider{name: "second"})
+	Register(fakeProvider{name: "first"})
+
+	p, err := Get("first")
+	require.NoError(t, err)
+	require.Equal(t, clouds.Name("first"), p.Name())
+
+	_, err = Get("unknown")
+	require.Equal(t, sgerrors.ErrUnknownProvider, errors.Cause(err))
+
+	list := List()
+	require.Len(t, list, 2)
+	require.Equal(t, clouds.Name("first"), list[0].Name())
+	require.Equal(t, clouds.Name("second"), list[1].Name())
+}
+
+func TestCopySettings(t *testing.T) {
+	source := &steps.DOConfig{
+		Region:      "fra1",
+		AccessToken: "token",
+	}
+	destination := &steps.DOConfig{
+		Size: "s-1vcpu-2gb",
+	}
+
+	require.NoError(t, CopySettings(source, destination))
+	require.Equal(t, source.Region, destination.Region)
+	require.Equal(t, source.AccessToken, destination.AccessToken)
+
+	require.Error(t, CopySettings(func() {}, destination))
+}
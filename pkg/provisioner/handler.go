@@ -38,9 +38,19 @@ type ProfileCreater interface {
 	Create(context.Context, *profile.Profile) error
 }
 
+type ProfileGetter interface {
+	Get(context.Context, string) (*profile.Profile, error)
+}
+
+type ProfileService interface {
+	ProfileCreater
+	ProfileGetter
+}
+
 type Handler struct {
 	accountGetter  AccountGetter
 	profileService ProfileCreater
+	profileGetter  ProfileGetter
 	kubeGetter     KubeGetter
 	provisioner    ClusterProvisioner
 }
@@ -62,11 +72,12 @@ type ClusterProvisioner interface {
 
 func NewHandler(kubeService KubeGetter,
 	cloudAccountService *account.Service,
-	profileSvc ProfileCreater,
+	profileSvc ProfileService,
 	provisioner ClusterProvisioner) *Handler {
 	return &Handler{
 		kubeGetter:     kubeService,
 		profileService: profileSvc,
+		profileGetter:  profileSvc,
 		accountGetter:  cloudAccountService,
 		provisioner:    provisioner,
 	}
@@ -74,56 +85,14 @@ func NewHandler(kubeService KubeGetter,
 
 func (h *Handler) Register(m *mux.Router) {
 	m.HandleFunc("/provision", h.Provision).Methods(http.MethodPost)
+	m.HandleFunc("/kubes/plan", h.PlanKube).Methods(http.MethodPost)
+	m.HandleFunc("/kubes/{kubeID}/nodes/plan", h.PlanNodes).Methods(http.MethodPost)
 }
 
 // TODO(stgleb): Move this to KubeHandler create kube
 func (h *Handler) Provision(w http.ResponseWriter, r *http.Request) {
-	req := &ProvisionRequest{}
-	err := json.NewDecoder(r.Body).Decode(req)
-
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		logrus.Error(errors.Wrap(err, "unmarshal json"))
-		return
-	}
-
-	ok, err := govalidator.ValidateStruct(req)
+	req, config, ok := h.provisionConfig(w, r)
 	if !ok {
-		logrus.Errorf("Validation error %v", err.Error())
-		message.SendValidationFailed(w, err)
-		return
-	}
-
-	if req.Profile.K8SServicesCIDR == "" {
-		req.Profile.K8SServicesCIDR = DefaultK8SServicesCIDR
-	}
-
-	config, err := steps.NewConfig(req.ClusterName, req.CloudAccountName, req.Profile)
-
-	if err != nil {
-		logrus.Errorf("New config %v", err.Error())
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	acc, err := h.accountGetter.Get(r.Context(), req.CloudAccountName)
-
-	if err != nil {
-		if sgerrors.IsNotFound(err) {
-			message.SendValidationFailed(w, fmt.Errorf("%s account not found", req.CloudAccountName))
-			return
-		}
-
-		message.SendUnknownError(w, err)
-		return
-	}
-
-	// Fill config with appropriate cloud account credentials
-	err = util.FillCloudAccountCredentials(acc, config)
-
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		logrus.Error(errors.Wrap(err, "fill cloud account"))
 		return
 	}
 
@@ -176,3 +145,150 @@ func (h *Handler) Provision(w http.ResponseWriter, r *http.Request) {
 		logrus.Error(errors.Wrap(err, "marshal json"))
 	}
 }
+
+// PlanKube takes the same request as Provision and responds with scripts
+// that provisioning would run on machines and cloud resources it would
+// create, nothing is created and no machine is contacted.
+func (h *Handler) PlanKube(w http.ResponseWriter, r *http.Request) {
+	req, config, ok := h.provisionConfig(w, r)
+	if !ok {
+		return
+	}
+
+	if len(req.Profile.MasterProfiles) == 0 {
+		message.SendValidationFailed(w, errors.New("profile has no masters"))
+		return
+	}
+
+	plan, err := PlanCluster(r.Context(), &req.Profile, config)
+	if err != nil {
+		message.SendUnknownError(w, errors.Wrap(err, "plan cluster"))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// PlanNodes takes the same node profiles as adding nodes to the kube and
+// responds with scripts that would be run on the new nodes.
+func (h *Handler) PlanNodes(w http.ResponseWriter, r *http.Request) {
+	kubeID := mux.Vars(r)["kubeID"]
+
+	nodeProfiles := make([]profile.NodeProfile, 0)
+	if err := json.NewDecoder(r.Body).Decode(&nodeProfiles); err != nil {
+		message.SendInvalidJSON(w, err)
+		return
+	}
+
+	k, err := h.kubeGetter.Get(r.Context(), kubeID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	kubeProfile, err := h.profileGetter.Get(r.Context(), k.ProfileID)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, k.ProfileID, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	config, err := steps.NewConfigFromKube(kubeProfile, k)
+	if err != nil {
+		message.SendUnknownError(w, errors.Wrap(err, "new config"))
+		return
+	}
+
+	acc, err := h.accountGetter.Get(r.Context(), k.AccountName)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, k.AccountName, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err := util.FillCloudAccountCredentials(acc, config); err != nil {
+		message.SendUnknownError(w, errors.Wrap(err, "fill cloud account"))
+		return
+	}
+
+	plan, err := PlanNodes(r.Context(), nodeProfiles, k, config)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, kubeID, err)
+			return
+		}
+		message.SendUnknownError(w, errors.Wrap(err, "plan nodes"))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		logrus.Error(errors.Wrap(err, "marshal json"))
+	}
+}
+
+// provisionConfig reads provision request and builds config of the cluster
+// filled with credentials of the cloud account, errors are sent to client.
+func (h *Handler) provisionConfig(w http.ResponseWriter, r *http.Request) (*ProvisionRequest, *steps.Config, bool) {
+	req := &ProvisionRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logrus.Error(errors.Wrap(err, "unmarshal json"))
+		return nil, nil, false
+	}
+
+	ok, err := govalidator.ValidateStruct(req)
+	if !ok {
+		logrus.Errorf("Validation error %v", err.Error())
+		message.SendValidationFailed(w, err)
+		return nil, nil, false
+	}
+
+	if req.Profile.K8SServicesCIDR == "" {
+		req.Profile.K8SServicesCIDR = DefaultK8SServicesCIDR
+	}
+
+	config, err := steps.NewConfig(req.ClusterName, req.CloudAccountName, req.Profile)
+
+	if err != nil {
+		logrus.Errorf("New config %v", err.Error())
+		message.SendUnknownError(w, err)
+		return nil, nil, false
+	}
+
+	acc, err := h.accountGetter.Get(r.Context(), req.CloudAccountName)
+
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendValidationFailed(w, fmt.Errorf("%s account not found", req.CloudAccountName))
+			return nil, nil, false
+		}
+
+		message.SendUnknownError(w, err)
+		return nil, nil, false
+	}
+
+	// Fill config with appropriate cloud account credentials
+	err = util.FillCloudAccountCredentials(acc, config)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logrus.Error(errors.Wrap(err, "fill cloud account"))
+		return nil, nil, false
+	}
+
+	return req, config, true
+}
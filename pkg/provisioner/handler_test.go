@@ -242,7 +242,7 @@ func TestHandler_Register(t *testing.T) {
 	r := mux.NewRouter()
 	h.Register(r)
 
-	expectedRouteCount := 1
+	expectedRouteCount := 3
 	actualRouteCount := 0
 	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
 		if router != r {
@@ -0,0 +1,260 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner/dry"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/provider"
+)
+
+// ErrUnplannableStep is returned for workflows with steps that are not
+// known to only run scripts, so planning can't tell what they would do.
+var ErrUnplannableStep = errors.New("step can't be planned")
+
+// Plan describes what provisioning would do without doing it,
+// scripts are rendered with the same config as provisioning uses.
+type Plan struct {
+	ClusterID   string            `json:"clusterId"`
+	ClusterName string            `json:"clusterName"`
+	Provider    string            `json:"provider"`
+	Resources   []PlannedResource `json:"resources"`
+	Scripts     []PlannedScript   `json:"scripts"`
+}
+
+// PlannedResource is a resource created by a step that planning does not run
+type PlannedResource struct {
+	Workflow    string `json:"workflow"`
+	Step        string `json:"step"`
+	Description string `json:"description,omitempty"`
+	// Machine and its profile are set for resources of a particular machine
+	Machine  string              `json:"machine,omitempty"`
+	Settings profile.NodeProfile `json:"settings,omitempty"`
+}
+
+// PlannedScript is a script that a step would run on the machine
+type PlannedScript struct {
+	Machine  string `json:"machine"`
+	Workflow string `json:"workflow"`
+	Step     string `json:"step"`
+	Script   string `json:"script"`
+}
+
+// PlanCluster renders scripts of provisioning of the cluster, keys and
+// certificates are generated for the plan only and are never used.
+func PlanCluster(ctx context.Context, clusterProfile *profile.Profile, config *steps.Config) (*Plan, error) {
+	if len(clusterProfile.MasterProfiles) == 0 {
+		return nil, errors.New("profile has no masters")
+	}
+
+	if err := util.BootstrapKeys(config); err != nil {
+		return nil, errors.Wrap(err, "bootstrap keys")
+	}
+
+	if err := bootstrapCerts(config); err != nil {
+		return nil, errors.Wrap(err, "bootstrap certs")
+	}
+
+	if config.ClusterID == "" {
+		config.ClusterID = uuid.New()[:8]
+	}
+
+	p := newPlan(config)
+	infraWorkflow := fmt.Sprintf("%s%s", config.Provider, workflows.Infra)
+	for _, step := range workflows.GetWorkflow(infraWorkflow) {
+		if step == nil {
+			continue
+		}
+		p.Resources = append(p.Resources, PlannedResource{
+			Workflow:    infraWorkflow,
+			Step:        step.Name(),
+			Description: step.Description(),
+		})
+	}
+
+	var bootstrapConfig *steps.Config
+	for i, nodeProfile := range clusterProfile.MasterProfiles {
+		cfg, err := machineConfig(config, nodeProfile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.IsMaster = true
+		cfg.IsBootstrap = i == 0
+
+		if i == 0 {
+			bootstrapConfig = cfg
+		}
+
+		machine := fmt.Sprintf("master-%d", i+1)
+		if err := p.run(ctx, workflows.ProvisionMaster, machine, nodeProfile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, nodeProfile := range clusterProfile.NodesProfiles {
+		cfg, err := machineConfig(config, nodeProfile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.IsMaster = false
+		cfg.IsBootstrap = false
+
+		machine := fmt.Sprintf("node-%d", i+1)
+		if err := p.run(ctx, workflows.ProvisionNode, machine, nodeProfile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// cluster wide steps are run on the bootstrap master
+	bootstrapConfig.IsBootstrap = false
+	if err := p.run(ctx, workflows.PostProvision, "master-1", nil, bootstrapConfig); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// PlanNodes renders scripts of provisioning of nodes added to the kube
+func PlanNodes(ctx context.Context, nodeProfiles []profile.NodeProfile, k *model.Kube, config *steps.Config) (*Plan, error) {
+	if len(k.Masters) == 0 {
+		return nil, errors.Wrap(sgerrors.ErrNotFound, "master node")
+	}
+
+	for key := range k.Masters {
+		config.AddMaster(k.Masters[key])
+	}
+
+	if err := util.LoadCloudSpecificDataFromKube(k, config); err != nil {
+		return nil, errors.Wrap(err, "load cloud specific config")
+	}
+
+	p := newPlan(config)
+	for i, nodeProfile := range nodeProfiles {
+		cfg, err := machineConfig(config, nodeProfile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.IsMaster = false
+		cfg.IsBootstrap = false
+
+		machine := fmt.Sprintf("node-%d", len(k.Nodes)+i+1)
+		if err := p.run(ctx, workflows.ProvisionNode, machine, nodeProfile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func newPlan(config *steps.Config) *Plan {
+	// addresses of load balancers are known only after they are created
+	if config.ExternalDNSName == "" {
+		config.ExternalDNSName = "{{ .ExternalDNSName }}"
+	}
+	if config.InternalDNSName == "" {
+		config.InternalDNSName = "{{ .InternalDNSName }}"
+	}
+
+	return &Plan{
+		ClusterID:   config.ClusterID,
+		ClusterName: config.ClusterName,
+		Provider:    string(config.Provider),
+		Resources:   make([]PlannedResource, 0),
+		Scripts:     make([]PlannedScript, 0),
+	}
+}
+
+// machineConfig copies config of the cluster for a machine, so that
+// changes made by steps for one machine are not seen by others.
+func machineConfig(config *steps.Config, nodeProfile profile.NodeProfile) (*steps.Config, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal config")
+	}
+
+	cfg := &steps.Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrap(err, "unmarshal config")
+	}
+
+	if err := FillNodeCloudSpecificData(config.Provider, nodeProfile, cfg); err != nil {
+		return nil, errors.Wrap(err, "fill node profile data to config")
+	}
+
+	return cfg, nil
+}
+
+// run renders scripts of the workflow steps for the machine with dry runner
+func (p *Plan) run(ctx context.Context, workflowName, machine string,
+	nodeProfile profile.NodeProfile, cfg *steps.Config) error {
+	w := workflows.GetWorkflow(workflowName)
+	if w == nil {
+		return errors.Wrapf(sgerrors.ErrNotFound, "workflow %s", workflowName)
+	}
+
+	for _, step := range w {
+		if step != nil && steps.GetPlanKind(step) == steps.PlanUnknown {
+			return errors.Wrapf(ErrUnplannableStep, "step %s of workflow %s",
+				step.Name(), workflowName)
+		}
+	}
+
+	dryRunner := dry.NewDryRunner()
+	cfg.DryRun = true
+	cfg.Runner = dryRunner
+	cfg.Node = model.Machine{
+		Name:      fmt.Sprintf("%s-%s", cfg.ClusterName, machine),
+		PublicIp:  "{{ .PublicIp }}",
+		PrivateIp: "{{ .PrivateIp }}",
+		Provider:  cfg.Provider,
+		State:     model.MachineStatePlanned,
+	}
+
+	for _, step := range w {
+		if step == nil {
+			continue
+		}
+
+		// steps calling cloud or kubernetes API are not run
+		if steps.GetPlanKind(step) == steps.PlanResource {
+			resource := PlannedResource{
+				Workflow:    workflowName,
+				Step:        step.Name(),
+				Description: step.Description(),
+				Machine:     machine,
+			}
+			if step.Name() == provider.CreateMachineStep {
+				resource.Settings = nodeProfile
+			}
+			p.Resources = append(p.Resources, resource)
+			continue
+		}
+
+		offset := len(dryRunner.GetOutput())
+		if err := step.Run(ctx, ioutil.Discard, cfg); err != nil {
+			return errors.Wrapf(err, "plan step %s of %s", step.Name(), machine)
+		}
+
+		if script := dryRunner.GetOutput()[offset:]; script != "" {
+			p.Scripts = append(p.Scripts, PlannedScript{
+				Machine:  machine,
+				Workflow: workflowName,
+				Step:     step.Name(),
+				Script:   script,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows"
+	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/provider"
+)
+
+// scriptStep renders its script with the config like template steps do
+type scriptStep struct {
+	mockStep
+	name   string
+	script *template.Template
+}
+
+func (s *scriptStep) Name() string {
+	return s.name
+}
+
+func (s *scriptStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	return steps.RunTemplate(ctx, s.script, config.Runner, out, config)
+}
+
+func (s *scriptStep) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
+
+// resourceStep calls API that must not be called by planning
+type resourceStep struct {
+	mockStep
+	name string
+}
+
+func (s *resourceStep) Name() string {
+	return s.name
+}
+
+func (s *resourceStep) Run(context.Context, io.Writer, *steps.Config) error {
+	return errors.New("resource step has been run")
+}
+
+func (s *resourceStep) PlanKind() steps.PlanKind {
+	return steps.PlanResource
+}
+
+func registerPlanWorkflows() {
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.ProvisionMaster, []steps.Step{
+		provider.StepCreateMachine{},
+		&scriptStep{
+			name:   "kubeadm",
+			script: template.Must(template.New("").Parse("kubeadm {{ .Node.Name }} bootstrap={{ .IsBootstrap }}")),
+		},
+	})
+	workflows.RegisterWorkFlow(workflows.ProvisionNode, []steps.Step{
+		provider.StepCreateMachine{},
+		&scriptStep{
+			name:   "kubelet",
+			script: template.Must(template.New("").Parse("kubelet {{ .Node.Name }} {{ .InternalDNSName }}")),
+		},
+	})
+	workflows.RegisterWorkFlow(workflows.PostProvision, []steps.Step{
+		&scriptStep{
+			name:   "tiller",
+			script: template.Must(template.New("").Parse("helm init")),
+		},
+		&resourceStep{name: "configMap"},
+	})
+	workflows.RegisterWorkFlow(workflows.DigitalOceanInfra, []steps.Step{
+		&scriptStep{name: "createLoadBalancer"},
+	})
+}
+
+func TestPlanCluster(t *testing.T) {
+	registerPlanWorkflows()
+
+	p := &profile.Profile{
+		Provider: clouds.DigitalOcean,
+		MasterProfiles: []profile.NodeProfile{
+			{"size": "s-2vcpu-4gb"},
+			{"size": "s-2vcpu-4gb"},
+		},
+		NodesProfiles: []profile.NodeProfile{
+			{"size": "s-1vcpu-2gb"},
+		},
+	}
+
+	config, err := steps.NewConfig("test", "account", *p)
+	require.NoError(t, err)
+
+	plan, err := PlanCluster(context.Background(), p, config)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, plan.ClusterID)
+	require.Equal(t, []PlannedScript{
+		{Machine: "master-1", Workflow: workflows.ProvisionMaster, Step: "kubeadm", Script: "kubeadm test-master-1 bootstrap=true"},
+		{Machine: "master-2", Workflow: workflows.ProvisionMaster, Step: "kubeadm", Script: "kubeadm test-master-2 bootstrap=false"},
+		{Machine: "node-1", Workflow: workflows.ProvisionNode, Step: "kubelet", Script: "kubelet test-node-1 {{ .InternalDNSName }}"},
+		{Machine: "master-1", Workflow: workflows.PostProvision, Step: "tiller", Script: "helm init"},
+	}, plan.Scripts)
+
+	require.Equal(t, []PlannedResource{
+		{Workflow: workflows.DigitalOceanInfra, Step: "createLoadBalancer"},
+		{Workflow: workflows.ProvisionMaster, Step: provider.CreateMachineStep, Description: provider.CreateMachineStep,
+			Machine: "master-1", Settings: p.MasterProfiles[0]},
+		{Workflow: workflows.ProvisionMaster, Step: provider.CreateMachineStep, Description: provider.CreateMachineStep,
+			Machine: "master-2", Settings: p.MasterProfiles[1]},
+		{Workflow: workflows.ProvisionNode, Step: provider.CreateMachineStep, Description: provider.CreateMachineStep,
+			Machine: "node-1", Settings: p.NodesProfiles[0]},
+		{Workflow: workflows.PostProvision, Step: "configMap", Machine: "master-1"},
+	}, plan.Resources)
+
+	// config of the cluster is not changed by steps
+	require.False(t, config.IsMaster)
+	require.Nil(t, config.Runner)
+}
+
+func TestPlanNodes(t *testing.T) {
+	registerPlanWorkflows()
+
+	k := &model.Kube{
+		ID:       "kube",
+		Name:     "test",
+		Provider: clouds.DigitalOcean,
+	}
+	config, err := steps.NewConfigFromKube(&profile.Profile{Provider: clouds.DigitalOcean}, k)
+	require.NoError(t, err)
+
+	_, err = PlanNodes(context.Background(), []profile.NodeProfile{{}}, k, config)
+	require.True(t, sgerrors.IsNotFound(err))
+
+	k.Masters = map[string]*model.Machine{
+		"master": {Name: "master", PrivateIp: "10.0.0.1"},
+	}
+	k.Nodes = map[string]*model.Machine{
+		"node": {Name: "node"},
+	}
+	k.ExternalDNSName = "external"
+	k.InternalDNSName = "internal"
+
+	config, err = steps.NewConfigFromKube(&profile.Profile{Provider: clouds.DigitalOcean}, k)
+	require.NoError(t, err)
+
+	plan, err := PlanNodes(context.Background(), []profile.NodeProfile{{}}, k, config)
+	require.NoError(t, err)
+	require.Equal(t, []PlannedScript{
+		{Machine: "node-2", Workflow: workflows.ProvisionNode, Step: "kubelet", Script: "kubelet test-node-2 internal"},
+	}, plan.Scripts)
+}
+
+func TestPlanUnplannableStep(t *testing.T) {
+	registerPlanWorkflows()
+	workflows.RegisterWorkFlow(workflows.ProvisionNode, []steps.Step{
+		&scriptStep{
+			name:   "kubelet",
+			script: template.Must(template.New("").Parse("kubelet")),
+		},
+		// a step that does not tell what it does may call any API
+		&mockStep{},
+	})
+
+	k := &model.Kube{
+		Name:     "test",
+		Provider: clouds.DigitalOcean,
+		Masters: map[string]*model.Machine{
+			"master": {Name: "master"},
+		},
+	}
+	config, err := steps.NewConfigFromKube(&profile.Profile{Provider: clouds.DigitalOcean}, k)
+	require.NoError(t, err)
+
+	_, err = PlanNodes(context.Background(), []profile.NodeProfile{{}}, k, config)
+	require.Equal(t, ErrUnplannableStep, errors.Cause(err))
+}
+
+func TestHandler_PlanKube(t *testing.T) {
+	registerPlanWorkflows()
+
+	testCases := []struct {
+		description  string
+		profile      profile.Profile
+		expectedCode int
+	}{
+		{
+			description: "no masters",
+			profile: profile.Profile{
+				Provider: clouds.DigitalOcean,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description: "success",
+			profile: profile.Profile{
+				Provider: clouds.DigitalOcean,
+				MasterProfiles: []profile.NodeProfile{
+					{"size": "s-2vcpu-4gb"},
+				},
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		body, err := json.Marshal(&ProvisionRequest{
+			ClusterName:      "test",
+			Profile:          testCase.profile,
+			CloudAccountName: "account",
+		})
+		require.NoError(t, err)
+
+		h := Handler{
+			accountGetter: &mockAccountGetter{
+				get: func(context.Context, string) (*model.CloudAccount, error) {
+					return &model.CloudAccount{
+						Provider: clouds.DigitalOcean,
+					}, nil
+				},
+			},
+		}
+
+		router := mux.NewRouter()
+		h.Register(router)
+
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/kubes/plan", bytes.NewReader(body))
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, testCase.expectedCode, rec.Code)
+		if testCase.expectedCode != http.StatusOK {
+			continue
+		}
+
+		plan := &Plan{}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(plan))
+		require.Equal(t, "test", plan.ClusterName)
+		require.Len(t, plan.Scripts, 2)
+	}
+}
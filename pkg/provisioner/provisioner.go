@@ -29,6 +29,7 @@ import (
 type KubeService interface {
 	Create(ctx context.Context, k *model.Kube) error
 	Get(ctx context.Context, name string) (*model.Kube, error)
+	Update(ctx context.Context, name string, fn func(k *model.Kube) error) error
 }
 
 type TaskProvisioner struct {
@@ -187,6 +188,48 @@ func (tp *TaskProvisioner) ProvisionNodes(parentContext context.Context, nodePro
 	return tasks, nil
 }
 
+// RestartNodesProvisioning resumes tasks of nodes being added to working
+// cluster from the last successful step.
+func (tp *TaskProvisioner) RestartNodesProvisioning(parentContext context.Context,
+	config *steps.Config, taskIDs []string) error {
+	taskMap, err := tp.deserializeClusterTasks(parentContext, config,
+		map[string][]string{workflows.NodeTask: taskIDs})
+
+	if err != nil {
+		return errors.Wrap(err, "restart nodes provisioning")
+	}
+
+	ctx, cancel := context.WithCancel(parentContext)
+	tp.cancelMap[config.ClusterID] = cancel
+
+	go tp.monitorClusterState(ctx, config.ClusterID,
+		config.NodeChan(), config.KubeStateChan(), config.ConfigChan())
+
+	for _, t := range taskMap[workflows.NodeTask] {
+		writer, err := tp.getWriter(util.MakeFileName(t.ID))
+
+		if err != nil {
+			return errors.Wrap(err, "get writer")
+		}
+
+		t.Config.IsMaster = false
+		t.Config.IsBootstrap = false
+		errChan := t.Run(ctx, *t.Config, writer)
+
+		go func(task *workflows.Task, errChan chan error) {
+			if err := <-errChan; err != nil {
+				task.Config.Node.State = model.MachineStateError
+				task.Config.AddNode(&task.Config.Node)
+				task.Config.NodeChan() <- task.Config.Node
+				logrus.Errorf("add node to cluster %s caused an error %v",
+					config.ClusterID, err)
+			}
+		}(t, errChan)
+	}
+
+	return nil
+}
+
 func (tp *TaskProvisioner) Cancel(clusterID string) error {
 	if cancelFunc := tp.cancelMap[clusterID]; cancelFunc != nil {
 		cancelFunc()
@@ -802,38 +845,27 @@ func (tp *TaskProvisioner) monitorClusterState(ctx context.Context,
 	for {
 		select {
 		case n := <-nodeChan:
-			k, err := tp.kubeService.Get(ctx, clusterID)
+			err := tp.kubeService.Update(ctx, clusterID, func(k *model.Kube) error {
+				if n.Role == model.RoleMaster {
+					k.Masters[n.Name] = &n
+				} else {
+					k.Nodes[n.Name] = &n
+				}
 
-			if err != nil {
-				logrus.Errorf("cluster monitor: update kube state caused %v", err)
-				continue
-			}
-
-			if n.Role == model.RoleMaster {
-				k.Masters[n.Name] = &n
-			} else {
-				k.Nodes[n.Name] = &n
-			}
-
-			err = tp.kubeService.Create(ctx, k)
+				return nil
+			})
 
 			if err != nil {
 				logrus.Errorf("cluster monitor: update kube state caused %v", err)
 				continue
 			}
 		case state := <-kubeStateChan:
-			logrus.Debugf("monitor: get kube %s", clusterID)
-			k, err := tp.kubeService.Get(ctx, clusterID)
-
-			if err != nil {
-				logrus.Errorf("cluster monitor: update kube state caused %v", err)
-				continue
-			}
-
-			k.State = state
 			logrus.Debugf("monitor: update kube %s with state %s",
-				k.ID, state)
-			err = tp.kubeService.Create(ctx, k)
+				clusterID, state)
+			err := tp.kubeService.Update(ctx, clusterID, func(k *model.Kube) error {
+				k.State = state
+				return nil
+			})
 
 			if err != nil {
 				logrus.Errorf("cluster monitor: update kube state caused %v", err)
@@ -841,17 +873,10 @@ func (tp *TaskProvisioner) monitorClusterState(ctx context.Context,
 			}
 		case config := <-configChan:
 			logrus.Debugf("update kube %s with config", clusterID)
-			k, err := tp.kubeService.Get(ctx, clusterID)
-
-			if err != nil {
-				logrus.Errorf("cluster monitor: update kube state caused %v", err)
-				continue
-			}
-
-			logrus.Debugf("update kube %s with config", k.ID)
-			util.UpdateKubeWithCloudSpecificData(k, config)
-
-			err = tp.kubeService.Create(ctx, k)
+			err := tp.kubeService.Update(ctx, clusterID, func(k *model.Kube) error {
+				util.UpdateKubeWithCloudSpecificData(k, config)
+				return nil
+			})
 
 			if err != nil {
 				logrus.Errorf("cluster monitor: update kube state caused %v", err)
@@ -868,18 +893,11 @@ func (tp *TaskProvisioner) deserializeClusterTasks(ctx context.Context, kubeConf
 
 	for taskSet, tasks := range taskIdMap {
 		for _, taskId := range tasks {
-			data, err := tp.repository.Get(ctx, workflows.Prefix, taskId)
-
-			if err != nil {
-				logrus.Debugf("error getting task %s %v", taskId, err)
-				return nil, errors.Wrapf(err, "task id %s not found %b", taskId, err)
-			}
-
-			task, err := workflows.DeserializeTask(data, tp.repository)
+			task, err := workflows.LoadTask(ctx, taskId, tp.repository)
 
 			if err != nil {
-				logrus.Debugf("error deserializing task %s %v", taskId, err)
-				return nil, errors.Wrapf(err, "error deserializing task %s %v", taskId, err)
+				logrus.Debugf("error loading task %s %v", taskId, err)
+				return nil, errors.Wrapf(err, "load task %s", taskId)
 			}
 
 			err = MergeConfig(kubeConfig, task.Config)
@@ -15,12 +15,19 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	awsprovider "github.com/supergiant/control/pkg/providers/aws"
+	doprovider "github.com/supergiant/control/pkg/providers/digitalocean"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
+func init() {
+	awsprovider.Init()
+	doprovider.Init()
+}
+
 type mockKubeService struct {
 	getError  error
 	createErr error
@@ -41,6 +48,26 @@ func (m *mockKubeService) Get(ctx context.Context, kname string) (*model.Kube, e
 	return m.data[kname], m.getError
 }
 
+func (m *mockKubeService) Update(ctx context.Context, kname string, fn func(*model.Kube) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.getError != nil {
+		return m.getError
+	}
+
+	k, ok := m.data[kname]
+	if !ok {
+		return sgerrors.ErrNotFound
+	}
+
+	if err := fn(k); err != nil {
+		return err
+	}
+
+	return m.createErr
+}
+
 type mockStep struct {
 }
 
@@ -91,6 +118,9 @@ func TestProvisionCluster(t *testing.T) {
 	repository.On("Put", mock.Anything,
 		mock.Anything, mock.Anything,
 		mock.Anything).Return(nil)
+	repository.On("PutIfRevision", mock.Anything,
+		mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(1, nil)
 
 	bc := &bufferCloser{
 		ioutil.Discard,
@@ -176,6 +206,9 @@ func TestProvisionNodes(t *testing.T) {
 	repository.On("Put", mock.Anything,
 		mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
+	repository.On("PutIfRevision", mock.Anything,
+		mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(1, nil)
 	repository.On("Get", mock.Anything, mock.Anything,
 		mock.Anything).Return()
 	bc := &bufferCloser{
@@ -274,17 +307,20 @@ func TestRestartProvisionClusterSuccess(t *testing.T) {
 	repository.On("Put", mock.Anything,
 		mock.Anything, mock.Anything,
 		mock.Anything).Return(nil)
-	repository.On("Get", mock.Anything,
+	repository.On("PutIfRevision", mock.Anything,
+		mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(1, nil)
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything,
 		mock.Anything).Return([]byte(`{"id": "task_id", 
 		"type": "PreProvision", "stepsStatuses":[{"status": "error"}], "config": {}}`),
-		nil).Once()
+		1, nil).Once()
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything,
 		mock.Anything).Return([]byte(`{"id": "task_id", 
 		"type": "ProvisionMaster", "stepsStatuses":[{"status": "error"}] "config": {}}`),
-		nil).Once()
+		1, nil).Once()
 
 	bc := &bufferCloser{
 		ioutil.Discard,
@@ -354,10 +390,13 @@ func TestRestartProvisionClusterError(t *testing.T) {
 	repository.On("Put", mock.Anything,
 		mock.Anything, mock.Anything,
 		mock.Anything).Return(nil)
-	repository.On("Get", mock.Anything,
+	repository.On("PutIfRevision", mock.Anything,
+		mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(1, nil)
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything,
 		mock.Anything).Return([]byte(`}`),
-		nil)
+		1, nil)
 
 	bc := &bufferCloser{
 		ioutil.Discard,
@@ -425,29 +464,106 @@ func TestRestartProvisionClusterError(t *testing.T) {
 	}
 }
 
+func TestRestartNodesProvisioning(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		expectedErr bool
+	}{
+		{
+			description: "success",
+			data: `{"id": "node_task", "type": "ProvisionNode",
+			"stepsStatuses":[{"status": "error"}], "config": {"provider": "aws"}}`,
+		},
+		{
+			description: "load task error",
+			data:        `}`,
+			expectedErr: true,
+		},
+	}
+
+	workflows.Init()
+	workflows.RegisterWorkFlow(workflows.ProvisionNode, []steps.Step{
+		&mockStep{},
+	})
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		repository := &testutils.MockStorage{}
+		repository.On("PutIfRevision", mock.Anything,
+			mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything).Return(1, nil)
+		repository.On("GetWithRevision", mock.Anything,
+			mock.Anything, mock.Anything).
+			Return([]byte(testCase.data), 1, nil)
+
+		provisioner := TaskProvisioner{
+			&mockKubeService{
+				data: map[string]*model.Kube{
+					"kubeID": {ID: "kubeID"},
+				},
+			},
+			repository,
+			func(string) (io.WriteCloser, error) {
+				return &bufferCloser{ioutil.Discard, nil}, nil
+			},
+			NewRateLimiter(time.Nanosecond * 1),
+			make(map[string]func()),
+		}
+
+		cfg, err := steps.NewConfig("kube_name", "", profile.Profile{
+			Provider: clouds.AWS,
+		})
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+		cfg.ClusterID = "kubeID"
+
+		err = provisioner.RestartNodesProvisioning(context.Background(),
+			cfg, []string{"node_task"})
+		time.Sleep(time.Millisecond * 10)
+
+		if testCase.expectedErr {
+			if err == nil {
+				t.Errorf("Error must not be nil")
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		if _, ok := provisioner.cancelMap["kubeID"]; !ok {
+			t.Errorf("cancel func for kube kubeID not found")
+		}
+	}
+}
+
 func TestDeserializeTasks(t *testing.T) {
 	repository := &testutils.MockStorage{}
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything, mock.Anything).Return(
 		[]byte(`{"id": "1234", "type": "preprovision", "config": {"provider": "aws"}}`),
-		nil)
+		1, nil)
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything, mock.Anything).Return(
 		[]byte(
 			`{"id": "4567", "type": "master", "config": {"provider": "aws"}"}`),
-		nil)
+		1, nil)
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything, mock.Anything).Return(
 		[]byte(`{"id": "9876", "type": "node", "config": {"provider": "aws"}}`),
-		nil)
+		1, nil)
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything, mock.Anything).Return(
 		[]byte(`{"id": "abcd", "type": "cluster", "config": {"provider": "aws"}}`),
-		nil)
+		1, nil)
 
 	provisioner := TaskProvisioner{
 		repository: repository,
@@ -479,9 +595,9 @@ func TestDeserializeTasks(t *testing.T) {
 func TestDeserializeTasksError(t *testing.T) {
 	repository := &testutils.MockStorage{}
 
-	repository.On("Get", mock.Anything,
+	repository.On("GetWithRevision", mock.Anything,
 		mock.Anything, mock.Anything).Return(
-		nil,
+		nil, 0,
 		sgerrors.ErrNotFound)
 
 	provisioner := TaskProvisioner{
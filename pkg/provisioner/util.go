@@ -1,15 +1,16 @@
 package provisioner
 
 import (
-	"encoding/json"
-	"github.com/pkg/errors"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows"
@@ -38,84 +39,24 @@ func FillNodeCloudSpecificData(provider clouds.Name, nodeProfile profile.NodePro
 		config.IsMaster, _ = strconv.ParseBool(nodeProfile["isMaster"])
 	}
 
-	switch provider {
-	case clouds.AWS:
-		return util.BindParams(nodeProfile, &config.AWSConfig)
-	case clouds.GCE:
-		return util.BindParams(nodeProfile, &config.GCEConfig)
-	case clouds.DigitalOcean:
-		return util.BindParams(nodeProfile, &config.DigitalOceanConfig)
-	case clouds.Packet:
-		return util.BindParams(nodeProfile, &config.PacketConfig)
-	case clouds.OpenStack:
-		return util.BindParams(nodeProfile, &config.OSConfig)
-	case clouds.Azure:
-		return util.BindParams(nodeProfile, &config.AzureConfig)
-	default:
+	p, err := providers.Get(provider)
+	if err != nil {
 		return sgerrors.ErrUnknownProvider
 	}
 
-	return nil
+	return p.BindNodeProfile(nodeProfile, config)
 }
 
 func MergeConfig(source *steps.Config, destination *steps.Config) error {
-	switch source.Provider {
-	case clouds.AWS:
-		data, err := json.Marshal(&source.AWSConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "merge config marshall config1")
-		}
-
-		err = json.Unmarshal(data, &destination.AWSConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "Merge config")
-		}
-	case clouds.GCE:
-		data, err := json.Marshal(&source.GCEConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "merge config marshall config1")
-		}
-
-		err = json.Unmarshal(data, &destination.GCEConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "Merge config")
-		}
-	case clouds.DigitalOcean:
-		data, err := json.Marshal(&source.DigitalOceanConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "merge config marshall config1")
-		}
-
-		err = json.Unmarshal(data, &destination.DigitalOceanConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "Merge config")
-		}
-	case clouds.Packet:
-		return nil
-	case clouds.OpenStack:
-		return nil
-	case clouds.Azure:
-		data, err := json.Marshal(&source.AzureConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "merge config marshall config1")
-		}
-
-		err = json.Unmarshal(data, &destination.AzureConfig)
-
-		if err != nil {
-			return errors.Wrapf(err, "Merge config")
-		}
-	default:
+	p, err := providers.Get(source.Provider)
+	if err != nil {
 		return sgerrors.ErrUnknownProvider
 	}
 
+	if err := p.MergeConfig(source, destination); err != nil {
+		return errors.Wrapf(err, "Merge config")
+	}
+
 	// These items must be shared among configs
 	destination.ExternalDNSName = source.ExternalDNSName
 	destination.InternalDNSName = source.InternalDNSName
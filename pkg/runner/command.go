@@ -10,6 +10,10 @@ import (
 var (
 	ErrNilContext = errors.New("nil context")
 	ErrNilWriter  = errors.New("writer is nil")
+	ErrNilReader  = errors.New("reader is nil")
+	ErrEmptyPath  = errors.New("empty path")
+	// ErrNoTransfer is returned when a runner does not implement FileTransfer
+	ErrNoTransfer = errors.New("runner can't transfer files")
 )
 
 // Command is an action that can be run and cancelled on different environments ssh, shell, docker etc.
@@ -2,6 +2,11 @@ package dry
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/supergiant/control/pkg/runner"
 )
 
@@ -22,6 +27,38 @@ func (r *DryRunner) Run(cmd *runner.Command) (err error) {
 	return nil
 }
 
+// Upload writes commands that set mode and owner of the file like ssh runner
+// does, content is replaced with a comment to keep secrets out of plans.
+func (r *DryRunner) Upload(ctx context.Context, file runner.File, content io.Reader, size int64) error {
+	if strings.TrimSpace(file.Path) == "" {
+		return runner.ErrEmptyPath
+	}
+	if content == nil {
+		return runner.ErrNilReader
+	}
+
+	mode := file.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	fmt.Fprintf(r.output, "# upload %d bytes to '%s'\n", size, file.Path)
+	fmt.Fprintf(r.output, "sudo chmod %04o '%s'\n", mode.Perm(), file.Path)
+	if file.Owner != "" {
+		fmt.Fprintf(r.output, "sudo chown '%s' '%s'\n", file.Owner, file.Path)
+	}
+
+	return nil
+}
+
+// Download does nothing as there is no host to get the file from.
+func (r *DryRunner) Download(ctx context.Context, path string, w io.Writer) error {
+	if strings.TrimSpace(path) == "" {
+		return runner.ErrEmptyPath
+	}
+	return nil
+}
+
 func (r *DryRunner) GetOutput() string {
 	return r.output.String()
 }
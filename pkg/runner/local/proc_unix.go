@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package local
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(c *exec.Cmd) {
+	// negative pid sends the signal to every process of the group
+	if err := syscall.Kill(-c.Process.Pid, syscall.SIGKILL); err != nil {
+		c.Process.Kill()
+	}
+}
@@ -0,0 +1,12 @@
+package local
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing as windows has no process groups
+func setProcessGroup(*exec.Cmd) {}
+
+func killProcessGroup(c *exec.Cmd) {
+	c.Process.Kill()
+}
@@ -0,0 +1,91 @@
+package local
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+const (
+	DefaultShell = "/bin/bash"
+)
+
+// Config is a set of params of the shell commands are run with
+type Config struct {
+	// Shell is run with -c and a script of the command
+	Shell string `json:"shell"`
+	// Dir is a working directory of commands, current directory is used if empty
+	Dir string `json:"dir"`
+	// Env is added to environment of control plane, e.g. KUBECONFIG=/etc/kubeconfig
+	Env []string `json:"env"`
+}
+
+// Runner is implementation of runner interface that runs commands on control plane host
+type Runner struct {
+	shell string
+	dir   string
+	env   []string
+}
+
+// NewRunner creates local runner, shell of the config must be found
+// on control plane host.
+func NewRunner(config Config) (runner.Runner, error) {
+	shell := config.Shell
+	if shell == "" {
+		shell = DefaultShell
+	}
+
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		return nil, errors.Wrapf(err, "local: find shell %s", shell)
+	}
+
+	return &Runner{
+		shell: path,
+		dir:   config.Dir,
+		env:   append(os.Environ(), config.Env...),
+	}, nil
+}
+
+// Run executes script of the command in a new process group. Output of the
+// script is streamed to writers of the command while it runs, the whole group
+// is killed once context of the command is done.
+func (r *Runner) Run(cmd *runner.Command) error {
+	if cmd == nil || strings.TrimSpace(cmd.Script) == "" {
+		return nil
+	}
+
+	if cmd.Ctx == nil {
+		return runner.ErrNilContext
+	}
+
+	c := exec.Command(r.shell, "-c", cmd.Script)
+	c.Dir = r.dir
+	c.Env = r.env
+	c.Stdout = cmd.Out
+	c.Stderr = cmd.Err
+	setProcessGroup(c)
+
+	if err := c.Start(); err != nil {
+		return errors.Wrap(err, "local: start command")
+	}
+
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- c.Wait()
+	}()
+
+	select {
+	case <-cmd.Ctx.Done():
+		// children of the script would keep writing to the output otherwise
+		killProcessGroup(c)
+		<-waitCh
+		return errors.Wrap(cmd.Ctx.Err(), "local: run command")
+	case err := <-waitCh:
+		return err
+	}
+}
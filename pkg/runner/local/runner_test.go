@@ -0,0 +1,94 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+func TestNewRunner(t *testing.T) {
+	r, err := NewRunner(Config{})
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	_, err = NewRunner(Config{Shell: "/not/a/shell"})
+	require.Error(t, err)
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-runner")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "kubeconfig"), []byte("config"), 0600))
+
+	r, err := NewRunner(Config{
+		Dir: dir,
+		Env: []string{"KUBECONFIG=kubeconfig"},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		script      string
+		out         string
+		errOut      string
+		hasErr      bool
+	}{
+		{
+			description: "empty script",
+		},
+		{
+			description: "output",
+			script:      "cat $KUBECONFIG\necho error >&2",
+			out:         "config",
+			errOut:      "error\n",
+		},
+		{
+			description: "exit code",
+			script:      "exit 3",
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd, err := runner.NewCommand(context.Background(), testCase.script, out, errOut)
+		require.NoError(t, err)
+
+		err = r.Run(cmd)
+		require.Equal(t, testCase.hasErr, err != nil, "TC: %s: %v", testCase.description, err)
+		require.Equal(t, testCase.out, out.String(), testCase.description)
+		require.Equal(t, testCase.errOut, errOut.String(), testCase.description)
+	}
+
+	require.NoError(t, r.Run(nil))
+	require.Equal(t, runner.ErrNilContext, r.Run(&runner.Command{Script: "true"}))
+}
+
+func TestRunner_RunCancel(t *testing.T) {
+	r, err := NewRunner(Config{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	// sleep runs in the background, so it is killed only with the group
+	cmd, err := runner.NewCommand(ctx, "sleep 10 & wait", out, out)
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = r.Run(cmd)
+	require.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	require.True(t, time.Since(start) < time.Second*5, "command was not killed")
+}
@@ -1,6 +1,31 @@
 package runner
 
+import (
+	"context"
+	"io"
+	"os"
+)
+
 // Runner is interface for running command in different environment
 type Runner interface {
 	Run(command *Command) error
 }
+
+// File describes a file on the environment a runner works with.
+type File struct {
+	Path string
+	// Mode is applied to uploaded file, 0644 is used when it is not set.
+	Mode os.FileMode
+	// Owner is user or user:group the uploaded file is owned by, it is
+	// left as is when empty.
+	Owner string
+}
+
+// FileTransfer is implemented by runners that can copy files to and from the
+// environment without embedding their content into commands.
+type FileTransfer interface {
+	// Upload writes size bytes of content to the file.
+	Upload(ctx context.Context, file File, content io.Reader, size int64) error
+	// Download writes content of the file at path to w.
+	Download(ctx context.Context, path string, w io.Writer) error
+}
@@ -0,0 +1,202 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultKeepAlive   = time.Second * 30
+	DefaultIdleTimeout = time.Minute * 5
+
+	keepAliveRequest = "keepalive@openssh.com"
+)
+
+// defaultPool is shared by all runners, so steps of a task and tasks
+// working with the same host run their commands over a single connection.
+var defaultPool = newPool(DefaultKeepAlive, DefaultIdleTimeout)
+
+// chain is a connection to a host through its bastions
+type chain struct {
+	key      string
+	clients  []*ssh.Client
+	hostKeys []ssh.PublicKey
+
+	refs     int
+	lastUsed time.Time
+	// evicted connection is closed once it is not used anymore
+	evicted bool
+}
+
+// client returns client of the host sessions are opened on
+func (c *chain) client() *ssh.Client {
+	return c.clients[len(c.clients)-1]
+}
+
+// verify checks the hosts of the chain present keys expected by hops, the
+// connection may have been established by a runner with other expectations.
+func (c *chain) verify(hops []hop) error {
+	for i, h := range hops {
+		if err := h.sshConf.HostKeyCallback(h.addr, c.clients[i].RemoteAddr(), c.hostKeys[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *chain) close() {
+	closeAll(c.clients)
+}
+
+// pool keeps connections to hosts open between commands, idle connections
+// are closed and broken ones are evicted to be dialed again on next use.
+type pool struct {
+	keepAlive   time.Duration
+	idleTimeout time.Duration
+
+	m     sync.Mutex
+	conns map[string]*chain
+}
+
+func newPool(keepAlive, idleTimeout time.Duration) *pool {
+	return &pool{
+		keepAlive:   keepAlive,
+		idleTimeout: idleTimeout,
+		conns:       make(map[string]*chain),
+	}
+}
+
+// hopID identifies a hop by address, user and key it is accessed with
+func hopID(addr string, config Config) string {
+	sum := sha256.Sum256(config.Key)
+	return fmt.Sprintf("%s@%s/%x", config.User, addr, sum[:8])
+}
+
+// poolKey identifies connection by all hops it goes through
+func poolKey(hops []hop) string {
+	ids := make([]string, 0, len(hops))
+	for _, h := range hops {
+		ids = append(ids, h.id)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// get returns connection for the key, it is dialed when there is no one.
+// Connection must be released with put when it is not used anymore.
+func (p *pool) get(ctx context.Context, hops []hop) (*chain, error) {
+	key := poolKey(hops)
+
+	p.m.Lock()
+	if c := p.conns[key]; c != nil {
+		c.refs++
+		p.m.Unlock()
+		return c, nil
+	}
+	p.m.Unlock()
+
+	c, err := connectionWithBackOff(ctx, hops, time.Second*10, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	// the same host could have been dialed concurrently
+	if existing := p.conns[key]; existing != nil {
+		existing.refs++
+		c.close()
+		return existing, nil
+	}
+
+	c.key = key
+	c.refs = 1
+	p.conns[key] = c
+	go p.keepAliveLoop(c)
+
+	return c, nil
+}
+
+// put releases connection, evicted connection is closed by the last user
+func (p *pool) put(c *chain) {
+	p.m.Lock()
+	c.refs--
+	c.lastUsed = time.Now()
+	unused := c.evicted && c.refs == 0
+	p.m.Unlock()
+
+	if unused {
+		c.close()
+	}
+}
+
+// evict removes connection from the pool, so that it is dialed again on next
+// use. Commands running over the connection are not affected, it is closed
+// once all of them are done.
+func (p *pool) evict(c *chain) {
+	p.m.Lock()
+	if p.conns[c.key] == c {
+		delete(p.conns, c.key)
+	}
+	unused := !c.evicted && c.refs == 0
+	c.evicted = true
+	p.m.Unlock()
+
+	if unused {
+		c.close()
+	}
+}
+
+// keepAliveLoop pings the host to keep connection open and detect it is
+// broken, connection is closed once it has not been used for idle timeout.
+func (p *pool) keepAliveLoop(c *chain) {
+	ticker := time.NewTicker(p.keepAlive)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.m.Lock()
+		if p.conns[c.key] != c {
+			p.m.Unlock()
+			return
+		}
+		idle := c.refs == 0 && time.Since(c.lastUsed) > p.idleTimeout
+		p.m.Unlock()
+
+		if idle {
+			logrus.Debugf("ssh: close idle connection %s", c.key)
+			p.evict(c)
+			return
+		}
+
+		if err := p.ping(c); err != nil {
+			logrus.Debugf("ssh: evict connection %s: %v", c.key, err)
+			p.evict(c)
+			return
+		}
+	}
+}
+
+func (p *pool) ping(c *chain) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := c.client().SendRequest(keepAliveRequest, true, nil)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(p.keepAlive):
+		return errors.New("keepalive timed out")
+	}
+}
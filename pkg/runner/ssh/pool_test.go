@@ -0,0 +1,133 @@
+package ssh
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+func newPooledRunner(t *testing.T, s *testServer, key []byte, p *pool) *Runner {
+	r, err := NewRunner(Config{
+		Host:    s.Host(),
+		Port:    s.Port(),
+		User:    "root",
+		Key:     key,
+		Timeout: 5,
+	})
+	require.NoError(t, err)
+
+	sshRunner := r.(*Runner)
+	sshRunner.pool = p
+	return sshRunner
+}
+
+func run(t *testing.T, r runner.Runner, script string) error {
+	cmd, err := runner.NewCommand(context.Background(), script, ioutil.Discard, ioutil.Discard)
+	require.NoError(t, err)
+	return r.Run(cmd)
+}
+
+func TestRunner_RunReusesConnection(t *testing.T) {
+	key := newPrivateKey(t)
+	host := newTestServer(t, "root", key)
+	defer host.Close()
+
+	p := newPool(DefaultKeepAlive, DefaultIdleTimeout)
+	// runners of different steps share connection
+	for _, script := range []string{"hostname", "uptime", "whoami"} {
+		require.NoError(t, run(t, newPooledRunner(t, host, key, p), script))
+	}
+
+	require.Equal(t, 1, host.Connections())
+	require.Equal(t, []string{"hostname", "uptime", "whoami"}, host.Commands())
+}
+
+func TestRunner_RunReconnect(t *testing.T) {
+	key := newPrivateKey(t)
+	host := newTestServer(t, "root", key)
+	defer host.Close()
+
+	r := newPooledRunner(t, host, key, newPool(DefaultKeepAlive, DefaultIdleTimeout))
+	require.NoError(t, run(t, r, "hostname"))
+
+	host.DropConnections()
+	require.NoError(t, run(t, r, "uptime"))
+
+	require.Equal(t, 2, host.Connections())
+	require.Equal(t, []string{"hostname", "uptime"}, host.Commands())
+}
+
+func TestPool_Eviction(t *testing.T) {
+	key := newPrivateKey(t)
+
+	for _, tc := range []struct {
+		name  string
+		setup func(*testServer)
+	}{
+		{
+			name: "idle",
+		},
+		{
+			name: "broken",
+			setup: func(s *testServer) {
+				s.DropConnections()
+			},
+		},
+	} {
+		host := newTestServer(t, "root", key)
+		p := newPool(time.Millisecond*10, time.Millisecond*20)
+
+		require.NoError(t, run(t, newPooledRunner(t, host, key, p), "hostname"), tc.name)
+		if tc.setup != nil {
+			tc.setup(host)
+		}
+
+		deadline := time.Now().Add(time.Second * 5)
+		for {
+			p.m.Lock()
+			count := len(p.conns)
+			p.m.Unlock()
+
+			if count == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: connection has not been evicted", tc.name)
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		host.Close()
+	}
+}
+
+func TestPool_EvictInUse(t *testing.T) {
+	key := newPrivateKey(t)
+	host := newTestServer(t, "root", key)
+	defer host.Close()
+
+	p := newPool(DefaultKeepAlive, DefaultIdleTimeout)
+	r := newPooledRunner(t, host, key, p)
+
+	c, err := p.get(context.Background(), r.hops())
+	require.NoError(t, err)
+
+	session, err := c.client().NewSession()
+	require.NoError(t, err)
+	p.evict(c)
+
+	// command running over evicted connection goes on
+	require.NoError(t, session.Run("hostname"))
+
+	// while new commands use a new connection
+	require.NoError(t, run(t, r, "uptime"))
+	require.Equal(t, 2, host.Connections())
+
+	p.put(c)
+	_, err = c.client().NewSession()
+	require.Error(t, err, "evicted connection must be closed by its last user")
+}
@@ -2,12 +2,14 @@ package ssh
 
 import (
 	"context"
+	"net"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 
+	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
 )
 
@@ -22,13 +24,34 @@ type Config struct {
 	User    string `json:"user"`
 	Timeout int    `json:"timeout"`
 	Key     []byte `json:"key"`
+
+	// HostKey is a public key the host must present in authorized_keys
+	// format, the first key seen is trusted when it is not set.
+	HostKey string `json:"hostKey"`
+
+	// Bastions are jump hosts the host is reached through, user,
+	// key and timeout of the host are used when not set for a bastion.
+	Bastions []Config `json:"bastions"`
 }
 
-// Runner is implementation of runner interface for ssh
+// Runner is implementation of runner interface for ssh, commands run
+// in sessions of a connection shared by runners of the same host.
 type Runner struct {
 	host    string
 	port    string
 	sshConf *ssh.ClientConfig
+	hostKey *hostKeyChecker
+	id      string
+
+	bastions []hop
+	pool     *pool
+}
+
+// hop is a bastion connections to the host go through
+type hop struct {
+	id      string
+	addr    string
+	sshConf *ssh.ClientConfig
 }
 
 // NewRunner creates ssh runner object. It requires two io.Writer
@@ -38,19 +61,59 @@ func NewRunner(config Config) (runner.Runner, error) {
 	if strings.TrimSpace(config.Host) == "" {
 		return nil, ErrHostNotSpecified
 	}
-	sshConfig, err := getSshConfig(config)
+	sshConfig, hostKey, err := getSshConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
-	r := &Runner{host: config.Host, port: config.Port, sshConf: sshConfig}
+	r := &Runner{host: config.Host, port: config.Port, sshConf: sshConfig, hostKey: hostKey, pool: defaultPool}
 	if r.port == "" {
 		r.port = DefaultPort
 	}
+	r.id = hopID(net.JoinHostPort(r.host, r.port), config)
+
+	for i, bastion := range config.Bastions {
+		bastion = inherit(bastion, config)
+		bastionConfig, _, err := getSshConfig(bastion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bastion #%d", i+1)
+		}
+
+		addr := net.JoinHostPort(bastion.Host, bastion.Port)
+		r.bastions = append(r.bastions, hop{
+			id:      hopID(addr, bastion),
+			addr:    addr,
+			sshConf: bastionConfig,
+		})
+	}
 
 	return r, nil
 }
 
+// HostKey returns the key the host is verified with, it is the key trusted
+// on first use when none has been set, so it can be stored for the machine.
+func (r *Runner) HostKey() string {
+	return r.hostKey.trusted()
+}
+
+// inherit fills settings that are not set for bastion with ones of the host
+func inherit(bastion Config, host Config) Config {
+	if bastion.Port == "" {
+		bastion.Port = DefaultPort
+	}
+	if bastion.User == "" {
+		bastion.User = host.User
+	}
+	if len(bastion.Key) == 0 {
+		bastion.Key = host.Key
+	}
+	if bastion.Timeout == 0 {
+		bastion.Timeout = host.Timeout
+	}
+
+	return bastion
+}
+
 //TODO(stgleb): Add  more context like env variables?
 // Run executes a single command on ssh session.
 //
@@ -62,17 +125,11 @@ func (r *Runner) Run(cmd *runner.Command) (err error) {
 		return nil
 	}
 
-	c, err := connectionWithBackOff(cmd.Ctx, r.host, r.port, r.sshConf,
-		time.Second*10, 5)
-
-	if err != nil {
-		return errors.Wrap(err, "ssh: establishing connection")
-	}
-
-	session, err := c.NewSession()
+	c, session, err := r.newSession(cmd.Ctx)
 	if err != nil {
-		return errors.Wrap(err, "ssh: creating new session")
+		return err
 	}
+	defer r.pool.put(c)
 	defer session.Close()
 
 	session.Stdout = cmd.Out
@@ -85,11 +142,11 @@ func (r *Runner) Run(cmd *runner.Command) (err error) {
 
 	select {
 	case <-cmd.Ctx.Done():
-		if cmd.Ctx.Err() == context.Canceled {
-			session.Signal(ssh.SIGKILL)
-			session.Close()
-		}
-		return <-waitCh
+		// command is killed both on cancellation and deadline of the step
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		<-waitCh
+		return errors.Wrap(cmd.Ctx.Err(), "ssh: run command")
 	case err := <-waitCh:
 		return err
 	}
@@ -97,3 +154,65 @@ func (r *Runner) Run(cmd *runner.Command) (err error) {
 	// We can close session multiple times
 	return session.Close()
 }
+
+// hops returns bastions followed by the host itself
+func (r *Runner) hops() []hop {
+	hops := make([]hop, 0, len(r.bastions)+1)
+	hops = append(hops, r.bastions...)
+	return append(hops, hop{
+		id:      r.id,
+		addr:    net.JoinHostPort(r.host, r.port),
+		sshConf: r.sshConf,
+	})
+}
+
+// newSession opens session on pooled connection to the host, connection
+// that turns out to be broken is dialed again once.
+func (r *Runner) newSession(ctx context.Context) (*chain, *ssh.Session, error) {
+	hops := r.hops()
+	for attempt := 0; ; attempt++ {
+		c, err := r.pool.get(ctx, hops)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "ssh: establishing connection")
+		}
+
+		if err := c.verify(hops); err != nil {
+			r.pool.put(c)
+			return nil, nil, errors.Wrap(err, "ssh: verify host key")
+		}
+
+		session, err := c.client().NewSession()
+		if err == nil {
+			return c, session, nil
+		}
+
+		// session may be refused by limit of the host, so the
+		// connection is left to the commands that are using it.
+		r.pool.evict(c)
+		r.pool.put(c)
+		if attempt > 0 {
+			return nil, nil, errors.Wrap(err, "ssh: creating new session")
+		}
+		logrus.Debugf("ssh: reconnect to %s: %v", hops[len(hops)-1].addr, err)
+	}
+}
+
+// Bastions converts bastion hosts of a kube to configs of the runner
+func Bastions(hosts []profile.BastionHost) []Config {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	bastions := make([]Config, 0, len(hosts))
+	for _, h := range hosts {
+		bastions = append(bastions, Config{
+			Host:    h.Host,
+			Port:    h.Port,
+			User:    h.User,
+			Key:     []byte(h.PrivateKey),
+			HostKey: h.HostKey,
+		})
+	}
+
+	return bastions
+}
@@ -1,10 +1,15 @@
 package ssh
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
 	"testing"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/supergiant/control/pkg/runner"
 )
@@ -59,3 +64,140 @@ func TestRunner_New(t *testing.T) {
 		}
 	}
 }
+
+func TestRunner_RunThroughBastions(t *testing.T) {
+	bastionKey, hostKey := newPrivateKey(t), newPrivateKey(t)
+
+	first := newTestServer(t, "jump", bastionKey)
+	defer first.Close()
+	// second bastion inherits user and key of the host
+	second := newTestServer(t, "root", hostKey)
+	defer second.Close()
+	host := newTestServer(t, "root", hostKey)
+	defer host.Close()
+
+	r, err := NewRunner(Config{
+		Host:    host.Host(),
+		Port:    host.Port(),
+		User:    "root",
+		Key:     hostKey,
+		Timeout: 5,
+		Bastions: []Config{
+			{
+				Host: first.Host(),
+				Port: first.Port(),
+				User: "jump",
+				Key:  bastionKey,
+			},
+			{
+				Host: second.Host(),
+				Port: second.Port(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	cmd, err := runner.NewCommand(context.Background(), "hostname", out, ioutil.Discard)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Run(cmd))
+	require.Equal(t, "hostname", out.String())
+
+	require.Equal(t, []string{"hostname"}, host.Commands())
+	require.Empty(t, first.Commands())
+	require.Empty(t, second.Commands())
+	require.Equal(t, []string{net.JoinHostPort(second.Host(), second.Port())}, first.Dials())
+	require.Equal(t, []string{net.JoinHostPort(host.Host(), host.Port())}, second.Dials())
+}
+
+func TestRunner_NewInvalidBastion(t *testing.T) {
+	_, err := NewRunner(Config{
+		Host: "10.0.0.2",
+		User: "root",
+		Key:  newPrivateKey(t),
+		Bastions: []Config{
+			{},
+		},
+	})
+	require.Equal(t, ErrHostNotSpecified, errors.Cause(err))
+}
+
+func TestRunner_RunHostKey(t *testing.T) {
+	key, otherKey := newPrivateKey(t), newPrivateKey(t)
+	host := newTestServer(t, "root", key)
+	defer host.Close()
+
+	signer, err := ssh.ParsePrivateKey(key)
+	require.NoError(t, err)
+	otherSigner, err := ssh.ParsePrivateKey(otherKey)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name        string
+		hostKey     string
+		expectedErr error
+	}{
+		{
+			name:    "matching key",
+			hostKey: marshalHostKey(signer.PublicKey()),
+		},
+		{
+			name:        "mismatch",
+			hostKey:     marshalHostKey(otherSigner.PublicKey()),
+			expectedErr: ErrHostKeyMismatch,
+		},
+	} {
+		r, err := NewRunner(Config{
+			Host:    host.Host(),
+			Port:    host.Port(),
+			User:    "root",
+			Key:     key,
+			Timeout: 5,
+			HostKey: tc.hostKey,
+		})
+		require.NoError(t, err, tc.name)
+
+		cmd, err := runner.NewCommand(context.Background(), "hostname", ioutil.Discard, ioutil.Discard)
+		require.NoError(t, err, tc.name)
+
+		err = r.Run(cmd)
+		require.Equal(t, tc.expectedErr, errors.Cause(err), tc.name)
+	}
+}
+
+func TestRunner_RunTrustOnFirstUse(t *testing.T) {
+	key := newPrivateKey(t)
+	host := newTestServer(t, "root", key)
+	defer host.Close()
+
+	r, err := NewRunner(Config{
+		Host:    host.Host(),
+		Port:    host.Port(),
+		User:    "root",
+		Key:     key,
+		Timeout: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.(*Runner).HostKey())
+
+	for i := 0; i < 2; i++ {
+		cmd, err := runner.NewCommand(context.Background(), "hostname", ioutil.Discard, ioutil.Discard)
+		require.NoError(t, err)
+		require.NoError(t, r.Run(cmd))
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	require.NoError(t, err)
+	require.Equal(t, marshalHostKey(signer.PublicKey()), r.(*Runner).HostKey())
+}
+
+func TestRunner_NewInvalidHostKey(t *testing.T) {
+	_, err := NewRunner(Config{
+		Host:    "10.0.0.2",
+		User:    "root",
+		Key:     newPrivateKey(t),
+		HostKey: "not a key",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,330 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is ssh server that echoes commands of sessions
+// and forwards tcp connections like bastions do.
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	m        sync.Mutex
+	conns    []net.Conn
+	commands []string
+	dials    []string
+	files    map[string][]byte
+}
+
+func newPrivateKey(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+}
+
+// newTestServer accepts connections of the user with the key
+func newTestServer(t *testing.T, user string, key []byte) *testServer {
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		t.Fatalf("parse key %v", err)
+	}
+	authorized := string(signer.PublicKey().Marshal())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() != user || string(key.Marshal()) != authorized {
+				return nil, ssh.ErrNoAuth
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen %v", err)
+	}
+
+	s := &testServer{
+		listener: l,
+		config:   config,
+		files:    make(map[string][]byte),
+	}
+	go s.serve()
+
+	return s
+}
+
+func (s *testServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+func (s *testServer) Port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *testServer) Close() {
+	s.listener.Close()
+}
+
+func (s *testServer) Commands() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testServer) Dials() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string(nil), s.dials...)
+}
+
+// File returns content of the file uploaded over sftp
+func (s *testServer) File(path string) ([]byte, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	content, ok := s.files[path]
+	return content, ok
+}
+
+// Files returns paths of all files of the server
+func (s *testServer) Files() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// SetFile puts the file to be downloaded over sftp
+func (s *testServer) SetFile(path string, content []byte) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.files[path] = content
+}
+
+// Connections returns count of connections accepted by the server
+func (s *testServer) Connections() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.conns)
+}
+
+// DropConnections breaks connections accepted by the server
+func (s *testServer) DropConnections() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	s.m.Lock()
+	s.conns = append(s.conns, conn)
+	s.m.Unlock()
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			go s.session(newChannel)
+		case "direct-tcpip":
+			go s.forward(newChannel)
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+		}
+	}
+}
+
+func (s *testServer) session(newChannel ssh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+
+	for req := range requests {
+		switch req.Type {
+		case "subsystem":
+			var payload struct{ Name string }
+			ssh.Unmarshal(req.Payload, &payload)
+			req.Reply(payload.Name == "sftp", nil)
+			if payload.Name == "sftp" {
+				s.sftp(channel)
+				return
+			}
+		case "exec":
+			var payload struct{ Command string }
+			ssh.Unmarshal(req.Payload, &payload)
+			req.Reply(true, nil)
+
+			s.m.Lock()
+			s.commands = append(s.commands, payload.Command)
+			s.m.Unlock()
+
+			io.WriteString(channel, payload.Command)
+			channel.SendRequest("exit-status", false, make([]byte, 4))
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// sftp serves files of the server over sftp until the channel is closed
+func (s *testServer) sftp(channel ssh.Channel) {
+	handles := make(map[string]string)
+
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(channel, header); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(channel, data); err != nil {
+			return
+		}
+
+		var response interface{}
+		switch data[0] {
+		case 1:
+			response = fxpVersionPacket{Version: sftpVersion}
+		case 3:
+			req := fxpOpenPacket{}
+			ssh.Unmarshal(data, &req)
+			response = s.sftpOpen(req, handles)
+		case 4:
+			req := fxpHandleRequest{}
+			ssh.Unmarshal(data, &req)
+			delete(handles, req.Handle)
+			response = fxpStatusPacket{ID: req.ID, Code: fxOK}
+		case 5:
+			req := fxpReadPacket{}
+			ssh.Unmarshal(data, &req)
+			content, _ := s.File(handles[req.Handle])
+			if req.Offset >= uint64(len(content)) {
+				response = fxpStatusPacket{ID: req.ID, Code: fxEOF, Message: "EOF"}
+				break
+			}
+			content = content[req.Offset:]
+			if len(content) > int(req.Length) {
+				content = content[:req.Length]
+			}
+			response = fxpDataPacket{ID: req.ID, Data: content}
+		case 6:
+			req := fxpWritePacket{}
+			ssh.Unmarshal(data, &req)
+			path := handles[req.Handle]
+			content, _ := s.File(path)
+			s.SetFile(path, append(content[:req.Offset], req.Data...))
+			response = fxpStatusPacket{ID: req.ID, Code: fxOK}
+		case 13:
+			req := fxpRemovePacket{}
+			ssh.Unmarshal(data, &req)
+			s.m.Lock()
+			delete(s.files, req.Path)
+			s.m.Unlock()
+			response = fxpStatusPacket{ID: req.ID, Code: fxOK}
+		default:
+			return
+		}
+
+		packet := ssh.Marshal(response)
+		binary.BigEndian.PutUint32(header, uint32(len(packet)))
+		channel.Write(append(header, packet...))
+	}
+}
+
+func (s *testServer) sftpOpen(req fxpOpenPacket, handles map[string]string) interface{} {
+	_, exists := s.File(req.Path)
+
+	switch {
+	case req.Flags&fxfWrite != 0 && exists && req.Flags&fxfExcl != 0:
+		return fxpStatusPacket{ID: req.ID, Code: 4, Message: "Failure"}
+	case req.Flags&fxfWrite != 0:
+		s.SetFile(req.Path, nil)
+	case !exists:
+		return fxpStatusPacket{ID: req.ID, Code: 2, Message: "No such file"}
+	}
+
+	handle := strconv.Itoa(len(handles) + 1)
+	handles[handle] = req.Path
+	return fxpHandlePacket{ID: req.ID, Handle: handle}
+}
+
+func (s *testServer) forward(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	s.m.Lock()
+	s.dials = append(s.dials, addr)
+	s.m.Unlock()
+
+	go func() {
+		io.Copy(target, channel)
+		target.Close()
+	}()
+	io.Copy(channel, target)
+	channel.Close()
+}
@@ -0,0 +1,348 @@
+package ssh
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Minimal client of SFTP version 3 that reads and writes files, see
+// https://tools.ietf.org/html/draft-ietf-secsh-filexfer-02 for the protocol.
+// Types of packets are set by sshtype tags of their structs.
+const (
+	sftpVersion = 3
+
+	fxpVersion = 2
+	fxpStatus  = 101
+	fxpHandle  = 102
+	fxpData    = 103
+
+	fxfRead  = 0x01
+	fxfWrite = 0x02
+	fxfCreat = 0x08
+	fxfTrunc = 0x10
+	fxfExcl  = 0x20
+
+	attrPermissions = 0x04
+
+	fxOK  = 0
+	fxEOF = 1
+
+	// sftpChunk is size of data read or written by a single request
+	sftpChunk = 32 * 1024
+	// maxPacket limits packets server is allowed to send
+	maxPacket = 256 * 1024
+)
+
+type fxpInitPacket struct {
+	Version uint32 `sshtype:"1"`
+}
+
+type fxpVersionPacket struct {
+	Version    uint32 `sshtype:"2"`
+	Extensions []byte `ssh:"rest"`
+}
+
+type fxpOpenPacket struct {
+	ID    uint32 `sshtype:"3"`
+	Path  string
+	Flags uint32
+	Attrs []byte `ssh:"rest"`
+}
+
+// fxpAttrs are attributes of a file with permissions only
+type fxpAttrs struct {
+	Flags uint32
+	Perm  uint32
+}
+
+type fxpHandleRequest struct {
+	ID     uint32 `sshtype:"4"`
+	Handle string
+}
+
+type fxpReadPacket struct {
+	ID     uint32 `sshtype:"5"`
+	Handle string
+	Offset uint64
+	Length uint32
+}
+
+type fxpWritePacket struct {
+	ID     uint32 `sshtype:"6"`
+	Handle string
+	Offset uint64
+	Data   []byte
+}
+
+type fxpRemovePacket struct {
+	ID   uint32 `sshtype:"13"`
+	Path string
+}
+
+type fxpStatusPacket struct {
+	ID      uint32 `sshtype:"101"`
+	Code    uint32
+	Message string
+	Rest    []byte `ssh:"rest"`
+}
+
+type fxpHandlePacket struct {
+	ID     uint32 `sshtype:"102"`
+	Handle string
+}
+
+type fxpDataPacket struct {
+	ID   uint32 `sshtype:"103"`
+	Data []byte
+}
+
+// statusError is an error status server has responded with
+type statusError struct {
+	code uint32
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("sftp: %s (%d)", e.msg, e.code)
+}
+
+// sftpClient talks to sftp subsystem of a session, requests are sent one by
+// one, so the client must not be used by several goroutines at once.
+type sftpClient struct {
+	in  io.Writer
+	out io.Reader
+	id  uint32
+}
+
+// newSFTP starts sftp subsystem on the session
+func newSFTP(session *ssh.Session) (*sftpClient, error) {
+	in, err := session.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "stdin")
+	}
+	out, err := session.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "stdout")
+	}
+
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return nil, errors.Wrap(err, "request sftp subsystem")
+	}
+
+	c := &sftpClient{in: in, out: out}
+	if err := c.send(fxpInitPacket{Version: sftpVersion}); err != nil {
+		return nil, err
+	}
+
+	data, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+
+	version := fxpVersionPacket{}
+	if err := unmarshalPacket(data, fxpVersion, &version); err != nil {
+		return nil, err
+	}
+	if version.Version != sftpVersion {
+		return nil, errors.Errorf("sftp: unsupported version %d", version.Version)
+	}
+
+	return c, nil
+}
+
+// create opens new file for writing, the file must not exist
+func (c *sftpClient) create(path string, mode os.FileMode) (string, error) {
+	return c.open(fxpOpenPacket{
+		Path:  path,
+		Flags: fxfWrite | fxfCreat | fxfTrunc | fxfExcl,
+		Attrs: ssh.Marshal(fxpAttrs{Flags: attrPermissions, Perm: uint32(mode.Perm())}),
+	})
+}
+
+// openRead opens existing file for reading
+func (c *sftpClient) openRead(path string) (string, error) {
+	return c.open(fxpOpenPacket{
+		Path:  path,
+		Flags: fxfRead,
+		// no attributes
+		Attrs: make([]byte, 4),
+	})
+}
+
+func (c *sftpClient) open(packet fxpOpenPacket) (string, error) {
+	packet.ID = c.nextID()
+	data, err := c.request(packet)
+	if err != nil {
+		return "", err
+	}
+
+	handle := fxpHandlePacket{}
+	if err := unmarshalPacket(data, fxpHandle, &handle); err != nil {
+		return "", err
+	}
+
+	return handle.Handle, nil
+}
+
+// write writes all data read from r to the file
+func (c *sftpClient) write(handle string, r io.Reader) (int64, error) {
+	var (
+		offset uint64
+		buf    = make([]byte, sftpChunk)
+	)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := c.status(fxpWritePacket{
+				ID:     c.nextID(),
+				Handle: handle,
+				Offset: offset,
+				Data:   buf[:n],
+			}); err != nil {
+				return int64(offset), err
+			}
+			offset += uint64(n)
+		}
+
+		if err == io.EOF {
+			return int64(offset), nil
+		}
+		if err != nil {
+			return int64(offset), err
+		}
+	}
+}
+
+// read copies content of the file to w
+func (c *sftpClient) read(handle string, w io.Writer) error {
+	var offset uint64
+
+	for {
+		data, err := c.request(fxpReadPacket{
+			ID:     c.nextID(),
+			Handle: handle,
+			Offset: offset,
+			Length: sftpChunk,
+		})
+		if e, ok := err.(*statusError); ok && e.code == fxEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		packet := fxpDataPacket{}
+		if err := unmarshalPacket(data, fxpData, &packet); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(packet.Data); err != nil {
+			return err
+		}
+		offset += uint64(len(packet.Data))
+	}
+}
+
+func (c *sftpClient) close(handle string) error {
+	return c.status(fxpHandleRequest{
+		ID:     c.nextID(),
+		Handle: handle,
+	})
+}
+
+func (c *sftpClient) remove(path string) error {
+	return c.status(fxpRemovePacket{
+		ID:   c.nextID(),
+		Path: path,
+	})
+}
+
+func (c *sftpClient) nextID() uint32 {
+	c.id++
+	return c.id
+}
+
+// status sends request that server responds with status to
+func (c *sftpClient) status(packet interface{}) error {
+	data, err := c.request(packet)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		return errors.Errorf("sftp: unexpected response %d", data[0])
+	}
+
+	return nil
+}
+
+// request sends packet and returns the response, error status is returned as
+// *statusError, OK status is returned as nil response with nil error.
+func (c *sftpClient) request(packet interface{}) ([]byte, error) {
+	if err := c.send(packet); err != nil {
+		return nil, err
+	}
+
+	data, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if data[0] != fxpStatus {
+		return data, nil
+	}
+
+	status := fxpStatusPacket{}
+	if err := ssh.Unmarshal(data, &status); err != nil {
+		return nil, errors.Wrap(err, "sftp: malformed status")
+	}
+	if status.Code != fxOK {
+		return nil, &statusError{code: status.Code, msg: status.Message}
+	}
+
+	return nil, nil
+}
+
+func (c *sftpClient) send(packet interface{}) error {
+	data := ssh.Marshal(packet)
+	buf := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+
+	if _, err := c.in.Write(append(buf, data...)); err != nil {
+		return errors.Wrap(err, "sftp: send")
+	}
+
+	return nil
+}
+
+func (c *sftpClient) recv() ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.out, header); err != nil {
+		return nil, errors.Wrap(err, "sftp: receive")
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length == 0 || length > maxPacket {
+		return nil, errors.Errorf("sftp: wrong packet length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.out, data); err != nil {
+		return nil, errors.Wrap(err, "sftp: receive")
+	}
+
+	return data, nil
+}
+
+func unmarshalPacket(data []byte, packetType byte, packet interface{}) error {
+	if len(data) == 0 || data[0] != packetType {
+		return errors.Errorf("sftp: unexpected response, expected %d", packetType)
+	}
+
+	return errors.Wrap(ssh.Unmarshal(data, packet), "sftp: malformed response")
+}
@@ -0,0 +1,154 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/runner"
+)
+
+const (
+	defaultFileMode os.FileMode = 0644
+
+	// uploadPrefix is a prefix of temporary files content is uploaded to
+	uploadPrefix = "/tmp/.supergiant-upload-"
+)
+
+// Upload copies content over sftp to a temporary file readable by the ssh
+// user only, then the file is moved to its path and gets its mode and owner
+// with sudo. So content is neither embedded into scripts nor written to logs,
+// while files of other users can be written.
+func (r *Runner) Upload(ctx context.Context, file runner.File, content io.Reader, size int64) error {
+	if strings.TrimSpace(file.Path) == "" {
+		return runner.ErrEmptyPath
+	}
+	if content == nil {
+		return runner.ErrNilReader
+	}
+	if file.Mode == 0 {
+		file.Mode = defaultFileMode
+	}
+
+	tmp := uploadPrefix + uuid.New()
+	err := r.sftp(ctx, func(c *sftpClient) error {
+		handle, err := c.create(tmp, 0600)
+		if err != nil {
+			return err
+		}
+
+		n, err := c.write(handle, io.LimitReader(content, size))
+		if closeErr := c.close(handle); err == nil {
+			err = closeErr
+		}
+		if err == nil && n < size {
+			err = errors.Wrapf(io.ErrUnexpectedEOF, "content has %d of %d bytes", n, size)
+		}
+
+		if err != nil {
+			if removeErr := c.remove(tmp); removeErr != nil {
+				return errors.Wrapf(err, "remove %s: %v", tmp, removeErr)
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "ssh: upload %s", file.Path)
+	}
+
+	script := fmt.Sprintf("sudo mv -f %s %s || { rm -f %s; exit 1; }",
+		quote(tmp), quote(file.Path), quote(tmp))
+	script += fmt.Sprintf(" && sudo chmod %04o %s", file.Mode.Perm(), quote(file.Path))
+	if file.Owner != "" {
+		script += fmt.Sprintf(" && sudo chown %s %s", quote(file.Owner), quote(file.Path))
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd, err := runner.NewCommand(ctx, script, ioutil.Discard, stderr)
+	if err != nil {
+		return err
+	}
+	if err := r.Run(cmd); err != nil {
+		return errors.Wrapf(err, "ssh: move upload to %s: %s", file.Path, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// Download copies content of the file on the host to w over sftp,
+// the file must be readable by the ssh user.
+func (r *Runner) Download(ctx context.Context, filePath string, w io.Writer) error {
+	if strings.TrimSpace(filePath) == "" {
+		return runner.ErrEmptyPath
+	}
+	if w == nil {
+		return runner.ErrNilWriter
+	}
+
+	err := r.sftp(ctx, func(c *sftpClient) error {
+		handle, err := c.openRead(filePath)
+		if err != nil {
+			return err
+		}
+
+		err = c.read(handle, w)
+		if closeErr := c.close(handle); err == nil {
+			err = closeErr
+		}
+
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "ssh: download %s", filePath)
+	}
+
+	return nil
+}
+
+// sftp runs f with sftp client of a new session on the host. Cancellation
+// of the context closes the session, so a temporary file of an interrupted
+// upload may be left behind.
+func (r *Runner) sftp(ctx context.Context, f func(*sftpClient) error) error {
+	if ctx == nil {
+		return runner.ErrNilContext
+	}
+
+	c, session, err := r.newSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.pool.put(c)
+	defer session.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		client, err := newSFTP(session)
+		if err == nil {
+			err = f(client)
+		}
+		errCh <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		session.Close()
+		<-errCh
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// quote escapes s to be passed as a single shell argument
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
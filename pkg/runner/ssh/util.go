@@ -1,9 +1,11 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
-	"fmt"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,20 +16,26 @@ import (
 var (
 	ErrUserNotSpecified = errors.New("user not specified")
 	ErrHostNotSpecified = errors.New("host not specified")
+	ErrHostKeyMismatch  = errors.New("host key mismatch")
 )
 
-func getSshConfig(config Config) (*ssh.ClientConfig, error) {
+func getSshConfig(config Config) (*ssh.ClientConfig, *hostKeyChecker, error) {
 	if config.User == "" {
-		return nil, ErrUserNotSpecified
+		return nil, nil, ErrUserNotSpecified
 	}
 
 	if config.Host == "" {
-		return nil, ErrHostNotSpecified
+		return nil, nil, ErrHostNotSpecified
 	}
 
 	key, err := ssh.ParsePrivateKey(config.Key)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	hostKey, err := newHostKeyChecker(config.HostKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse host key")
 	}
 
 	return &ssh.ClientConfig{
@@ -35,22 +43,80 @@ func getSshConfig(config Config) (*ssh.ClientConfig, error) {
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(key),
 		},
-		Timeout: time.Duration(config.Timeout) * time.Second,
-		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			logrus.Debugf("hostname %s,addr %s key %s", hostname, remote.String(), string(key.Type()))
-			return nil
-		},
+		Timeout:         time.Duration(config.Timeout) * time.Second,
+		HostKeyCallback: hostKey.check,
 		BannerCallback: func(message string) error {
 			logrus.Debug(message)
 			return nil
 		},
-	}, nil
+	}, hostKey, nil
 }
 
-func connectionWithBackOff(ctx context.Context, host, port string, config *ssh.ClientConfig, timeout time.Duration, attemptCount int) (*ssh.Client, error) {
+// hostKeyChecker verifies a host presents the expected key, when no key
+// is expected the first one seen is trusted for the rest of connections.
+type hostKeyChecker struct {
+	m   sync.Mutex
+	key ssh.PublicKey
+}
+
+func newHostKeyChecker(authorizedKey string) (*hostKeyChecker, error) {
+	c := &hostKeyChecker{}
+	if strings.TrimSpace(authorizedKey) == "" {
+		return c, nil
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return nil, err
+	}
+	c.key = key
+
+	return c, nil
+}
+
+func (c *hostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.key == nil {
+		logrus.Infof("ssh: trust %s host key %s of %s on first use",
+			key.Type(), ssh.FingerprintSHA256(key), hostname)
+		c.key = key
+		return nil
+	}
+
+	if !bytes.Equal(c.key.Marshal(), key.Marshal()) {
+		return errors.Wrapf(ErrHostKeyMismatch, "%s presented %s key %s, expected %s",
+			hostname, key.Type(), ssh.FingerprintSHA256(key), ssh.FingerprintSHA256(c.key))
+	}
+
+	return nil
+}
+
+// trusted returns the key the host is verified with formatted
+// the way it is stored for machines, or empty string if none.
+func (c *hostKeyChecker) trusted() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.key == nil {
+		return ""
+	}
+
+	return marshalHostKey(c.key)
+}
+
+// marshalHostKey formats a host key the way it is stored for machines.
+func marshalHostKey(key ssh.PublicKey) string {
+	return string(bytes.TrimSpace(ssh.MarshalAuthorizedKey(key)))
+}
+
+// connectionWithBackOff connects to the last of hops through the rest of them,
+// clients of all hops are returned in the same order as hops.
+func connectionWithBackOff(ctx context.Context, hops []hop, timeout time.Duration, attemptCount int) (*chain, error) {
 	var (
 		counter = 0
-		c       *ssh.Client
+		c       *chain
 		err     error
 	)
 
@@ -59,12 +125,14 @@ func connectionWithBackOff(ctx context.Context, host, port string, config *ssh.C
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
-			c, err = ssh.Dial("tcp", fmt.Sprintf("%s:%s", host, port), config)
+			c, err = dialChain(hops)
 
+			if errors.Cause(err) == ErrHostKeyMismatch {
+				return nil, err
+			}
 			if err != nil {
 				logrus.Debugf("connect to %s failed, try again in %v seconds, reason: %v",
-					fmt.Sprintf("%s:%s", host, port),
-					timeout, err)
+					hops[len(hops)-1].addr, timeout, err)
 				time.Sleep(timeout)
 				timeout = timeout * 2
 			} else {
@@ -76,3 +144,58 @@ func connectionWithBackOff(ctx context.Context, host, port string, config *ssh.C
 
 	return nil, err
 }
+
+func dialChain(hops []hop) (*chain, error) {
+	clients := make([]*ssh.Client, 0, len(hops))
+	hostKeys := make([]ssh.PublicKey, len(hops))
+
+	for i, h := range hops {
+		// ssh handshake flattens errors, keep host key one to not retry it
+		var keyErr error
+		sshConf := *h.sshConf
+		sshConf.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			keyErr = h.sshConf.HostKeyCallback(hostname, remote, key)
+			hostKeys[i] = key
+			return keyErr
+		}
+
+		if i == 0 {
+			c, err := ssh.Dial("tcp", h.addr, &sshConf)
+			if err != nil {
+				if keyErr != nil {
+					return nil, keyErr
+				}
+				return nil, err
+			}
+			clients = append(clients, c)
+			continue
+		}
+
+		// tunnel connection to the next hop through the previous one
+		conn, err := clients[i-1].Dial("tcp", h.addr)
+		if err != nil {
+			closeAll(clients)
+			return nil, errors.Wrapf(err, "dial %s through %s", h.addr, hops[i-1].addr)
+		}
+
+		clientConn, chans, reqs, err := ssh.NewClientConn(conn, h.addr, &sshConf)
+		if err != nil {
+			conn.Close()
+			closeAll(clients)
+			if keyErr != nil {
+				err = keyErr
+			}
+			return nil, errors.Wrapf(err, "connect to %s through %s", h.addr, hops[i-1].addr)
+		}
+		clients = append(clients, ssh.NewClient(clientConn, chans, reqs))
+	}
+
+	return &chain{clients: clients, hostKeys: hostKeys}, nil
+}
+
+// closeAll closes clients starting from the last hop
+func closeAll(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
+	}
+}
@@ -59,7 +59,7 @@ dZM6MSCYh9kcT0pi2FPmY9iXba9kx4XAnf+0YB5xCz9QSMk4W5xSTBs=
 	}
 
 	for _, testCase := range testCases {
-		cfg, err := getSshConfig(testCase.cfg)
+		cfg, _, err := getSshConfig(testCase.cfg)
 
 		if err != testCase.expectedErr {
 			t.Errorf("wrong error expected %v actual %v",
@@ -18,4 +18,5 @@ const (
 	NilEntity           ErrorCode = 1011
 	TimeoutExceeded     ErrorCode = 1012
 	RawError            ErrorCode = 1013
+	Conflict            ErrorCode = 1014
 )
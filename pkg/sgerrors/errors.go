@@ -32,6 +32,7 @@ var (
 	ErrNilEntity           = New("nil entity", NilEntity)
 	ErrTimeoutExceeded     = New("timeout exceeded", TimeoutExceeded)
 	ErrRawError            = New("error", RawError)
+	ErrConflict            = New("entity has been modified concurrently", Conflict)
 )
 
 func IsNotFound(err error) bool {
@@ -46,6 +47,10 @@ func IsAlreadyExists(err error) bool {
 	return errors.Cause(err) == ErrAlreadyExists
 }
 
+func IsConflict(err error) bool {
+	return errors.Cause(err) == ErrConflict
+}
+
 func IsTimeoutExceeded(err error) bool {
 	return errors.Cause(err) == ErrTimeoutExceeded
 }
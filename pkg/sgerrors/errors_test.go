@@ -137,3 +137,27 @@ func TestError_Error(t *testing.T) {
 		t.Errorf("wrong message expected %s actual %s", message, err.Error())
 	}
 }
+
+func TestIsConflict(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{
+			ErrNotFound,
+			false,
+		},
+		{
+			ErrConflict,
+			true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual := IsConflict(testCase.err)
+
+		if testCase.expected != actual {
+			t.Errorf("Wrong result expected %v actual %v", testCase.expected, actual)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 type fakeRepoManager struct {
@@ -53,6 +55,26 @@ func (s fakeStorage) Delete(ctx context.Context, prefix string, key string) erro
 	return s.deleteErr
 }
 
+func (s fakeStorage) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	return nil, nil
+}
+
+func (s fakeStorage) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	return &paging.Page{}, nil
+}
+
+func (s fakeStorage) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	return nil, s.listErr
+}
+
+func (s fakeStorage) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	return s.item, 0, s.getErr
+}
+
+func (s fakeStorage) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	return 0, s.putErr
+}
+
 func TestService_CreateRepo(t *testing.T) {
 	loggerWriter := logrus.StandardLogger().Out
 	logrus.SetOutput(ioutil.Discard)
@@ -0,0 +1,130 @@
+package encrypted
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// magic marks values written by encrypted repository, values
+// without it are treated as plain text written before encryption
+// has been turned on.
+var magic = []byte("sgenc:v1:")
+
+// envelope is a stored representation of an encrypted value. Value is
+// encrypted with random data key, the data key is encrypted with master
+// key, full storage key is used as additional data for both of them
+// so encrypted values can not be swapped between keys.
+type envelope struct {
+	KeyID      string `json:"kid"`
+	DataKey    []byte `json:"dek"`
+	Ciphertext []byte `json:"data"`
+}
+
+func isEncrypted(value []byte) bool {
+	return bytes.HasPrefix(value, magic)
+}
+
+func seal(keyring *Keyring, key string, value []byte) ([]byte, error) {
+	masterKey, err := keyring.key(keyring.Primary())
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "generate data key")
+	}
+
+	wrappedKey, err := encrypt(masterKey, dataKey, []byte(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "wrap data key")
+	}
+
+	ciphertext, err := encrypt(dataKey, value, []byte(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypt value")
+	}
+
+	data, err := json.Marshal(envelope{
+		KeyID:      keyring.Primary(),
+		DataKey:    wrappedKey,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal envelope")
+	}
+
+	return append(append([]byte{}, magic...), data...), nil
+}
+
+func open(keyring *Keyring, key string, value []byte) ([]byte, string, error) {
+	if !isEncrypted(value) {
+		return value, "", nil
+	}
+
+	e := envelope{}
+	if err := json.Unmarshal(value[len(magic):], &e); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshal envelope")
+	}
+
+	masterKey, err := keyring.key(e.KeyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dataKey, err := decrypt(masterKey, e.DataKey, []byte(key))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "unwrap data key of %s", key)
+	}
+
+	plaintext, err := decrypt(dataKey, e.Ciphertext, []byte(key))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "decrypt %s", key)
+	}
+
+	return plaintext, e.KeyID, nil
+}
+
+// encrypt returns nonce followed by AES-GCM ciphertext
+func encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce := ciphertext[:aead.NonceSize()]
+	return aead.Open(nil, nonce, ciphertext[aead.NonceSize():], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
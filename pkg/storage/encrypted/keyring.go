@@ -0,0 +1,131 @@
+package encrypted
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MasterKeyEnv is an environment variable that holds master keys
+	// when master key file is not provided, keys are separated by comma.
+	MasterKeyEnv = "SG_MASTER_KEY"
+
+	keySize = 32
+)
+
+var (
+	ErrNoMasterKey = errors.New("no master key configured")
+	ErrUnknownKey  = errors.New("unknown master key")
+)
+
+// Keyring holds master keys that wrap data encryption keys of the records.
+// Primary key is used for all new writes, the rest of keys are kept
+// to read records that have not been re-encrypted after rotation.
+type Keyring struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewKeyring creates keyring from list of entries in form of id:base64key,
+// first entry becomes a primary key.
+func NewKeyring(entries []string) (*Keyring, error) {
+	k := &Keyring{
+		keys: make(map[string][]byte),
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("wrong master key format, expected id:base64key")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode master key %s", parts[0])
+		}
+
+		if len(key) != keySize {
+			return nil, errors.Errorf("master key %s must be %d bytes long, actual %d",
+				parts[0], keySize, len(key))
+		}
+
+		if _, ok := k.keys[parts[0]]; ok {
+			return nil, errors.Errorf("duplicate master key %s", parts[0])
+		}
+
+		if k.primary == "" {
+			k.primary = parts[0]
+		}
+		k.keys[parts[0]] = key
+	}
+
+	if k.primary == "" {
+		return nil, ErrNoMasterKey
+	}
+
+	return k, nil
+}
+
+// LoadKeyring reads keyring from the file, one key per line, or from
+// MasterKeyEnv variable if file name is empty. ErrNoMasterKey is returned
+// when neither of them is set.
+func LoadKeyring(fileName string) (*Keyring, error) {
+	if fileName == "" {
+		env := os.Getenv(MasterKeyEnv)
+
+		if env == "" {
+			return nil, ErrNoMasterKey
+		}
+
+		return NewKeyring(strings.Split(env, ","))
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open master key file %s", fileName)
+	}
+	defer f.Close()
+
+	return readKeyring(f)
+}
+
+func readKeyring(r io.Reader) (*Keyring, error) {
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		entries = append(entries, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read master keys")
+	}
+
+	return NewKeyring(entries)
+}
+
+// Primary returns id of the key used for encryption.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+func (k *Keyring) key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownKey, id)
+	}
+
+	return key, nil
+}
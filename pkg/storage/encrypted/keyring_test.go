@@ -0,0 +1,98 @@
+package encrypted
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	testKey1 = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("1", keySize)))
+	testKey2 = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("2", keySize)))
+)
+
+func TestNewKeyring(t *testing.T) {
+	testCases := []struct {
+		description string
+		entries     []string
+		primary     string
+		hasErr      bool
+	}{
+		{
+			description: "empty",
+			hasErr:      true,
+		},
+		{
+			description: "success",
+			entries:     []string{"# comment", "", "k2:" + testKey2, "k1:" + testKey1},
+			primary:     "k2",
+		},
+		{
+			description: "no id",
+			entries:     []string{testKey1},
+			hasErr:      true,
+		},
+		{
+			description: "short key",
+			entries:     []string{"k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+			hasErr:      true,
+		},
+		{
+			description: "duplicate",
+			entries:     []string{"k1:" + testKey1, "k1:" + testKey2},
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		k, err := NewKeyring(testCase.entries)
+
+		if testCase.hasErr != (err != nil) {
+			t.Errorf("Unexpected error value %v", err)
+			continue
+		}
+
+		if err == nil && k.Primary() != testCase.primary {
+			t.Errorf("Wrong primary key expected %s actual %s",
+				testCase.primary, k.Primary())
+		}
+	}
+}
+
+func TestLoadKeyringEnv(t *testing.T) {
+	old := os.Getenv(MasterKeyEnv)
+	defer os.Setenv(MasterKeyEnv, old)
+
+	os.Setenv(MasterKeyEnv, "")
+
+	if _, err := LoadKeyring(""); errors.Cause(err) != ErrNoMasterKey {
+		t.Errorf("Expected no master key error actual %v", err)
+	}
+
+	os.Setenv(MasterKeyEnv, "k1:"+testKey1+",k2:"+testKey2)
+	k, err := LoadKeyring("")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if k.Primary() != "k1" || len(k.keys) != 2 {
+		t.Errorf("Wrong keyring %v", k.keys)
+	}
+}
+
+func TestReadKeyring(t *testing.T) {
+	k, err := readKeyring(strings.NewReader("k1:" + testKey1 + "\nk2:" + testKey2 + "\n"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if k.Primary() != "k1" {
+		t.Errorf("Wrong primary key %s", k.Primary())
+	}
+}
@@ -0,0 +1,207 @@
+package encrypted
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
+)
+
+var (
+	_ storage.Interface = &Repository{}
+
+	errUpToDate   = errors.New("value is encrypted with primary key")
+	errKeyDeleted = errors.New("key has been deleted")
+)
+
+// Repository encrypts values before they are written to the underlying
+// storage and decrypts them on read, so the storage itself never sees
+// cloud credentials, certificate keys and ssh keys in plain text.
+type Repository struct {
+	storage storage.Interface
+	keyring *Keyring
+}
+
+func NewRepository(s storage.Interface, keyring *Keyring) *Repository {
+	return &Repository{
+		storage: s,
+		keyring: keyring,
+	}
+}
+
+// Storage returns underlying storage that holds encrypted values
+func (r *Repository) Storage() storage.Interface {
+	return r.storage
+}
+
+func (r *Repository) Get(ctx context.Context, prefix string, key string) ([]byte, error) {
+	value, err := r.storage.Get(ctx, prefix, key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, _, err := open(r.keyring, prefix+key, value)
+	return plaintext, err
+}
+
+func (r *Repository) GetAll(ctx context.Context, prefix string) ([][]byte, error) {
+	keys, err := r.storage.ListKeys(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]byte, 0, len(keys))
+
+	for _, key := range keys {
+		value, err := r.Get(ctx, "", key)
+		// key has been deleted since it was listed
+		if sgerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "get %s", key)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (r *Repository) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	return r.storage.ListKeys(ctx, prefix)
+}
+
+func (r *Repository) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	page, err := r.storage.List(ctx, prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range page.Items {
+		page.Items[i].Value, _, err = open(r.keyring, item.Key, item.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get %s", item.Key)
+		}
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Put(ctx context.Context, prefix string, key string, value []byte) error {
+	ciphertext, err := seal(r.keyring, prefix+key, value)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Put(ctx, prefix, key, ciphertext)
+}
+
+func (r *Repository) Delete(ctx context.Context, prefix string, key string) error {
+	return r.storage.Delete(ctx, prefix, key)
+}
+
+func (r *Repository) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	in, err := r.storage.Watch(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan watch.Event, watch.DefaultBufferSize)
+
+	go func() {
+		defer close(out)
+
+		for e := range in {
+			if e.Value != nil {
+				value, _, err := open(r.keyring, e.Key, e.Value)
+				if err != nil {
+					logrus.Errorf("encrypted: watch %s: %v", e.Key, err)
+					continue
+				}
+				e.Value = value
+			}
+
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (r *Repository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	value, revision, err := r.storage.GetWithRevision(ctx, prefix, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	plaintext, _, err := open(r.keyring, prefix+key, value)
+	return plaintext, revision, err
+}
+
+func (r *Repository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	ciphertext, err := seal(r.keyring, prefix+key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.storage.PutIfRevision(ctx, prefix, key, ciphertext, revision)
+}
+
+// Reencrypt rewrites every record with prefix that is stored in plain text
+// or encrypted with a key other than primary one, it returns count of the
+// rewritten records. Reencrypt must be run after master key rotation
+// before an old key is removed from the keyring.
+func (r *Repository) Reencrypt(ctx context.Context, prefix string) (int, error) {
+	keys, err := r.storage.ListKeys(ctx, prefix)
+	if err != nil {
+		return 0, errors.Wrap(err, "list keys")
+	}
+
+	count := 0
+
+	for _, key := range keys {
+		rewritten := false
+
+		err := storage.Update(ctx, r.storage, "", key, func(value []byte) ([]byte, error) {
+			if value == nil {
+				return nil, errKeyDeleted
+			}
+
+			plaintext, keyID, err := open(r.keyring, key, value)
+			if err != nil {
+				return nil, err
+			}
+
+			if keyID == r.keyring.Primary() {
+				return nil, errUpToDate
+			}
+
+			rewritten = true
+			return seal(r.keyring, key, plaintext)
+		})
+
+		if err == errUpToDate || err == errKeyDeleted {
+			continue
+		}
+
+		if err != nil {
+			return count, errors.Wrapf(err, "reencrypt %s", key)
+		}
+
+		if rewritten {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,163 @@
+package encrypted
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/memory"
+)
+
+func newTestKeyring(t *testing.T, entries ...string) *Keyring {
+	k, err := NewKeyring(entries)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	return k
+}
+
+func TestRepository_PutGet(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewInMemoryRepository()
+	repo := NewRepository(inner, newTestKeyring(t, "k1:"+testKey1))
+
+	secret := []byte(`{"accessToken":"secret"}`)
+
+	if err := repo.Put(ctx, "/accounts/", "do", secret); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	stored, _ := inner.Get(ctx, "/accounts/", "do")
+
+	if !isEncrypted(stored) || bytes.Contains(stored, []byte("secret")) {
+		t.Errorf("Value must be encrypted %s", stored)
+	}
+
+	value, err := repo.Get(ctx, "/accounts/", "do")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !bytes.Equal(value, secret) {
+		t.Errorf("Wrong value expected %s actual %s", secret, value)
+	}
+
+	values, err := repo.GetAll(ctx, "/accounts/")
+
+	if err != nil || len(values) != 1 || !bytes.Equal(values[0], secret) {
+		t.Errorf("Wrong values %v error %v", values, err)
+	}
+
+	// Encrypted value must not be readable under other key
+	inner.Put(ctx, "/accounts/", "aws", stored)
+
+	if _, err := repo.Get(ctx, "/accounts/", "aws"); err == nil {
+		t.Errorf("Error must not be nil")
+	}
+
+	if _, err := repo.Get(ctx, "/accounts/", "gce"); !sgerrors.IsNotFound(err) {
+		t.Errorf("Expected not found error actual %v", err)
+	}
+}
+
+// staleKeysStorage lists keys that have been deleted already
+type staleKeysStorage struct {
+	*memory.InMemoryRepository
+	deleted []string
+}
+
+func (s *staleKeysStorage) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := s.InMemoryRepository.ListKeys(ctx, prefix)
+	return append(keys, s.deleted...), err
+}
+
+func TestRepository_GetAllDeleted(t *testing.T) {
+	ctx := context.Background()
+	inner := &staleKeysStorage{
+		InMemoryRepository: memory.NewInMemoryRepository(),
+		deleted:            []string{"/accounts/gce"},
+	}
+	repo := NewRepository(inner, newTestKeyring(t, "k1:"+testKey1))
+
+	secret := []byte(`{"accessToken":"secret"}`)
+
+	if err := repo.Put(ctx, "/accounts/", "do", secret); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	values, err := repo.GetAll(ctx, "/accounts/")
+
+	if err != nil || len(values) != 1 || !bytes.Equal(values[0], secret) {
+		t.Errorf("Wrong values %v error %v", values, err)
+	}
+}
+
+func TestRepository_PlainText(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewInMemoryRepository()
+	repo := NewRepository(inner, newTestKeyring(t, "k1:"+testKey1))
+
+	inner.Put(ctx, "/kubes/", "1234", []byte(`{"id":"1234"}`))
+
+	value, err := repo.Get(ctx, "/kubes/", "1234")
+
+	if err != nil || string(value) != `{"id":"1234"}` {
+		t.Errorf("Wrong value %s error %v", value, err)
+	}
+}
+
+func TestRepository_Revision(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(memory.NewInMemoryRepository(),
+		newTestKeyring(t, "k1:"+testKey1))
+
+	rev, err := repo.PutIfRevision(ctx, "tasks", "1", []byte("task"), 0)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	value, current, err := repo.GetWithRevision(ctx, "tasks", "1")
+
+	if err != nil || string(value) != "task" || current != rev {
+		t.Errorf("Wrong value %s revision %d error %v", value, current, err)
+	}
+}
+
+func TestRepository_Reencrypt(t *testing.T) {
+	ctx := context.Background()
+	inner := memory.NewInMemoryRepository()
+
+	oldRepo := NewRepository(inner, newTestKeyring(t, "k1:"+testKey1))
+	oldRepo.Put(ctx, "/kubes/", "1", []byte("kube1"))
+	inner.Put(ctx, "/kubes/", "2", []byte("kube2"))
+
+	rotated := NewRepository(inner, newTestKeyring(t,
+		"k2:"+testKey2, "k1:"+testKey1))
+	rotated.Put(ctx, "/kubes/", "3", []byte("kube3"))
+
+	count, err := rotated.Reencrypt(ctx, "")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if count != 2 {
+		t.Errorf("Wrong reencrypted count expected 2 actual %d", count)
+	}
+
+	// Old key is not needed anymore
+	newRepo := NewRepository(inner, newTestKeyring(t, "k2:"+testKey2))
+
+	for key, expected := range map[string]string{"1": "kube1", "2": "kube2", "3": "kube3"} {
+		value, err := newRepo.Get(ctx, "/kubes/", key)
+
+		if err != nil || string(value) != expected {
+			t.Errorf("Wrong value %s for key %s error %v", value, key, err)
+		}
+	}
+}
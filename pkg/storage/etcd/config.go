@@ -0,0 +1,164 @@
+package etcd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/pkg/errors"
+)
+
+const DefaultDialTimeout = 5 * time.Second
+
+// Config holds settings of connection to etcd cluster.
+type Config struct {
+	Endpoints []string
+
+	Username string
+	Password string
+
+	// CAFile is used to verify server certificates, system
+	// roots are used if it is empty.
+	CAFile string
+	// CertFile and KeyFile hold client certificate.
+	CertFile string
+	KeyFile  string
+
+	DialTimeout time.Duration
+}
+
+// ParseURI parses storage uri in form of
+// [http|https]://[user:password@]host1:2379[,host2:2379]?[ca=ca.pem&cert=cert.pem&key=key.pem&dial-timeout=5s]
+// scheme is optional and defaults to http.
+func ParseURI(uri string) (Config, error) {
+	cfg := Config{
+		DialTimeout: DefaultDialTimeout,
+	}
+
+	scheme := "http"
+	if i := strings.Index(uri, "://"); i >= 0 {
+		scheme, uri = uri[:i], uri[i+len("://"):]
+	}
+
+	if scheme != "http" && scheme != "https" {
+		return cfg, errors.Errorf("unsupported etcd scheme %s", scheme)
+	}
+
+	query := ""
+	if i := strings.Index(uri, "?"); i >= 0 {
+		uri, query = uri[:i], uri[i+1:]
+	}
+	uri = strings.TrimSuffix(uri, "/")
+
+	if i := strings.LastIndex(uri, "@"); i >= 0 {
+		var userInfo string
+		userInfo, uri = uri[:i], uri[i+1:]
+
+		parts := strings.SplitN(userInfo, ":", 2)
+		if len(parts) != 2 {
+			return cfg, errors.New("etcd password is not set")
+		}
+
+		var err error
+		if cfg.Username, err = url.PathUnescape(parts[0]); err != nil {
+			return cfg, errors.Wrap(err, "username")
+		}
+		if cfg.Password, err = url.PathUnescape(parts[1]); err != nil {
+			return cfg, errors.Wrap(err, "password")
+		}
+	}
+
+	for _, host := range strings.Split(uri, ",") {
+		if host == "" || strings.ContainsAny(host, "/ ") {
+			return cfg, errors.Errorf("wrong etcd endpoint %q", host)
+		}
+		cfg.Endpoints = append(cfg.Endpoints, scheme+"://"+host)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return cfg, errors.Wrap(err, "parse query")
+	}
+
+	for name := range values {
+		switch name {
+		case "ca":
+			cfg.CAFile = values.Get(name)
+		case "cert":
+			cfg.CertFile = values.Get(name)
+		case "key":
+			cfg.KeyFile = values.Get(name)
+		case "dial-timeout":
+			if cfg.DialTimeout, err = time.ParseDuration(values.Get(name)); err != nil {
+				return cfg, errors.Wrap(err, "dial-timeout")
+			}
+		default:
+			return cfg, errors.Errorf("unknown etcd parameter %s", name)
+		}
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return cfg, errors.New("both cert and key must be set")
+	}
+
+	if scheme == "http" && (cfg.CAFile != "" || cfg.CertFile != "") {
+		return cfg, errors.New("certificates require https scheme")
+	}
+
+	return cfg, nil
+}
+
+// clientConfig returns configuration of etcd client, certificate
+// files are read at this point.
+func (c Config) clientConfig() (clientv3.Config, error) {
+	cfg := clientv3.Config{
+		Endpoints:   c.Endpoints,
+		Username:    c.Username,
+		Password:    c.Password,
+		DialTimeout: c.DialTimeout,
+	}
+
+	if !c.secure() {
+		return cfg, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if c.CAFile != "" {
+		data, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return cfg, errors.Wrap(err, "read ca")
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return cfg, errors.Errorf("no certificates found in %s", c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return cfg, errors.Wrap(err, "load client certificate")
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	cfg.TLS = tlsConfig
+	return cfg, nil
+}
+
+func (c Config) secure() bool {
+	for _, endpoint := range c.Endpoints {
+		if strings.HasPrefix(endpoint, "https://") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package etcd_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/etcd"
+	"github.com/supergiant/control/pkg/storage/storagetest"
+)
+
+// etcdURIEnv points to etcd cluster that is used by conformance
+// tests, e.g. http://localhost:2379
+const etcdURIEnv = "SG_TEST_ETCD_URI"
+
+func TestConformance(t *testing.T) {
+	uri := os.Getenv(etcdURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", etcdURIEnv)
+	}
+
+	cfg, err := etcd.ParseURI(uri)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return etcd.NewETCDRepository(cfg)
+	}, fmt.Sprintf("/conformance/%d/", time.Now().UnixNano()))
+}
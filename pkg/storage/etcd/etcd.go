@@ -2,22 +2,30 @@ package etcd
 
 import (
 	"context"
+	"sync"
 
 	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
+// ETCDRepository keeps a single client that is shared by all operations,
+// the client is connected on first use.
 type ETCDRepository struct {
-	cfg clientv3.Config
+	cfg Config
+
+	m  sync.Mutex
+	cl *clientv3.Client
 }
 
-func NewETCDRepository(uri string) *ETCDRepository {
+func NewETCDRepository(cfg Config) *ETCDRepository {
 	return &ETCDRepository{
-		cfg: clientv3.Config{
-			Endpoints: []string{uri},
-		},
+		cfg: cfg,
 	}
 }
 
@@ -26,7 +34,6 @@ func (e *ETCDRepository) Get(ctx context.Context, prefix string, key string) ([]
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to connect to the etcd")
 	}
-	defer cl.Close()
 	kv := clientv3.NewKV(cl)
 
 	res, err := kv.Get(ctx, prefix+key)
@@ -44,28 +51,93 @@ func (e *ETCDRepository) Put(ctx context.Context, prefix string, key string, val
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to the etcd")
 	}
-	defer cl.Close()
 	kv := clientv3.NewKV(cl)
 
 	_, err = kv.Put(ctx, prefix+key, string(value))
 	return errors.Wrap(err, "failed to write to the etcd")
 }
 
+func (e *ETCDRepository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	cl, err := e.GetClient()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to connect to the etcd")
+	}
+	kv := clientv3.NewKV(cl)
+
+	res, err := kv.Get(ctx, prefix+key)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read from the etcd")
+	}
+	if res.Count == 0 {
+		return nil, 0, sgerrors.ErrNotFound
+	}
+	return res.Kvs[0].Value, res.Kvs[0].ModRevision, nil
+}
+
+func (e *ETCDRepository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	cl, err := e.GetClient()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to connect to the etcd")
+	}
+	kv := clientv3.NewKV(cl)
+
+	// ModRevision of the key that does not exist is zero
+	res, err := kv.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(prefix+key), "=", revision)).
+		Then(clientv3.OpPut(prefix+key, string(value))).
+		Commit()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to write to the etcd")
+	}
+	if !res.Succeeded {
+		return 0, sgerrors.ErrConflict
+	}
+	return res.Header.Revision, nil
+}
+
 func (e *ETCDRepository) Delete(ctx context.Context, prefix string, key string) error {
 	cl, err := e.GetClient()
 	if err != nil {
 		return errors.Wrap(err, "failed to connect to the etcd")
 	}
-	_, err = cl.Delete(ctx, prefix+key, clientv3.WithPrefix())
-	return errors.Wrap(err, "failed to read from the etcd")
+	_, err = cl.Delete(ctx, prefix+key)
+	return errors.Wrap(err, "failed to delete from the etcd")
 }
 
+// GetClient returns client shared by the repository, it must not be closed by caller.
 func (e *ETCDRepository) GetClient() (*clientv3.Client, error) {
-	client, err := clientv3.New(e.cfg)
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.cl != nil {
+		return e.cl, nil
+	}
+
+	cfg, err := e.cfg.clientConfig()
 	if err != nil {
 		return nil, err
 	}
-	return client, nil
+
+	e.cl, err = clientv3.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.cl, nil
+}
+
+// Close closes connection to the etcd
+func (e *ETCDRepository) Close() error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.cl == nil {
+		return nil
+	}
+
+	err := e.cl.Close()
+	e.cl = nil
+	return err
 }
 
 func (e *ETCDRepository) GetAll(ctx context.Context, prefix string) ([][]byte, error) {
@@ -75,7 +147,6 @@ func (e *ETCDRepository) GetAll(ctx context.Context, prefix string) ([][]byte, e
 	if err != nil {
 		return result, errors.Wrap(err, "failed to connect to the etcd")
 	}
-	defer cl.Close()
 	kv := clientv3.NewKV(cl)
 
 	r, err := kv.Get(ctx, prefix, clientv3.WithPrefix())
@@ -87,3 +158,110 @@ func (e *ETCDRepository) GetAll(ctx context.Context, prefix string) ([][]byte, e
 	}
 	return result, nil
 }
+
+func (e *ETCDRepository) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	after, err := paging.DecodeToken(prefix, opts.Continue)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := e.GetClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the etcd")
+	}
+	kv := clientv3.NewKV(cl)
+
+	start := prefix
+	if after != "" {
+		// The smallest key that is greater than the last listed one
+		start = after + "\x00"
+	}
+
+	r, err := kv.Get(ctx, start,
+		clientv3.WithRange(clientv3.GetPrefixRangeEnd(prefix)),
+		clientv3.WithLimit(int64(opts.Limit)),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read from the etcd")
+	}
+
+	page := &paging.Page{
+		Items: make([]paging.Item, 0, len(r.Kvs)),
+	}
+
+	for _, v := range r.Kvs {
+		page.Items = append(page.Items, paging.Item{
+			Key:   string(v.Key),
+			Value: v.Value,
+		})
+	}
+
+	if r.More && len(page.Items) > 0 {
+		page.Continue = paging.EncodeToken(page.Items[len(page.Items)-1].Key)
+	}
+
+	return page, nil
+}
+
+func (e *ETCDRepository) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	cl, err := e.GetClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the etcd")
+	}
+
+	events := make(chan watch.Event, watch.DefaultBufferSize)
+	watchChan := cl.Watch(ctx, prefix, clientv3.WithPrefix())
+
+	go func() {
+		defer close(events)
+
+		for resp := range watchChan {
+			if err := resp.Err(); err != nil {
+				logrus.Errorf("etcd: watch prefix %s: %v", prefix, err)
+				return
+			}
+
+			for _, ev := range resp.Events {
+				event := watch.Event{
+					Key: string(ev.Kv.Key),
+				}
+
+				switch ev.Type {
+				case mvccpb.PUT:
+					event.Type = watch.Put
+					event.Value = ev.Kv.Value
+				case mvccpb.DELETE:
+					event.Type = watch.Delete
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (e *ETCDRepository) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	cl, err := e.GetClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the etcd")
+	}
+	kv := clientv3.NewKV(cl)
+
+	r, err := kv.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read from the etcd")
+	}
+
+	keys := make([]string, 0, len(r.Kvs))
+	for _, v := range r.Kvs {
+		keys = append(keys, string(v.Key))
+	}
+	return keys, nil
+}
@@ -0,0 +1,28 @@
+package file_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/file"
+	"github.com/supergiant/control/pkg/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sg-file-storage")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		s, err := file.NewFileRepository(filepath.Join(dir, filepath.Base(t.Name())+".db"))
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return s
+	}, "/conformance/")
+}
@@ -3,17 +3,27 @@ package file
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/etcd-io/bbolt"
 
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
-const bucketName = "supergiant.io"
+const (
+	bucketName = "supergiant.io"
+	// revisionsBucketName keeps revision of the last modification for
+	// every key of the main bucket, bucket sequence is a revision counter.
+	revisionsBucketName = "supergiant.io/revisions"
+)
 
 type FileRepository struct {
 	db *bbolt.DB
+
+	broadcaster *watch.Broadcaster
 }
 
 func NewFileRepository(fileName string) (*FileRepository, error) {
@@ -23,12 +33,27 @@ func NewFileRepository(fileName string) (*FileRepository, error) {
 	}
 
 	err = db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
 
 		if err != nil {
 			return fmt.Errorf("create bucket: %s", err)
 		}
-		return nil
+
+		revisions, err := tx.CreateBucketIfNotExists([]byte(revisionsBucketName))
+
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+
+		// Assign revisions to keys written by previous versions
+		return bucket.ForEach(func(k, v []byte) error {
+			if revisions.Get(k) != nil {
+				return nil
+			}
+
+			_, err := bumpRevision(revisions, k)
+			return err
+		})
 	})
 
 	if err != nil {
@@ -36,7 +61,8 @@ func NewFileRepository(fileName string) (*FileRepository, error) {
 	}
 
 	return &FileRepository{
-		db: db,
+		db:          db,
+		broadcaster: watch.NewBroadcaster(watch.DefaultBufferSize),
 	}, nil
 }
 
@@ -69,13 +95,29 @@ func (i *FileRepository) Put(ctx context.Context, prefix string, key string, val
 		}
 
 		err = bucket.Put([]byte(prefix+key), value)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = bumpRevision(tx.Bucket([]byte(revisionsBucketName)), []byte(prefix+key))
 		return err
 	})
 
+	if err == nil {
+		i.broadcaster.Publish(watch.Event{
+			Type:  watch.Put,
+			Key:   prefix + key,
+			Value: value,
+		})
+	}
+
 	return err
 }
 
 func (i *FileRepository) Delete(ctx context.Context, prefix string, key string) error {
+	var existed bool
+
 	err := i.db.Update(func(tx *bbolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
 
@@ -83,9 +125,22 @@ func (i *FileRepository) Delete(ctx context.Context, prefix string, key string)
 			return fmt.Errorf("create bucket: %s", err)
 		}
 
+		existed = bucket.Get([]byte(prefix+key)) != nil
+
+		if err := tx.Bucket([]byte(revisionsBucketName)).Delete([]byte(prefix + key)); err != nil {
+			return err
+		}
+
 		return bucket.Delete([]byte(prefix + key))
 	})
 
+	if err == nil && existed {
+		i.broadcaster.Publish(watch.Event{
+			Type: watch.Delete,
+			Key:  prefix + key,
+		})
+	}
+
 	return err
 }
 
@@ -110,3 +165,163 @@ func (i *FileRepository) GetAll(ctx context.Context, prefix string) ([][]byte, e
 
 	return values, nil
 }
+
+func (i *FileRepository) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	keys := make([]string, 0)
+
+	err := i.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucketName)).Cursor()
+		prefixBytes := []byte(prefix)
+
+		for k, _ := cursor.Seek(prefixBytes); k != nil && bytes.HasPrefix(k, prefixBytes); k, _ = cursor.Next() {
+			keys = append(keys, string(k))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (i *FileRepository) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	after, err := paging.DecodeToken(prefix, opts.Continue)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &paging.Page{
+		Items: make([]paging.Item, 0),
+	}
+
+	err = i.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucketName)).Cursor()
+		prefixBytes := []byte(prefix)
+
+		k, v := cursor.Seek(prefixBytes)
+		if after != "" {
+			k, v = cursor.Seek([]byte(after))
+			if k != nil && string(k) == after {
+				k, v = cursor.Next()
+			}
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefixBytes); k, v = cursor.Next() {
+			if opts.Limit > 0 && len(page.Items) == opts.Limit {
+				page.Continue = paging.EncodeToken(page.Items[len(page.Items)-1].Key)
+				break
+			}
+
+			// Value is valid only during transaction, so copy it
+			page.Items = append(page.Items, paging.Item{
+				Key:   string(k),
+				Value: append([]byte{}, v...),
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (i *FileRepository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	var (
+		value    []byte
+		revision int64
+	)
+
+	err := i.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket([]byte(bucketName)).Get([]byte(prefix + key))
+
+		if v == nil {
+			return sgerrors.ErrNotFound
+		}
+
+		// Value is valid only during transaction, so copy it
+		value = append([]byte{}, v...)
+		revision = getRevision(tx.Bucket([]byte(revisionsBucketName)), []byte(prefix+key))
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, revision, nil
+}
+
+func (i *FileRepository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	var newRevision int64
+
+	err := i.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		revisions := tx.Bucket([]byte(revisionsBucketName))
+		k := []byte(prefix + key)
+
+		if bucket.Get(k) == nil && revision != 0 {
+			return sgerrors.ErrConflict
+		}
+
+		if bucket.Get(k) != nil && getRevision(revisions, k) != revision {
+			return sgerrors.ErrConflict
+		}
+
+		if err := bucket.Put(k, value); err != nil {
+			return err
+		}
+
+		var err error
+		newRevision, err = bumpRevision(revisions, k)
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	i.broadcaster.Publish(watch.Event{
+		Type:  watch.Put,
+		Key:   prefix + key,
+		Value: value,
+	})
+
+	return newRevision, nil
+}
+
+// Watch notifies about changes made through this repository, bbolt holds
+// an exclusive lock on the file so no other process can write to it.
+func (i *FileRepository) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	return i.broadcaster.Subscribe(ctx, prefix), nil
+}
+
+func getRevision(revisions *bbolt.Bucket, key []byte) int64 {
+	v := revisions.Get(key)
+
+	if len(v) != 8 {
+		return 0
+	}
+
+	return int64(binary.BigEndian.Uint64(v))
+}
+
+func bumpRevision(revisions *bbolt.Bucket, key []byte) (int64, error) {
+	seq, err := revisions.NextSequence()
+
+	if err != nil {
+		return 0, err
+	}
+
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, seq)
+
+	return int64(seq), revisions.Put(key, v)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return memory.NewInMemoryRepository()
+	}, "/conformance/")
+}
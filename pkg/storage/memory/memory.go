@@ -2,20 +2,32 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 type InMemoryRepository struct {
 	m    sync.RWMutex
 	data map[string][]byte
+
+	// revision is incremented on every write, revisions
+	// holds revision of the last modification of each key.
+	revision  int64
+	revisions map[string]int64
+
+	broadcaster *watch.Broadcaster
 }
 
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
-		data: make(map[string][]byte),
+		data:        make(map[string][]byte),
+		revisions:   make(map[string]int64),
+		broadcaster: watch.NewBroadcaster(watch.DefaultBufferSize),
 	}
 }
 
@@ -34,17 +46,76 @@ func (i *InMemoryRepository) Get(ctx context.Context, prefix string, key string)
 
 func (i *InMemoryRepository) Put(ctx context.Context, prefix string, key string, value []byte) error {
 	i.m.Lock()
-	defer i.m.Unlock()
-
-	i.data[prefix+key] = value
+	i.put(prefix+key, value)
+	i.m.Unlock()
+
+	i.publish(watch.Event{
+		Type:  watch.Put,
+		Key:   prefix + key,
+		Value: value,
+	})
 	return nil
 }
 
-func (i *InMemoryRepository) Delete(ctx context.Context, prefix string, key string) error {
+func (i *InMemoryRepository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	value, ok := i.data[prefix+key]
+
+	if !ok {
+		return nil, 0, sgerrors.ErrNotFound
+	}
+
+	return value, i.revisions[prefix+key], nil
+}
+
+func (i *InMemoryRepository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
 	i.m.Lock()
-	defer i.m.Unlock()
+	_, ok := i.data[prefix+key]
+
+	if (!ok && revision != 0) || (ok && i.revisions[prefix+key] != revision) {
+		i.m.Unlock()
+		return 0, sgerrors.ErrConflict
+	}
+
+	newRevision := i.put(prefix+key, value)
+	i.m.Unlock()
 
+	i.publish(watch.Event{
+		Type:  watch.Put,
+		Key:   prefix + key,
+		Value: value,
+	})
+	return newRevision, nil
+}
+
+// put must be called with write lock held
+func (i *InMemoryRepository) put(key string, value []byte) int64 {
+	if i.revisions == nil {
+		i.revisions = make(map[string]int64)
+	}
+
+	i.revision++
+	i.data[key] = value
+	i.revisions[key] = i.revision
+
+	return i.revision
+}
+
+func (i *InMemoryRepository) Delete(ctx context.Context, prefix string, key string) error {
+	i.m.Lock()
+	_, ok := i.data[prefix+key]
 	delete(i.data, prefix+key)
+	delete(i.revisions, prefix+key)
+	i.m.Unlock()
+
+	if ok {
+		i.publish(watch.Event{
+			Type: watch.Delete,
+			Key:  prefix + key,
+		})
+	}
 	return nil
 }
 
@@ -52,13 +123,86 @@ func (i *InMemoryRepository) GetAll(ctx context.Context, prefix string) ([][]byt
 	i.m.RLock()
 	defer i.m.RUnlock()
 
-	allKeys := make([][]byte, len(i.data))
+	allKeys := make([][]byte, 0, len(i.data))
 
 	for key := range i.data {
-		if strings.Contains(key, prefix) {
+		if strings.HasPrefix(key, prefix) {
 			allKeys = append(allKeys, i.data[key])
 		}
 	}
 
 	return allKeys, nil
 }
+
+func (i *InMemoryRepository) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	keys := make([]string, 0)
+
+	for key := range i.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (i *InMemoryRepository) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	after, err := paging.DecodeToken(prefix, opts.Continue)
+	if err != nil {
+		return nil, err
+	}
+
+	i.m.RLock()
+	defer i.m.RUnlock()
+
+	keys := make([]string, 0)
+
+	for key := range i.data {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	page := &paging.Page{}
+
+	if opts.Limit > 0 && len(keys) > opts.Limit {
+		keys = keys[:opts.Limit]
+		page.Continue = paging.EncodeToken(keys[len(keys)-1])
+	}
+
+	page.Items = make([]paging.Item, 0, len(keys))
+	for _, key := range keys {
+		page.Items = append(page.Items, paging.Item{
+			Key:   key,
+			Value: i.data[key],
+		})
+	}
+
+	return page, nil
+}
+
+func (i *InMemoryRepository) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	i.m.Lock()
+	if i.broadcaster == nil {
+		i.broadcaster = watch.NewBroadcaster(watch.DefaultBufferSize)
+	}
+	i.m.Unlock()
+
+	return i.broadcaster.Subscribe(ctx, prefix), nil
+}
+
+func (i *InMemoryRepository) publish(e watch.Event) {
+	i.m.RLock()
+	b := i.broadcaster
+	i.m.RUnlock()
+
+	if b != nil {
+		b.Publish(e)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 func TestNewInMemoryRepository(t *testing.T) {
@@ -84,3 +85,70 @@ func TestInMemoryRepository_GetAll(t *testing.T) {
 		}
 	}
 }
+
+func TestInMemoryRepository_Watch(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := repo.Watch(ctx, "prefix")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	repo.Put(ctx, "prefix", "key", []byte(`value`))
+	repo.Put(ctx, "other", "key", []byte(`value`))
+	repo.Delete(ctx, "prefix", "key")
+
+	if e := <-events; e.Type != watch.Put || e.Key != "prefixkey" || string(e.Value) != "value" {
+		t.Errorf("Wrong put event %v", e)
+	}
+
+	if e := <-events; e.Type != watch.Delete || e.Key != "prefixkey" {
+		t.Errorf("Wrong delete event %v", e)
+	}
+
+	cancel()
+
+	if _, ok := <-events; ok {
+		t.Errorf("Channel must be closed after context is done")
+	}
+}
+
+func TestInMemoryRepository_PutIfRevision(t *testing.T) {
+	repo := NewInMemoryRepository()
+
+	if _, err := repo.PutIfRevision(context.Background(), "prefix", "key", []byte(`value`), 1); err != sgerrors.ErrConflict {
+		t.Errorf("Expected conflict for missing key, actual %v", err)
+	}
+
+	rev, err := repo.PutIfRevision(context.Background(), "prefix", "key", []byte(`value`), 0)
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := repo.PutIfRevision(context.Background(), "prefix", "key", []byte(`value`), 0); err != sgerrors.ErrConflict {
+		t.Errorf("Expected conflict for existing key, actual %v", err)
+	}
+
+	repo.Put(context.Background(), "prefix", "key", []byte(`value2`))
+
+	if _, err := repo.PutIfRevision(context.Background(), "prefix", "key", []byte(`value3`), rev); err != sgerrors.ErrConflict {
+		t.Errorf("Expected conflict for stale revision, actual %v", err)
+	}
+
+	value, current, err := repo.GetWithRevision(context.Background(), "prefix", "key")
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if string(value) != "value2" || current <= rev {
+		t.Errorf("Wrong value %s or revision %d", value, current)
+	}
+
+	if _, err := repo.PutIfRevision(context.Background(), "prefix", "key", []byte(`value3`), current); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
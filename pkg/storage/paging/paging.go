@@ -0,0 +1,96 @@
+// Package paging holds types of paginated listing shared by
+// storage backends and services built on top of them.
+package paging
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+const (
+	// MaxLimit caps page size requested by clients
+	MaxLimit = 1000
+
+	// ContinueHeader holds continue token of the next page in responses
+	// of list endpoints, it is absent when the last page has been sent.
+	ContinueHeader = "X-Continue"
+)
+
+var (
+	ErrInvalidToken = sgerrors.New("invalid continue token", sgerrors.ValidationFailed)
+	ErrInvalidLimit = sgerrors.New("limit must be a positive number", sgerrors.ValidationFailed)
+)
+
+// Options select a page of keys, zero limit means no limit and
+// empty continue token starts listing from the first key.
+type Options struct {
+	Limit    int
+	Continue string
+}
+
+// Item is a stored value along with its full key
+type Item struct {
+	Key   string
+	Value []byte
+}
+
+// Page holds items sorted by key, Continue is empty
+// when there are no more items to list.
+type Page struct {
+	Items    []Item
+	Continue string
+}
+
+// EncodeToken returns continue token that resumes listing after key
+func EncodeToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// DecodeToken returns key the listing must be resumed after, token
+// is rejected if it does not belong to listing of prefix.
+func DecodeToken(prefix, token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(key), prefix) {
+		return "", ErrInvalidToken
+	}
+
+	return string(key), nil
+}
+
+// FromQuery reads limit and continue query parameters of list requests,
+// limit is optional and the whole list is returned when it is not set.
+func FromQuery(values url.Values) (Options, error) {
+	opts := Options{
+		Continue: values.Get("continue"),
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return opts, ErrInvalidLimit
+		}
+
+		if n > MaxLimit {
+			n = MaxLimit
+		}
+		opts.Limit = n
+	}
+
+	return opts, nil
+}
+
+// IsInvalid reports whether err is caused by wrong options of the request
+func IsInvalid(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrInvalidToken || cause == ErrInvalidLimit
+}
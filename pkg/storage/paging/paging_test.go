@@ -0,0 +1,72 @@
+package paging
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDecodeToken(t *testing.T) {
+	key, err := DecodeToken("/prefix/", EncodeToken("/prefix/key"))
+	if err != nil || key != "/prefix/key" {
+		t.Errorf("Wrong key %s error %v", key, err)
+	}
+
+	if key, err := DecodeToken("/prefix/", ""); err != nil || key != "" {
+		t.Errorf("Wrong key %s error %v", key, err)
+	}
+
+	if _, err := DecodeToken("/prefix/", EncodeToken("/other/key")); err != ErrInvalidToken {
+		t.Errorf("Expected error %v actual %v", ErrInvalidToken, err)
+	}
+
+	if _, err := DecodeToken("/prefix/", "%%%"); err != ErrInvalidToken {
+		t.Errorf("Expected error %v actual %v", ErrInvalidToken, err)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected Options
+		hasErr   bool
+	}{
+		{
+			query: "",
+		},
+		{
+			query:    "limit=10&continue=token",
+			expected: Options{Limit: 10, Continue: "token"},
+		},
+		{
+			query:    "limit=100000",
+			expected: Options{Limit: MaxLimit},
+		},
+		{
+			query:  "limit=0",
+			hasErr: true,
+		},
+		{
+			query:  "limit=ten",
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		values, _ := url.ParseQuery(testCase.query)
+		opts, err := FromQuery(values)
+
+		if testCase.hasErr != (err != nil) {
+			t.Errorf("%s: unexpected error value %v", testCase.query, err)
+			continue
+		}
+
+		if testCase.hasErr && !IsInvalid(err) {
+			t.Errorf("%s: error %v must be invalid options error", testCase.query, err)
+		}
+
+		if err == nil && opts != testCase.expected {
+			t.Errorf("%s: wrong options expected %+v actual %+v",
+				testCase.query, testCase.expected, opts)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/sql"
+	"github.com/supergiant/control/pkg/storage/storagetest"
+	"github.com/supergiant/control/pkg/storage/watch"
+)
+
+// postgresURIEnv points to PostgreSQL database that is used by conformance
+// tests, e.g. postgres://postgres@localhost/postgres?sslmode=disable
+const postgresURIEnv = "SG_TEST_POSTGRES_URI"
+
+func TestConformanceSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sg-sql-storage")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		s, err := sql.NewSQLRepository("sqlite3://" + filepath.Join(dir, "supergiant.db"))
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return s
+	}, fmt.Sprintf("/conformance/%d/", time.Now().UnixNano()))
+}
+
+func TestConformancePostgres(t *testing.T) {
+	uri := os.Getenv(postgresURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", postgresURIEnv)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		s, err := sql.NewSQLRepository(uri)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return s
+	}, fmt.Sprintf("/conformance/%d/", time.Now().UnixNano()))
+}
+
+func TestWatchSharedSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sg-sql-storage")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testWatchShared(t, "sqlite3://"+filepath.Join(dir, "supergiant.db"))
+}
+
+func TestWatchSharedPostgres(t *testing.T) {
+	uri := os.Getenv(postgresURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", postgresURIEnv)
+	}
+
+	testWatchShared(t, uri)
+}
+
+// testWatchShared checks that writes of one instance are watched by another
+// one sharing the database.
+func testWatchShared(t *testing.T, uri string) {
+	watcher, err := sql.NewSQLRepository(uri)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer watcher.Close()
+
+	writer, err := sql.NewSQLRepository(uri)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer writer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prefix := fmt.Sprintf("/shared/%d/", time.Now().UnixNano())
+	events, err := watcher.Watch(ctx, prefix)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if err := writer.Put(ctx, prefix, "1", []byte("one")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := writer.Delete(ctx, prefix, "1"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	for _, expected := range []watch.EventType{watch.Put, watch.Delete} {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("Channel closed before %s event", expected)
+			}
+			if e.Type != expected || e.Key != prefix+"1" {
+				t.Errorf("Wrong event %v expected %s of %s", e, expected, prefix+"1")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s event has not been received", expected)
+		}
+	}
+}
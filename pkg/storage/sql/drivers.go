@@ -0,0 +1,7 @@
+package sql
+
+import (
+	// Register database drivers
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -5,24 +5,133 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage/etcd"
 	"github.com/supergiant/control/pkg/storage/file"
 	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/sql"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 const (
 	memoryStorageType = "memory"
 	fileStorageType   = "file"
 	etcdStorageType   = "etcd"
+	sqlStorageType    = "sql"
+
+	// MaxUpdateRetries is amount of attempts to resolve conflicting update
+	MaxUpdateRetries = 16
 )
 
 // Interface is an abstraction over key value storage, gets and returns values serialized as byte slices
 // It is up to the services to do data conversion from
 type Interface interface {
 	GetAll(ctx context.Context, prefix string) ([][]byte, error)
+	// ListKeys returns sorted full keys that start with prefix, each of
+	// them can be read back with Get(ctx, "", key).
+	ListKeys(ctx context.Context, prefix string) ([]string, error)
+	// List returns a page of items with prefix sorted by key, listing
+	// is resumed after the key encoded in continue token of options.
+	List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error)
 	Get(ctx context.Context, prefix string, key string) ([]byte, error)
 	Put(ctx context.Context, prefix string, key string, value []byte) error
 	Delete(ctx context.Context, prefix string, key string) error
+	// Watch streams put and delete events for all keys with prefix
+	// until context is done, after that the channel is closed. Channel
+	// is closed as well when events could not be delivered, so closed
+	// channel while context is not done means that keys must be listed
+	// again and the watch restarted.
+	Watch(ctx context.Context, prefix string) (<-chan watch.Event, error)
+	// GetWithRevision returns value along with revision of its last modification
+	GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error)
+	// PutIfRevision writes value only if stored revision of the key equals to revision,
+	// zero revision means that key must not exist. It returns new revision of the key
+	// or sgerrors.ErrConflict when key has been modified by somebody else.
+	PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error)
+}
+
+// UpdateFunc gets current value of the key or nil if key does not exist
+// and returns a new value that must be written instead of it.
+type UpdateFunc func(value []byte) ([]byte, error)
+
+// Update performs read-modify-write of the key, update is retried with
+// fresh value when the key has been concurrently modified.
+func Update(ctx context.Context, s Interface, prefix, key string, fn UpdateFunc) error {
+	for i := 0; i < MaxUpdateRetries; i++ {
+		value, revision, err := s.GetWithRevision(ctx, prefix, key)
+
+		if err != nil && !sgerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "get %s%s", prefix, key)
+		}
+
+		newValue, err := fn(value)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = s.PutIfRevision(ctx, prefix, key, newValue, revision)
+
+		if err == nil {
+			return nil
+		}
+
+		if !sgerrors.IsConflict(err) {
+			return errors.Wrapf(err, "put %s%s", prefix, key)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return errors.Wrapf(sgerrors.ErrConflict, "update %s%s after %d attempts",
+		prefix, key, MaxUpdateRetries)
+}
+
+// FilterFunc reports whether stored item must be included into a page
+type FilterFunc func(item paging.Item) (bool, error)
+
+// ListFiltered reads pages of items with prefix until limit of items accepted
+// by filter is collected, continue token of the returned page points to the
+// last examined item so filtered out items are not read again.
+func ListFiltered(ctx context.Context, s Interface, prefix string, opts paging.Options, filter FilterFunc) (*paging.Page, error) {
+	result := &paging.Page{
+		Items: make([]paging.Item, 0),
+	}
+	batch := opts
+
+	for {
+		page, err := s.List(ctx, prefix, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, item := range page.Items {
+			ok, err := filter(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "filter %s", item.Key)
+			}
+
+			if ok {
+				result.Items = append(result.Items, item)
+			}
+
+			if opts.Limit > 0 && len(result.Items) == opts.Limit {
+				if i < len(page.Items)-1 || page.Continue != "" {
+					result.Continue = paging.EncodeToken(item.Key)
+				}
+				return result, nil
+			}
+		}
+
+		if page.Continue == "" {
+			return result, nil
+		}
+
+		batch.Continue = page.Continue
+	}
 }
 
 func GetStorage(storageType, uri string) (Interface, error) {
@@ -32,7 +141,13 @@ func GetStorage(storageType, uri string) (Interface, error) {
 	case fileStorageType:
 		return file.NewFileRepository(uri)
 	case etcdStorageType:
-		return etcd.NewETCDRepository(uri), nil
+		cfg, err := etcd.ParseURI(uri)
+		if err != nil {
+			return nil, errors.Wrap(err, "etcd uri")
+		}
+		return etcd.NewETCDRepository(cfg), nil
+	case sqlStorageType:
+		return sql.NewSQLRepository(uri)
 	}
 
 	return nil, errors.New("wrong storage type" + storageType)
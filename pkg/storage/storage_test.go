@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
+	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage/etcd"
 	"github.com/supergiant/control/pkg/storage/file"
 	"github.com/supergiant/control/pkg/storage/memory"
@@ -26,7 +28,7 @@ func TestGetStorage(t *testing.T) {
 			reflect.TypeOf(&file.FileRepository{}),
 		},
 		{
-			"/tmp/file.db",
+			"localhost:2379",
 			etcdStorageType,
 			reflect.TypeOf(&etcd.ETCDRepository{}),
 		},
@@ -46,3 +48,52 @@ func TestGetStorage(t *testing.T) {
 
 	}
 }
+
+func TestUpdate(t *testing.T) {
+	repo := memory.NewInMemoryRepository()
+	ctx := context.Background()
+
+	err := Update(ctx, repo, "prefix", "key", func(value []byte) ([]byte, error) {
+		if value != nil {
+			t.Errorf("Value must be nil for missing key")
+		}
+
+		return []byte("1"), nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	calls := 0
+	err = Update(ctx, repo, "prefix", "key", func(value []byte) ([]byte, error) {
+		calls++
+
+		if calls == 1 {
+			repo.Put(ctx, "prefix", "key", []byte("2"))
+		}
+
+		return append(value, '+'), nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if value, _ := repo.Get(ctx, "prefix", "key"); string(value) != "2+" {
+		t.Errorf("Wrong value expected 2+ actual %s", value)
+	}
+
+	if calls != 2 {
+		t.Errorf("Wrong call count expected 2 actual %d", calls)
+	}
+
+	err = Update(ctx, repo, "prefix", "key", func(value []byte) ([]byte, error) {
+		repo.Put(ctx, "prefix", "key", []byte("3"))
+		return value, nil
+	})
+
+	if !sgerrors.IsConflict(err) {
+		t.Errorf("Expected conflict error actual %v", err)
+	}
+}
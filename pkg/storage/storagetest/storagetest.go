@@ -0,0 +1,304 @@
+// Package storagetest contains conformance tests that every
+// storage.Interface implementation must pass.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
+)
+
+const eventTimeout = 5 * time.Second
+
+// Run runs conformance tests against storage created by newStorage,
+// every test gets its own storage and uses only keys with prefix.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.Interface, prefix string) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Interface, prefix string)
+	}{
+		{"GetPutDelete", testGetPutDelete},
+		{"GetAllListKeys", testGetAllListKeys},
+		{"List", testList},
+		{"Revision", testRevision},
+		{"Update", testUpdate},
+		{"Watch", testWatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newStorage(t), prefix+test.name+"/")
+		})
+	}
+}
+
+func testGetPutDelete(t *testing.T, s storage.Interface, prefix string) {
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, prefix, "1")
+	require.True(t, sgerrors.IsNotFound(err), "get missing key %v", err)
+
+	require.NoError(t, s.Put(ctx, prefix, "1", []byte("one")))
+	value, err := s.Get(ctx, prefix, "1")
+	require.NoError(t, err)
+	require.Equal(t, "one", string(value))
+
+	require.NoError(t, s.Put(ctx, prefix, "1", []byte("one again")))
+	value, err = s.Get(ctx, "", prefix+"1")
+	require.NoError(t, err)
+	require.Equal(t, "one again", string(value))
+
+	binary := []byte{0, 1, 2, 255, '\n'}
+	require.NoError(t, s.Put(ctx, prefix, "binary", binary))
+	value, err = s.Get(ctx, prefix, "binary")
+	require.NoError(t, err)
+	require.Equal(t, binary, value)
+
+	require.NoError(t, s.Delete(ctx, prefix, "1"))
+	_, err = s.Get(ctx, prefix, "1")
+	require.True(t, sgerrors.IsNotFound(err), "get deleted key %v", err)
+
+	require.NoError(t, s.Delete(ctx, prefix, "missing"))
+}
+
+func testGetAllListKeys(t *testing.T, s storage.Interface, prefix string) {
+	ctx := context.Background()
+
+	values, err := s.GetAll(ctx, prefix)
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	keys, err := s.ListKeys(ctx, prefix)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	for _, key := range []string{"b", "a", "c/1", "c/0"} {
+		require.NoError(t, s.Put(ctx, prefix, key, []byte(key)))
+	}
+	// Keys that must not be matched by prefix
+	require.NoError(t, s.Put(ctx, prefix[:len(prefix)-1], "0", []byte("other")))
+	require.NoError(t, s.Put(ctx, prefix[:len(prefix)-1]+"x/", "a", []byte("other")))
+
+	keys, err = s.ListKeys(ctx, prefix)
+	require.NoError(t, err)
+	require.Equal(t, []string{prefix + "a", prefix + "b", prefix + "c/0", prefix + "c/1"}, keys)
+
+	keys, err = s.ListKeys(ctx, prefix+"c/")
+	require.NoError(t, err)
+	require.Equal(t, []string{prefix + "c/0", prefix + "c/1"}, keys)
+
+	values, err = s.GetAll(ctx, prefix)
+	require.NoError(t, err)
+	require.Len(t, values, 4)
+
+	found := make(map[string]bool)
+	for _, value := range values {
+		found[string(value)] = true
+	}
+
+	for _, key := range []string{"a", "b", "c/0", "c/1"} {
+		require.True(t, found[key], "value %s not found", key)
+	}
+}
+
+func testList(t *testing.T, s storage.Interface, prefix string) {
+	ctx := context.Background()
+
+	page, err := s.List(ctx, prefix, paging.Options{Limit: 2})
+	require.NoError(t, err)
+	require.Empty(t, page.Items)
+	require.Empty(t, page.Continue)
+
+	keys := []string{"e", "a", "d", "b", "c"}
+	for _, key := range keys {
+		require.NoError(t, s.Put(ctx, prefix, key, []byte(key)))
+	}
+	require.NoError(t, s.Put(ctx, prefix[:len(prefix)-1]+"x/", "a", []byte("other")))
+
+	var (
+		listed []string
+		opts   = paging.Options{Limit: 2}
+	)
+
+	for i := 0; ; i++ {
+		require.True(t, i < len(keys), "too many pages")
+
+		page, err := s.List(ctx, prefix, opts)
+		require.NoError(t, err)
+		require.True(t, len(page.Items) <= opts.Limit, "page is too long")
+
+		for _, item := range page.Items {
+			require.Equal(t, prefix+string(item.Value), item.Key)
+			listed = append(listed, string(item.Value))
+		}
+
+		if page.Continue == "" {
+			break
+		}
+		opts.Continue = page.Continue
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, listed)
+
+	page, err = s.List(ctx, prefix, paging.Options{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, len(keys))
+	require.Empty(t, page.Continue)
+
+	_, err = s.List(ctx, prefix, paging.Options{Continue: paging.EncodeToken("/other/a")})
+	require.Equal(t, paging.ErrInvalidToken, err)
+
+	matched, err := storage.ListFiltered(ctx, s, prefix, paging.Options{Limit: 1},
+		func(item paging.Item) (bool, error) {
+			return string(item.Value) > "c", nil
+		})
+	require.NoError(t, err)
+	require.Len(t, matched.Items, 1)
+	require.Equal(t, "d", string(matched.Items[0].Value))
+
+	matched, err = storage.ListFiltered(ctx, s, prefix, paging.Options{Limit: 1, Continue: matched.Continue},
+		func(item paging.Item) (bool, error) {
+			return string(item.Value) > "c", nil
+		})
+	require.NoError(t, err)
+	require.Len(t, matched.Items, 1)
+	require.Equal(t, "e", string(matched.Items[0].Value))
+	require.Empty(t, matched.Continue)
+}
+
+func testRevision(t *testing.T, s storage.Interface, prefix string) {
+	ctx := context.Background()
+
+	_, _, err := s.GetWithRevision(ctx, prefix, "1")
+	require.True(t, sgerrors.IsNotFound(err), "get missing key %v", err)
+
+	_, err = s.PutIfRevision(ctx, prefix, "1", []byte("one"), 1)
+	require.True(t, sgerrors.IsConflict(err), "put missing key with revision %v", err)
+
+	rev1, err := s.PutIfRevision(ctx, prefix, "1", []byte("one"), 0)
+	require.NoError(t, err)
+	require.True(t, rev1 > 0, "revision must be positive")
+
+	value, rev, err := s.GetWithRevision(ctx, prefix, "1")
+	require.NoError(t, err)
+	require.Equal(t, "one", string(value))
+	require.Equal(t, rev1, rev)
+
+	_, err = s.PutIfRevision(ctx, prefix, "1", []byte("two"), 0)
+	require.True(t, sgerrors.IsConflict(err), "create existing key %v", err)
+
+	rev2, err := s.PutIfRevision(ctx, prefix, "1", []byte("two"), rev1)
+	require.NoError(t, err)
+	require.True(t, rev2 > rev1, "revision must grow")
+
+	_, err = s.PutIfRevision(ctx, prefix, "1", []byte("three"), rev1)
+	require.True(t, sgerrors.IsConflict(err), "put with stale revision %v", err)
+
+	require.NoError(t, s.Put(ctx, prefix, "1", []byte("three")))
+	value, rev3, err := s.GetWithRevision(ctx, prefix, "1")
+	require.NoError(t, err)
+	require.Equal(t, "three", string(value))
+	require.True(t, rev3 > rev2, "put must change revision")
+
+	require.NoError(t, s.Delete(ctx, prefix, "1"))
+	_, err = s.PutIfRevision(ctx, prefix, "1", []byte("four"), rev3)
+	require.True(t, sgerrors.IsConflict(err), "put deleted key with revision %v", err)
+
+	_, err = s.PutIfRevision(ctx, prefix, "1", []byte("four"), 0)
+	require.NoError(t, err)
+}
+
+func testUpdate(t *testing.T, s storage.Interface, prefix string) {
+	ctx := context.Background()
+	workers := 4
+	increments := 5
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < increments; j++ {
+				err := storage.Update(ctx, s, prefix, "counter", func(value []byte) ([]byte, error) {
+					counter := 0
+					if value != nil {
+						fmt.Sscanf(string(value), "%d", &counter)
+					}
+					return []byte(fmt.Sprintf("%d", counter+1)), nil
+				})
+
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	value, err := s.Get(ctx, prefix, "counter")
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%d", workers*increments), string(value))
+}
+
+func testWatch(t *testing.T, s storage.Interface, prefix string) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := s.Watch(ctx, prefix)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, prefix[:len(prefix)-1]+"x/", "1", []byte("other")))
+	require.NoError(t, s.Put(ctx, prefix, "1", []byte("one")))
+	require.NoError(t, s.Delete(ctx, prefix, "1"))
+
+	expected := []watch.Event{
+		{Type: watch.Put, Key: prefix + "1", Value: []byte("one")},
+		{Type: watch.Delete, Key: prefix + "1"},
+	}
+
+	for _, e := range expected {
+		select {
+		case actual, ok := <-events:
+			require.True(t, ok, "channel closed before %s event", e.Type)
+			require.Equal(t, e.Type, actual.Type)
+			require.Equal(t, e.Key, actual.Key)
+			if e.Value != nil {
+				require.Equal(t, e.Value, actual.Value)
+			}
+		case <-time.After(eventTimeout):
+			t.Fatalf("%s event for %s has not been received", e.Type, e.Key)
+		}
+	}
+
+	cancel()
+
+	timeout := time.After(eventTimeout)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("channel must be closed after context is done")
+		}
+	}
+}
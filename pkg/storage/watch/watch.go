@@ -0,0 +1,122 @@
+package watch
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type EventType string
+
+const (
+	Put    EventType = "PUT"
+	Delete EventType = "DELETE"
+
+	// DefaultBufferSize is a size of subscriber channel buffer
+	DefaultBufferSize = 128
+)
+
+// Event describes a change of the value stored under the Key,
+// Key is a full storage key which is prefix + key.
+type Event struct {
+	Type  EventType `json:"type"`
+	Key   string    `json:"key"`
+	Value []byte    `json:"value,omitempty"`
+}
+
+type subscriber struct {
+	prefix string
+	events chan Event
+	done   chan struct{}
+}
+
+// Broadcaster fans out events to all subscribers which prefix matches
+// event key, it is used by storage backends that have no native watch.
+type Broadcaster struct {
+	m           sync.Mutex
+	bufferSize  int
+	subscribers map[*subscriber]struct{}
+}
+
+func NewBroadcaster(bufferSize int) *Broadcaster {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Broadcaster{
+		bufferSize:  bufferSize,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Subscribe returns channel of events for keys with prefix, channel
+// is closed when context is done or when the subscriber falls behind.
+func (b *Broadcaster) Subscribe(ctx context.Context, prefix string) <-chan Event {
+	s := &subscriber{
+		prefix: prefix,
+		events: make(chan Event, b.bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	b.m.Lock()
+	b.subscribers[s] = struct{}{}
+	b.m.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.m.Lock()
+			b.unsubscribe(s)
+			b.m.Unlock()
+		case <-s.done:
+		}
+	}()
+
+	return s.events
+}
+
+// Publish sends event to every matching subscriber. Publish never blocks
+// the writer, so subscriber that does not keep up gets its channel closed
+// instead of missing the event, it has to list keys again and resubscribe.
+func (b *Broadcaster) Publish(e Event) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	for s := range b.subscribers {
+		if !strings.HasPrefix(e.Key, s.prefix) {
+			continue
+		}
+
+		select {
+		case s.events <- e:
+		default:
+			logrus.Warnf("watch: subscriber for prefix %s is full on %s "+
+				"event for key %s, close it", s.prefix, e.Type, e.Key)
+			b.unsubscribe(s)
+		}
+	}
+}
+
+// CloseAll closes channels of all subscribers, it is used when events
+// may have been missed, so subscribers have to list keys again.
+func (b *Broadcaster) CloseAll() {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	for s := range b.subscribers {
+		b.unsubscribe(s)
+	}
+}
+
+// unsubscribe removes subscriber and closes its channel, lock must be held
+func (b *Broadcaster) unsubscribe(s *subscriber) {
+	if _, ok := b.subscribers[s]; !ok {
+		return
+	}
+
+	delete(b.subscribers, s)
+	close(s.events)
+	close(s.done)
+}
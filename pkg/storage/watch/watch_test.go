@@ -0,0 +1,91 @@
+package watch
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBroadcaster_Publish(t *testing.T) {
+	b := NewBroadcaster(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubes := b.Subscribe(ctx, "/kubes/")
+	tasks := b.Subscribe(ctx, "tasks")
+
+	b.Publish(Event{Type: Put, Key: "/kubes/1234"})
+	// Subscriber buffer is full, its channel must be closed after
+	// the buffered events instead of dropping this one
+	b.Publish(Event{Type: Delete, Key: "/kubes/1234"})
+
+	if e := <-kubes; e.Type != Put || e.Key != "/kubes/1234" {
+		t.Errorf("Wrong event %v", e)
+	}
+
+	if e, ok := <-kubes; ok {
+		t.Errorf("Channel must be closed, got event %v", e)
+	}
+
+	select {
+	case e := <-tasks:
+		t.Errorf("Unexpected event %v", e)
+	default:
+	}
+
+	// Publishing to the rest of subscribers goes on
+	b.Publish(Event{Type: Put, Key: "tasks/1234"})
+	if e := <-tasks; e.Type != Put || e.Key != "tasks/1234" {
+		t.Errorf("Wrong event %v", e)
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(b.subscribers) != 1 {
+		t.Errorf("Closed subscriber must be removed, actual count %d",
+			len(b.subscribers))
+	}
+}
+
+func TestBroadcaster_Subscribe(t *testing.T) {
+	b := NewBroadcaster(0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := b.Subscribe(ctx, "")
+	cancel()
+
+	if _, ok := <-events; ok {
+		t.Errorf("Channel must be closed after context is done")
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(b.subscribers) != 0 {
+		t.Errorf("Subscriber must be removed, actual count %d",
+			len(b.subscribers))
+	}
+}
+
+func TestBroadcaster_CloseAll(t *testing.T) {
+	b := NewBroadcaster(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := []<-chan Event{b.Subscribe(ctx, ""), b.Subscribe(ctx, "tasks")}
+	b.CloseAll()
+
+	for _, ch := range events {
+		if _, ok := <-ch; ok {
+			t.Errorf("Channel must be closed")
+		}
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(b.subscribers) != 0 {
+		t.Errorf("Subscribers must be removed, actual count %d",
+			len(b.subscribers))
+	}
+}
@@ -4,14 +4,22 @@ import (
 	"context"
 
 	"github.com/stretchr/testify/mock"
+
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 // Method names for MockStorage
 const (
-	StoragePut    = "Put"
-	StorageGet    = "Get"
-	StorageGetAll = "GetAll"
-	StorageDelete = "Delete"
+	StoragePut             = "Put"
+	StorageGet             = "Get"
+	StorageGetAll          = "GetAll"
+	StorageListKeys        = "ListKeys"
+	StorageList            = "List"
+	StorageDelete          = "Delete"
+	StorageWatch           = "Watch"
+	StorageGetWithRevision = "GetWithRevision"
+	StoragePutIfRevision   = "PutIfRevision"
 )
 
 // MockStorage is a reusable mock of storage.Interface
@@ -42,3 +50,44 @@ func (m *MockStorage) Delete(ctx context.Context, prefix string, key string) err
 	args := m.Called(ctx, prefix, key)
 	return args.Error(0)
 }
+
+func (m *MockStorage) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	args := m.Called(ctx, prefix)
+	val, ok := args.Get(0).(<-chan watch.Event)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return val, args.Error(1)
+}
+
+func (m *MockStorage) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	args := m.Called(ctx, prefix, key)
+	val, ok := args.Get(0).([]byte)
+	if !ok {
+		return nil, 0, args.Error(2)
+	}
+	return val, int64(args.Int(1)), args.Error(2)
+}
+
+func (m *MockStorage) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	args := m.Called(ctx, prefix, key, value, revision)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockStorage) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	args := m.Called(ctx, prefix, opts)
+	val, ok := args.Get(0).(*paging.Page)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return val, args.Error(1)
+}
+
+func (m *MockStorage) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	args := m.Called(ctx, prefix)
+	val, ok := args.Get(0).([]string)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return val, args.Error(1)
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 type Fake struct {
@@ -11,6 +14,10 @@ type Fake struct {
 	GetErr    error
 	ListErr   error
 	DeleteErr error
+	Events    chan watch.Event
+	WatchErr  error
+	Revision  int64
+	Keys      []string
 }
 
 func (s Fake) Put(ctx context.Context, prefix string, key string, value []byte) error {
@@ -28,3 +35,40 @@ func (s Fake) GetAll(ctx context.Context, prefix string) ([][]byte, error) {
 func (s Fake) Delete(ctx context.Context, prefix string, key string) error {
 	return s.DeleteErr
 }
+
+func (s Fake) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	return s.Events, s.WatchErr
+}
+
+func (s Fake) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	return s.Item, s.Revision, s.GetErr
+}
+
+func (s Fake) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	return s.Revision + 1, s.PutErr
+}
+
+// List returns Items as a single page, Keys hold their keys
+func (s Fake) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	if s.ListErr != nil {
+		return nil, s.ListErr
+	}
+
+	page := &paging.Page{
+		Items: make([]paging.Item, 0, len(s.Items)),
+	}
+
+	for i, value := range s.Items {
+		item := paging.Item{Value: value}
+		if i < len(s.Keys) {
+			item.Key = s.Keys[i]
+		}
+		page.Items = append(page.Items, item)
+	}
+
+	return page, nil
+}
+
+func (s Fake) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	return s.Keys, s.ListErr
+}
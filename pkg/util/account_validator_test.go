@@ -5,6 +5,7 @@ import (
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 )
 
@@ -101,9 +102,13 @@ func TestValidateCredentials(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Log(testCase.description)
 		validator := CloudAccountValidatorImpl{
-			digitalOcean: testCase.getCreds,
-			aws:          testCase.getCreds,
-			gce:          testCase.getCreds,
+			getProvider: func(name clouds.Name) (providers.Provider, error) {
+				if testCase.getCreds == nil {
+					return nil, sgerrors.ErrUnknownProvider
+				}
+
+				return validatingProvider{validate: testCase.getCreds}, nil
+			},
 		}
 
 		err := validator.ValidateCredentials(testCase.cloudAccount)
@@ -121,15 +126,16 @@ func TestNewCloudAccountValidator(t *testing.T) {
 		t.Errorf("validator must not be nil")
 	}
 
-	if validator.aws == nil {
-		t.Errorf("aws must not be nil")
+	if validator.getProvider == nil {
+		t.Errorf("getProvider must not be nil")
 	}
+}
 
-	if validator.gce == nil {
-		t.Errorf("gce must not be nil")
-	}
+type validatingProvider struct {
+	providers.Provider
+	validate func(map[string]string) error
+}
 
-	if validator.digitalOcean == nil {
-		t.Errorf("digitalocean must not be nil")
-	}
+func (p validatingProvider) ValidateCredentials(credentials map[string]string) error {
+	return p.validate(credentials)
 }
@@ -13,8 +13,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
-	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -108,19 +108,12 @@ func FillCloudAccountCredentials(cloudAccount *model.CloudAccount, config *steps
 		return err
 	}
 
-	// TODO(stgleb):  Add support for other cloud providers
-	switch cloudAccount.Provider {
-	case clouds.AWS:
-		return BindParams(cloudAccount.Credentials, &config.AWSConfig)
-	case clouds.DigitalOcean:
-		return BindParams(cloudAccount.Credentials, &config.DigitalOceanConfig)
-	case clouds.GCE:
-		return BindParams(cloudAccount.Credentials, &config.GCEConfig)
-	case clouds.Azure:
-		return BindParams(cloudAccount.Credentials, &config.AzureConfig)
-	default:
-		return sgerrors.ErrUnknownProvider
+	p, err := providers.Get(cloudAccount.Provider)
+	if err != nil {
+		return err
 	}
+
+	return p.BindCredentials(cloudAccount.Credentials, config)
 }
 
 func GetRandomNode(nodeMap map[string]*model.Machine) *model.Machine {
@@ -153,67 +146,12 @@ func LoadCloudSpecificDataFromKube(k *model.Kube, config *steps.Config) error {
 	config.CertificatesConfig.CACertHash = k.Auth.CACertHash
 	config.K8SVersion = k.K8SVersion
 
-	switch config.Provider {
-	case clouds.AWS:
-		// Load AZ -> subnet mapping for cluster
-		config.AWSConfig.Subnets = k.Subnets
-		config.AWSConfig.Region = k.Region
-		config.AWSConfig.AvailabilityZone = k.CloudSpec[clouds.AwsAZ]
-		config.AWSConfig.VPCCIDR = k.CloudSpec[clouds.AwsVpcCIDR]
-		config.AWSConfig.VPCID = k.CloudSpec[clouds.AwsVpcID]
-		config.AWSConfig.KeyPairName = k.CloudSpec[clouds.AwsKeyPairName]
-		config.AWSConfig.MastersSecurityGroupID = k.CloudSpec[clouds.AwsMastersSecGroupID]
-		config.AWSConfig.NodesSecurityGroupID = k.CloudSpec[clouds.AwsNodesSecgroupID]
-		config.AWSConfig.RouteTableID = k.CloudSpec[clouds.AwsRouteTableID]
-		config.AWSConfig.InternetGatewayID = k.CloudSpec[clouds.AwsInternetGateWayID]
-		config.AWSConfig.MastersInstanceProfile = k.CloudSpec[clouds.AwsMasterInstanceProfile]
-		config.AWSConfig.NodesInstanceProfile = k.CloudSpec[clouds.AwsNodeInstanceProfile]
-		config.AWSConfig.ImageID = k.CloudSpec[clouds.AwsImageID]
-		config.Kube.SSHConfig.BootstrapPrivateKey = k.CloudSpec[clouds.AwsSshBootstrapPrivateKey]
-		config.Kube.SSHConfig.PublicKey = k.CloudSpec[clouds.AwsUserProvidedSshPublicKey]
-		config.AWSConfig.ExternalLoadBalancerName = k.CloudSpec[clouds.AwsExternalLoadBalancerName]
-		config.AWSConfig.InternalLoadBalancerName = k.CloudSpec[clouds.AwsInternalLoadBalancerName]
-		config.AWSConfig.VolumeSize = k.CloudSpec[clouds.AwsVolumeSize]
-	case clouds.GCE:
-		config.GCEConfig.Region = k.Region
-		config.GCEConfig.TargetPoolName = k.CloudSpec[clouds.GCETargetPoolName]
-		config.GCEConfig.HealthCheckName = k.CloudSpec[clouds.GCEHealthCheckName]
-
-		config.GCEConfig.BackendServiceName = k.CloudSpec[clouds.GCEBackendServiceName]
-		config.GCEConfig.BackendServiceLink = k.CloudSpec[clouds.GCEBackendServiceLink]
-
-		config.GCEConfig.ExternalAddressName = k.CloudSpec[clouds.GCEExternalIPAddressName]
-		config.GCEConfig.ExternalIPAddressLink = k.CloudSpec[clouds.GCEExternalIPAddress]
-
-		config.GCEConfig.ExternalForwardingRuleName = k.CloudSpec[clouds.GCEExternalForwardingRuleName]
-		config.GCEConfig.InternalForwardingRuleName = k.CloudSpec[clouds.GCEInternalForwardingRuleName]
-
-		config.GCEConfig.InternalIPAddressLink = k.CloudSpec[clouds.GCEInternalIPAddress]
-		config.GCEConfig.InternalAddressName = k.CloudSpec[clouds.GCEInternalIPAddressName]
-
-		config.GCEConfig.NetworkLink = k.CloudSpec[clouds.GCENetworkLink]
-		config.GCEConfig.NetworkName = k.CloudSpec[clouds.GCENetworkName]
-		config.GCEConfig.ImageFamily = k.CloudSpec[clouds.GCEImageFamily]
-
-		config.GCEConfig.AZs = k.Subnets
-
-		config.GCEConfig.InstanceGroupNames = make(map[string]string)
-
-		for az := range k.Subnets {
-			config.GCEConfig.InstanceGroupNames[az] = fmt.Sprintf("%s-%s", az, config.ClusterID)
-		}
-	case clouds.DigitalOcean:
-		config.DigitalOceanConfig.ExternalLoadBalancerID = k.CloudSpec[clouds.DigitalOceanExternalLoadBalancerID]
-		config.DigitalOceanConfig.InternalLoadBalancerID = k.CloudSpec[clouds.DigitalOceanInternalLoadBalancerID]
-	case clouds.Azure:
-		config.AzureConfig.Location = k.Region
-		config.AzureConfig.VNetCIDR = k.CloudSpec[clouds.AzureVNetCIDR]
-		config.AzureConfig.VolumeSize = k.CloudSpec[clouds.AzureVolumeSize]
-	default:
+	p, err := providers.Get(config.Provider)
+	if err != nil {
 		return errors.Wrapf(sgerrors.ErrUnsupportedProvider, "Load cloud specific data from kube %s", k.ID)
 	}
 
-	return nil
+	return p.LoadFromKube(k, config)
 }
 
 func CreateLBName(clusterID string, isExternal bool) string {
@@ -0,0 +1,337 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// Definition describes a workflow made of registered steps,
+// definitions are written in YAML or JSON, e.g.
+//
+//	name: ProvisionMaster
+//	steps:
+//	- name: createMachine
+//	- name: docker
+//	  timeout: 15m
+//	  retry:
+//	    attempts: 5
+//	    backoff: 30s
+//	  config:
+//	    dockerConfig:
+//	      version: 18.06.1
+type Definition struct {
+	Name  string           `json:"name"`
+	Steps []StepDefinition `json:"steps"`
+}
+
+// StepDefinition references a registered step, config overrides task config
+// for the step only, the steps that follow and the stored task do not see it.
+type StepDefinition struct {
+	Name string `json:"name"`
+	// Timeout is written in time.ParseDuration format, "0s" disables it
+	Timeout string           `json:"timeout,omitempty"`
+	Retry   *RetryDefinition `json:"retry,omitempty"`
+	Config  json.RawMessage  `json:"config,omitempty"`
+}
+
+// RetryDefinition overrides retry policy of a step, durations
+// are written in time.ParseDuration format.
+type RetryDefinition struct {
+	Attempts   int     `json:"attempts"`
+	Backoff    string  `json:"backoff,omitempty"`
+	MaxBackoff string  `json:"maxBackoff,omitempty"`
+	Multiplier float64 `json:"multiplier,omitempty"`
+}
+
+// definitionExts are extensions of files LoadDefinitions reads
+var definitionExts = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// ParseDefinition reads definition written in YAML or JSON
+func ParseDefinition(data []byte) (*Definition, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	def := &Definition{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(def); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// Build validates definition against registered steps and returns the workflow
+func (d Definition) Build() (Workflow, error) {
+	if strings.TrimSpace(d.Name) == "" {
+		return nil, errors.New("workflow name is empty")
+	}
+
+	if len(d.Steps) == 0 {
+		return nil, errors.Errorf("workflow %s has no steps", d.Name)
+	}
+
+	w := make(Workflow, 0, len(d.Steps))
+	for i, stepDef := range d.Steps {
+		step, err := stepDef.build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "workflow %s step #%d", d.Name, i+1)
+		}
+		w = append(w, step)
+	}
+
+	if _, err := w.dependencies(); err != nil {
+		return nil, errors.Wrapf(err, "workflow %s", d.Name)
+	}
+
+	return w, nil
+}
+
+func (d StepDefinition) build() (steps.Step, error) {
+	step := steps.GetStep(d.Name)
+	if step == nil {
+		return nil, errors.Errorf("step %s is not registered", d.Name)
+	}
+
+	if len(d.Config) > 0 {
+		if err := decodeConfig(d.Config, &steps.Config{}); err != nil {
+			return nil, errors.Wrapf(err, "config of step %s", d.Name)
+		}
+	}
+
+	if d.Retry != nil {
+		policy, err := d.Retry.policy()
+		if err != nil {
+			return nil, errors.Wrapf(err, "retry of step %s", d.Name)
+		}
+		step = steps.WithRetry(step, policy)
+	}
+
+	if d.Timeout != "" {
+		timeout, err := time.ParseDuration(d.Timeout)
+		if err != nil || timeout < 0 {
+			return nil, errors.Errorf("wrong timeout %q of step %s", d.Timeout, d.Name)
+		}
+		step = steps.WithTimeout(step, timeout)
+	}
+
+	if len(d.Config) == 0 {
+		return step, nil
+	}
+
+	return definedStep{
+		Step:   step,
+		config: d.Config,
+	}, nil
+}
+
+func (d RetryDefinition) policy() (steps.RetryPolicy, error) {
+	policy := steps.RetryPolicy{
+		Attempts:   d.Attempts,
+		Multiplier: d.Multiplier,
+	}
+
+	if d.Attempts < 1 {
+		return policy, errors.New("attempts must be positive")
+	}
+
+	var err error
+	if d.Backoff != "" {
+		if policy.Backoff, err = time.ParseDuration(d.Backoff); err != nil {
+			return policy, errors.Wrap(err, "backoff")
+		}
+	}
+
+	if d.MaxBackoff != "" {
+		if policy.MaxBackoff, err = time.ParseDuration(d.MaxBackoff); err != nil {
+			return policy, errors.Wrap(err, "maxBackoff")
+		}
+	}
+
+	return policy, nil
+}
+
+// definedStep applies config of the definition to a registered step
+type definedStep struct {
+	steps.Step
+	config json.RawMessage
+}
+
+func (s definedStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	return s.override(config, func(stepConfig *steps.Config) error {
+		return s.Step.Run(ctx, out, stepConfig)
+	})
+}
+
+func (s definedStep) Rollback(ctx context.Context, out io.Writer, config *steps.Config) error {
+	return s.override(config, func(stepConfig *steps.Config) error {
+		return s.Step.Rollback(ctx, out, stepConfig)
+	})
+}
+
+// override calls f with a copy of the config the overrides are applied to,
+// the changes made by the step itself are joined into the config afterwards.
+func (s definedStep) override(config *steps.Config, f func(*steps.Config) error) error {
+	if len(s.config) == 0 {
+		return f(config)
+	}
+
+	overridden := config.Fork()
+	if err := decodeConfig(s.config, overridden); err != nil {
+		return errors.Wrapf(err, "apply config of step %s", s.Name())
+	}
+
+	stepConfig := overridden.Fork()
+	defer config.Join(stepConfig)
+
+	return f(stepConfig)
+}
+
+func (s definedStep) RetryPolicy() steps.RetryPolicy {
+	return steps.GetRetryPolicy(s.Step)
+}
+
+func (s definedStep) Timeout(config *steps.Config) time.Duration {
+	return steps.GetTimeout(s.Step, config)
+}
+
+func (s definedStep) PlanKind() steps.PlanKind {
+	return steps.GetPlanKind(s.Step)
+}
+
+func decodeConfig(data []byte, config *steps.Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(config)
+}
+
+// RegisterDefinition builds workflow of the definition and registers it,
+// workflow with the same name is replaced.
+func RegisterDefinition(def Definition) error {
+	w, err := def.Build()
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	workflowMap[def.Name] = w
+	definitionMap[def.Name] = def
+
+	return nil
+}
+
+// GetDefinition returns definition of a registered workflow, workflows
+// that are not defined by definitions are described by names of their steps.
+func GetDefinition(workflowName string) (*Definition, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if def, ok := definitionMap[workflowName]; ok {
+		return &def, nil
+	}
+
+	w, ok := workflowMap[workflowName]
+	if !ok {
+		return nil, sgerrors.ErrNotFound
+	}
+
+	return describe(workflowName, w), nil
+}
+
+// ListDefinitions returns definitions of all registered workflows sorted by name
+func ListDefinitions() []Definition {
+	m.RLock()
+	defer m.RUnlock()
+
+	defs := make([]Definition, 0, len(workflowMap))
+	for name, w := range workflowMap {
+		if def, ok := definitionMap[name]; ok {
+			defs = append(defs, def)
+			continue
+		}
+		defs = append(defs, *describe(name, w))
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+
+	return defs
+}
+
+func describe(name string, w Workflow) *Definition {
+	def := &Definition{
+		Name:  name,
+		Steps: make([]StepDefinition, 0, len(w)),
+	}
+
+	for _, step := range w {
+		if step == nil {
+			continue
+		}
+		def.Steps = append(def.Steps, StepDefinition{
+			Name: step.Name(),
+		})
+	}
+
+	return def
+}
+
+// LoadDefinitions registers workflows defined in yaml and json files of the directory
+func LoadDefinitions(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Wrap(err, "read workflows dir")
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !definitionExts[strings.ToLower(filepath.Ext(f.Name()))] {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "read %s", f.Name())
+		}
+
+		def, err := ParseDefinition(data)
+		if err != nil {
+			return errors.Wrapf(err, "parse %s", f.Name())
+		}
+
+		if err := RegisterDefinition(*def); err != nil {
+			return errors.Wrapf(err, "register %s", f.Name())
+		}
+
+		logrus.Infof("workflows: loaded workflow %s from %s", def.Name, f.Name())
+	}
+
+	return nil
+}
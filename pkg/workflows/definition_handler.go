@@ -0,0 +1,83 @@
+package workflows
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/message"
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// maxDefinitionSize limits size of definitions sent to API
+const maxDefinitionSize = 1 << 20
+
+// DefinitionHandler lets administrators inspect workflows and register
+// new ones, workflows registered through API last until restart, the
+// ones that must survive it are put to workflows directory.
+type DefinitionHandler struct{}
+
+func NewDefinitionHandler() *DefinitionHandler {
+	return &DefinitionHandler{}
+}
+
+func (h *DefinitionHandler) Register(r *mux.Router) {
+	r.HandleFunc("/workflows", h.ListDefinitions).Methods(http.MethodGet)
+	r.HandleFunc("/workflows", h.RegisterDefinition).Methods(http.MethodPost)
+	r.HandleFunc("/workflows/{name}", h.GetDefinition).Methods(http.MethodGet)
+}
+
+func (h *DefinitionHandler) ListDefinitions(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(ListDefinitions()); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+func (h *DefinitionHandler) GetDefinition(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	def, err := GetDefinition(name)
+	if err != nil {
+		if sgerrors.IsNotFound(err) {
+			message.SendNotFound(w, name, err)
+			return
+		}
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(def); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+// RegisterDefinition registers workflow defined in YAML or JSON body of
+// the request, all errors of parsing and building the workflow are caused
+// by the definition, so they are reported as validation errors.
+func (h *DefinitionHandler) RegisterDefinition(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxDefinitionSize))
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	def, err := ParseDefinition(data)
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	if err := RegisterDefinition(*def); err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	logrus.Infof("workflows: workflow %s has been registered", def.Name)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(def); err != nil {
+		logrus.Errorf("workflows: send definition %s: %v", def.Name, err)
+	}
+}
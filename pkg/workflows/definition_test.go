@@ -0,0 +1,249 @@
+package workflows
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func registerDefinitionSteps() (*MockStep, *MockStep) {
+	first := &MockStep{name: "definition_first"}
+	second := &MockStep{name: "definition_second"}
+
+	steps.RegisterStep(first.name, first)
+	steps.RegisterStep(second.name, second)
+
+	return first, second
+}
+
+func TestDefinitionBuild(t *testing.T) {
+	registerDefinitionSteps()
+
+	testCases := []struct {
+		description string
+		data        string
+		hasErr      bool
+	}{
+		{
+			description: "yaml",
+			data: `
+name: custom
+steps:
+- name: definition_first
+- name: definition_second
+  retry:
+    attempts: 3
+    backoff: 1s
+  config:
+    dockerConfig:
+      version: 18.06.1
+`,
+		},
+		{
+			description: "json",
+			data:        `{"name": "custom", "steps": [{"name": "definition_first"}]}`,
+		},
+		{
+			description: "unknown step",
+			data:        `{"name": "custom", "steps": [{"name": "unknown"}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "unknown field",
+			data:        `{"name": "custom", "stages": []}`,
+			hasErr:      true,
+		},
+		{
+			description: "unknown config field",
+			data:        `{"name": "custom", "steps": [{"name": "definition_first", "config": {"docker": {}}}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "wrong backoff",
+			data:        `{"name": "custom", "steps": [{"name": "definition_first", "retry": {"attempts": 2, "backoff": "1"}}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "wrong timeout",
+			data:        `{"name": "custom", "steps": [{"name": "definition_first", "timeout": "-1m"}]}`,
+			hasErr:      true,
+		},
+		{
+			description: "no steps",
+			data:        `{"name": "custom"}`,
+			hasErr:      true,
+		},
+		{
+			description: "no name",
+			data:        `{"steps": [{"name": "definition_first"}]}`,
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		def, err := ParseDefinition([]byte(testCase.data))
+		if err == nil {
+			_, err = def.Build()
+		}
+
+		if testCase.hasErr != (err != nil) {
+			t.Errorf("Unexpected error value %v", err)
+		}
+	}
+}
+
+// overriddenStep records docker version it is run with
+type overriddenStep struct {
+	MockStep
+	dockerVersion string
+}
+
+func (s *overriddenStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	s.dockerVersion = config.DockerConfig.Version
+	config.AWSConfig.VPCID = "vpc-1"
+	return nil
+}
+
+func TestDefinedStep(t *testing.T) {
+	step := &overriddenStep{MockStep: MockStep{name: "definition_overridden"}}
+	steps.RegisterStep(step.name, step)
+
+	def, err := ParseDefinition([]byte(`
+name: custom
+steps:
+- name: definition_overridden
+  timeout: 5m
+  retry:
+    attempts: 3
+    backoff: 1s
+  config:
+    dockerConfig:
+      version: 18.06.1
+`))
+	require.NoError(t, err)
+
+	w, err := def.Build()
+	require.NoError(t, err)
+	require.Len(t, w, 1)
+
+	policy := steps.GetRetryPolicy(w[0])
+	require.Equal(t, 3, policy.Attempts)
+	require.Equal(t, time.Second, policy.Backoff)
+	require.Equal(t, time.Minute*5, steps.GetTimeout(w[0], &steps.Config{Timeout: time.Hour}))
+
+	cfg := &steps.Config{DockerConfig: steps.DockerConfig{Version: "17.03.2"}}
+	require.NoError(t, w[0].Run(context.Background(), ioutil.Discard, cfg))
+	require.Equal(t, "18.06.1", step.dockerVersion)
+
+	// override does not leak, while changes of the step are kept
+	require.Equal(t, "17.03.2", cfg.DockerConfig.Version)
+	require.Equal(t, "vpc-1", cfg.AWSConfig.VPCID)
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	registerDefinitionSteps()
+	workflowMap = make(map[string]Workflow)
+	definitionMap = make(map[string]Definition)
+
+	dir, err := ioutil.TempDir("", "sg-workflows")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "custom.yaml"),
+		[]byte("name: custom\nsteps:\n- name: definition_first\n- name: definition_second\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a workflow"), 0644))
+
+	require.NoError(t, LoadDefinitions(dir))
+	require.Len(t, GetWorkflow("custom"), 2)
+
+	def, err := GetDefinition("custom")
+	require.NoError(t, err)
+	require.Equal(t, "definition_second", def.Steps[1].Name)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644))
+	require.Error(t, LoadDefinitions(dir))
+}
+
+func TestDefinitionHandler(t *testing.T) {
+	registerDefinitionSteps()
+	workflowMap = make(map[string]Workflow)
+	definitionMap = make(map[string]Definition)
+	RegisterWorkFlow("builtin", Workflow{&MockStep{name: "definition_first"}})
+
+	router := mux.NewRouter()
+	NewDefinitionHandler().Register(router)
+
+	testCases := []struct {
+		description  string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "register",
+			method:       http.MethodPost,
+			url:          "/workflows",
+			body:         "name: custom\nsteps:\n- name: definition_second\n",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			description:  "invalid",
+			method:       http.MethodPost,
+			url:          "/workflows",
+			body:         "name: custom\nsteps:\n- name: unknown\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "get",
+			method:       http.MethodGet,
+			url:          "/workflows/custom",
+			expectedCode: http.StatusOK,
+			expectedBody: "definition_second",
+		},
+		{
+			description:  "not found",
+			method:       http.MethodGet,
+			url:          "/workflows/unknown",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			description:  "list",
+			method:       http.MethodGet,
+			url:          "/workflows",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"builtin","steps":[{"name":"definition_first"}]},{"name":"custom"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(testCase.method, testCase.url, strings.NewReader(testCase.body))
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != testCase.expectedCode {
+			t.Errorf("Wrong status code expected %d actual %d", testCase.expectedCode, rec.Code)
+		}
+
+		if !strings.Contains(rec.Body.String(), testCase.expectedBody) {
+			t.Errorf("Response %s must contain %s", rec.Body.String(), testCase.expectedBody)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package workflows
+
+import (
+	"github.com/pkg/errors"
+)
+
+// dependencies returns indexes of steps that each step of the workflow waits for.
+// A step waits for the steps named by its Depends() that are part of the workflow,
+// a step that names none of them waits for the previous step, so workflows made
+// of steps without declared dependencies run in order. Steps that return empty
+// non-nil list of dependencies can be started right away.
+func (w Workflow) dependencies() ([][]int, error) {
+	indexes := make(map[string][]int, len(w))
+	for i, step := range w {
+		indexes[step.Name()] = append(indexes[step.Name()], i)
+	}
+
+	deps := make([][]int, len(w))
+
+	for i, step := range w {
+		names := step.Depends()
+
+		for _, name := range names {
+			for _, j := range indexes[name] {
+				if j == i {
+					return nil, errors.Errorf("step %s depends on itself", name)
+				}
+				deps[i] = append(deps[i], j)
+			}
+		}
+
+		independent := names != nil && len(names) == 0
+		if len(deps[i]) == 0 && !independent && i > 0 {
+			deps[i] = []int{i - 1}
+		}
+	}
+
+	if err := checkCycles(w, deps); err != nil {
+		return nil, err
+	}
+
+	return deps, nil
+}
+
+// checkCycles makes sure that every step can be started eventually
+func checkCycles(w Workflow, deps [][]int) error {
+	resolved := make([]bool, len(deps))
+
+	for count := 0; count < len(deps); {
+		progress := false
+
+		for i := range deps {
+			if resolved[i] || !allResolved(deps[i], resolved) {
+				continue
+			}
+
+			resolved[i] = true
+			progress = true
+			count++
+		}
+
+		if !progress {
+			for i := range deps {
+				if !resolved[i] {
+					return errors.Errorf("dependency cycle at step %s", w[i].Name())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// rollbackOrder returns indexes of steps in the order they are rolled back,
+// a step is rolled back only after all steps that depend on it.
+func rollbackOrder(deps [][]int) []int {
+	resolved := make([]bool, len(deps))
+	order := make([]int, 0, len(deps))
+
+	for len(order) < len(deps) {
+		progress := false
+
+		for i := range deps {
+			if resolved[i] || !allResolved(deps[i], resolved) {
+				continue
+			}
+
+			resolved[i] = true
+			progress = true
+			order = append(order, i)
+		}
+
+		// dependencies are checked for cycles before the task is run
+		if !progress {
+			break
+		}
+	}
+
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order
+}
+
+func allResolved(indexes []int, resolved []bool) bool {
+	for _, i := range indexes {
+		if !resolved[i] {
+			return false
+		}
+	}
+
+	return true
+}
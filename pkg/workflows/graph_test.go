@@ -0,0 +1,243 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+type depStep struct {
+	MockStep
+	deps []string
+}
+
+func (s *depStep) Depends() []string {
+	return s.deps
+}
+
+func TestWorkflowDependencies(t *testing.T) {
+	testCases := []struct {
+		description string
+		workflow    Workflow
+		expected    [][]int
+		hasErr      bool
+	}{
+		{
+			description: "sequential",
+			workflow: Workflow{
+				&MockStep{name: "a"},
+				&MockStep{name: "b"},
+				&MockStep{name: "c"},
+			},
+			expected: [][]int{nil, {0}, {1}},
+		},
+		{
+			description: "parallel",
+			workflow: Workflow{
+				&depStep{MockStep: MockStep{name: "a"}, deps: []string{}},
+				&depStep{MockStep: MockStep{name: "b"}, deps: []string{}},
+				&depStep{MockStep: MockStep{name: "c"}, deps: []string{"a", "b"}},
+			},
+			expected: [][]int{nil, nil, {0, 1}},
+		},
+		{
+			description: "missing dependency",
+			workflow: Workflow{
+				&MockStep{name: "a"},
+				&depStep{MockStep: MockStep{name: "b"}, deps: []string{"unknown"}},
+			},
+			expected: [][]int{nil, {0}},
+		},
+		{
+			description: "self dependency",
+			workflow: Workflow{
+				&depStep{MockStep: MockStep{name: "a"}, deps: []string{"a"}},
+			},
+			hasErr: true,
+		},
+		{
+			description: "cycle",
+			workflow: Workflow{
+				&depStep{MockStep: MockStep{name: "a"}, deps: []string{"b"}},
+				&depStep{MockStep: MockStep{name: "b"}, deps: []string{"a"}},
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		deps, err := testCase.workflow.dependencies()
+
+		if testCase.hasErr != (err != nil) {
+			t.Errorf("Unexpected error value %v", err)
+			continue
+		}
+
+		if err == nil && !reflect.DeepEqual(deps, testCase.expected) {
+			t.Errorf("Wrong dependencies expected %v actual %v", testCase.expected, deps)
+		}
+	}
+}
+
+func TestRollbackOrder(t *testing.T) {
+	testCases := []struct {
+		description string
+		deps        [][]int
+		expected    []int
+	}{
+		{
+			description: "sequential",
+			deps:        [][]int{nil, {0}, {1}},
+			expected:    []int{2, 1, 0},
+		},
+		{
+			description: "forward dependency",
+			deps:        [][]int{{2}, nil, {1}},
+			expected:    []int{0, 2, 1},
+		},
+		{
+			description: "parallel",
+			deps:        [][]int{nil, nil, {0, 1}},
+			expected:    []int{2, 1, 0},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		if order := rollbackOrder(testCase.deps); !reflect.DeepEqual(order, testCase.expected) {
+			t.Errorf("Wrong rollback order expected %v actual %v", testCase.expected, order)
+		}
+	}
+}
+
+// blockingStep waits until all parallel steps are started
+type blockingStep struct {
+	depStep
+	wg *sync.WaitGroup
+}
+
+func (s *blockingStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	s.wg.Done()
+	s.wg.Wait()
+	return nil
+}
+
+func TestTaskRunParallel(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	last := &depStep{MockStep: MockStep{name: "c"}, deps: []string{"a", "b"}}
+	wf := []steps.Step{
+		&blockingStep{depStep: depStep{MockStep: MockStep{name: "a"}, deps: []string{}}, wg: wg},
+		&blockingStep{depStep: depStep{MockStep: MockStep{name: "b"}, deps: []string{}}, wg: wg},
+		last,
+	}
+
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	if err != nil {
+		t.Fatalf("Error while creating task %v", err)
+	}
+
+	select {
+	case err = <-task.Run(context.Background(), steps.Config{}, &bufferCloser{}):
+	case <-time.After(time.Second * 5):
+		t.Fatal("Independent steps have not been run in parallel")
+	}
+
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if task.Status != statuses.Success || last.counter != 1 {
+		t.Errorf("Wrong task status %s or step run count %d", task.Status, last.counter)
+	}
+}
+
+// configStep changes the config while other steps are running
+type configStep struct {
+	blockingStep
+	set func(*steps.Config)
+}
+
+func (s *configStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	s.blockingStep.Run(ctx, out, config)
+	s.set(config)
+	return nil
+}
+
+func TestTaskRunParallelConfig(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	wf := []steps.Step{
+		&configStep{
+			blockingStep: blockingStep{depStep: depStep{MockStep: MockStep{name: "vpc"}, deps: []string{}}, wg: wg},
+			set: func(config *steps.Config) {
+				config.AWSConfig.VPCID = "vpc-1"
+			},
+		},
+		&configStep{
+			blockingStep: blockingStep{depStep: depStep{MockStep: MockStep{name: "keypair"}, deps: []string{}}, wg: wg},
+			set: func(config *steps.Config) {
+				config.AWSConfig.KeyPairName = "key"
+				config.Node.HostKey = "ssh-ed25519 AAAA"
+			},
+		},
+	}
+
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+
+	select {
+	case err = <-task.Run(context.Background(), steps.Config{}, &bufferCloser{}):
+	case <-time.After(time.Second * 5):
+		t.Fatal("Independent steps have not been run in parallel")
+	}
+	require.NoError(t, err)
+
+	stored := &Task{}
+	require.NoError(t, json.Unmarshal(s.storage[Prefix+task.ID], stored))
+
+	for _, config := range []*steps.Config{task.Config, stored.Config} {
+		require.Equal(t, "vpc-1", config.AWSConfig.VPCID)
+		require.Equal(t, "key", config.AWSConfig.KeyPairName)
+		require.Equal(t, "ssh-ed25519 AAAA", config.Node.HostKey)
+	}
+}
+
+func TestSetStepConcurrency(t *testing.T) {
+	defer SetStepConcurrency(DefaultStepConcurrency)
+
+	SetStepConcurrency(0)
+	if limit := getStepConcurrency(); limit != 1 {
+		t.Errorf("Wrong step concurrency expected 1 actual %d", limit)
+	}
+
+	SetStepConcurrency(8)
+	if limit := getStepConcurrency(); limit != 8 {
+		t.Errorf("Wrong step concurrency expected 8 actual %d", limit)
+	}
+}
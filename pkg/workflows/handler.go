@@ -2,10 +2,12 @@ package workflows
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -14,13 +16,18 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/hpcloud/tail"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/supergiant/control/pkg/message"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
 	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
@@ -31,6 +38,7 @@ type cloudAccountGetter interface {
 type TaskHandler struct {
 	runnerFactory func(config ssh.Config) (runner.Runner, error)
 	getTail       func(string) (*tail.Tail, error)
+	getLog        func(string) (io.ReadCloser, error)
 
 	cloudAccGetter cloudAccountGetter
 	repository     storage.Interface
@@ -52,12 +60,27 @@ type TaskResponse struct {
 	ID string `json:"id"`
 }
 
+// TaskSummary describes a task in task listings
+type TaskSummary struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Kind       string          `json:"kind,omitempty"`
+	ClusterID  string          `json:"clusterId,omitempty"`
+	Status     statuses.Status `json:"status"`
+	FailedStep string          `json:"failedStep,omitempty"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
 func NewTaskHandler(repository storage.Interface, runnerFactory func(config ssh.Config) (runner.Runner, error), getter cloudAccountGetter, logDir string) *TaskHandler {
 	return &TaskHandler{
 		runnerFactory:  runnerFactory,
 		repository:     repository,
 		cloudAccGetter: getter,
 		getWriter:      util.GetWriterFunc(logDir),
+		getLog: func(id string) (io.ReadCloser, error) {
+			return os.Open(path.Join(logDir, util.MakeFileName(id)))
+		},
 		getTail: func(id string) (*tail.Tail, error) {
 			t, err := tail.TailFile(path.Join(logDir, util.MakeFileName(id)),
 				tail.Config{
@@ -81,13 +104,95 @@ func NewTaskHandler(repository storage.Interface, runnerFactory func(config ssh.
 }
 
 func (h *TaskHandler) Register(m *mux.Router) {
+	m.HandleFunc("/tasks", h.ListTasks).Methods(http.MethodGet)
 	m.HandleFunc("/tasks/{id}", h.GetTask).Methods(http.MethodGet)
 	m.HandleFunc("/tasks/{id}/restart",
 		h.RestartTask).Methods(http.MethodPost)
+	m.HandleFunc("/tasks/{id}/logs", h.GetStepLogs).
+		Methods(http.MethodGet).Queries("step", "{step}")
 	m.HandleFunc("/tasks/{id}/logs", h.StreamLogs).Methods(http.MethodGet)
 	m.HandleFunc("/tasks/{id}/logs/ws", h.GetLogs).Methods(http.MethodGet)
 }
 
+// ListTasks returns summaries of tasks of all kubes, tasks are filtered
+// by status, type, clusterId, failedStep and time range of their start
+// set by since and until query parameters in RFC3339 format.
+func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	opts, err := paging.FromQuery(r.URL.Query())
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	filter, err := taskFilterFromQuery(r.URL.Query())
+	if err != nil {
+		message.SendValidationFailed(w, err)
+		return
+	}
+
+	tasks, next, err := ListTasks(r.Context(), h.repository, filter, opts)
+	if err != nil {
+		if paging.IsInvalid(err) {
+			message.SendValidationFailed(w, err)
+			return
+		}
+
+		message.SendUnknownError(w, err)
+		return
+	}
+
+	if next != "" {
+		w.Header().Set(paging.ContinueHeader, next)
+	}
+
+	resp := make([]TaskSummary, 0, len(tasks))
+	for _, task := range tasks {
+		summary := TaskSummary{
+			ID:         task.ID,
+			Type:       task.Type,
+			Kind:       TaskKind(task.Type),
+			Status:     task.Status,
+			FailedStep: task.FailedStep(),
+			StartedAt:  task.StartedAt,
+			FinishedAt: task.FinishedAt,
+		}
+
+		if task.Config != nil {
+			summary.ClusterID = task.Config.ClusterID
+		}
+
+		resp = append(resp, summary)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		message.SendUnknownError(w, err)
+	}
+}
+
+func taskFilterFromQuery(values url.Values) (TaskFilter, error) {
+	filter := TaskFilter{
+		Status:     statuses.Status(values.Get("status")),
+		Type:       values.Get("type"),
+		ClusterID:  values.Get("clusterId"),
+		FailedStep: values.Get("failedStep"),
+	}
+
+	var err error
+	if since := values.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, errors.Wrap(err, "since")
+		}
+	}
+
+	if until := values.Get("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return filter, errors.Wrap(err, "until")
+		}
+	}
+
+	return filter, nil
+}
+
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
@@ -117,18 +222,16 @@ func (h *TaskHandler) RestartTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logrus.Debugf("get task %s", id)
-	data, err := h.repository.Get(r.Context(), Prefix, id)
+	task, err := LoadTask(r.Context(), id, h.repository)
 
-	if err != nil {
+	if sgerrors.IsNotFound(err) {
 		logrus.Debugf("task %s not found", id)
 		http.NotFound(w, r)
 		return
 	}
 
-	task, err := DeserializeTask(data, h.repository)
-
 	if err != nil {
-		logrus.Debugf("error deserializing task %s %v", id, err)
+		logrus.Debugf("error loading task %s %v", id, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -146,6 +249,39 @@ func (h *TaskHandler) RestartTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// GetStepLogs returns lines of task log that belong to the step
+// set by step query parameter, see StepLogs.
+func (h *TaskHandler) GetStepLogs(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	stepName := r.URL.Query().Get("step")
+
+	f, err := h.getLog(id)
+	if os.IsNotExist(err) {
+		message.SendNotFound(w, "log of task "+id, sgerrors.ErrNotFound)
+		return
+	}
+
+	if err != nil {
+		message.SendUnknownError(w, err)
+		return
+	}
+	defer f.Close()
+
+	lines, err := StepLogs(f, stepName)
+	if err != nil {
+		message.SendUnknownError(w, errors.Wrapf(err, "read log of task %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			logrus.Errorf("send log of step %s of task %s: %v", stepName, id, err)
+			return
+		}
+	}
+}
+
 // NOTE(stgleb): This is made for testing purposes and example, remove when UI is done.
 func (h *TaskHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
@@ -5,20 +5,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hpcloud/tail"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
 
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/storage/memory"
 	"github.com/supergiant/control/pkg/testutils"
+	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
@@ -252,6 +258,60 @@ func TestTaskHandler_StreamLogs(t *testing.T) {
 	}
 }
 
+func TestTaskHandler_GetStepLogs(t *testing.T) {
+	testCases := []struct {
+		description  string
+		log          string
+		getLogErr    error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			description:  "file not found",
+			getLogErr:    os.ErrNotExist,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			description:  "unknown error",
+			getLogErr:    errors.New("error"),
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			description: "success",
+			log: `level=info msg="[ssh] - started"
+level=info msg="[ssh] - success"
+level=info msg="[docker] - started"
+level=info msg="[docker] - success"
+`,
+			expectedCode: http.StatusOK,
+			expectedBody: `level=info msg="[docker] - started"
+level=info msg="[docker] - success"
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/tasks/abcd/logs?step=docker", nil)
+
+		router := mux.NewRouter()
+		handler := TaskHandler{
+			getLog: func(id string) (io.ReadCloser, error) {
+				require.Equal(t, "abcd", id)
+				return ioutil.NopCloser(strings.NewReader(testCase.log)), testCase.getLogErr
+			},
+		}
+		handler.Register(router)
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, testCase.expectedCode, rec.Code)
+		if testCase.expectedCode == http.StatusOK {
+			require.Equal(t, testCase.expectedBody, rec.Body.String())
+		}
+	}
+}
+
 func TestNewTaskHandler(t *testing.T) {
 	r := &testutils.MockStorage{}
 	h := NewTaskHandler(r, nil, nil, "")
@@ -260,3 +320,104 @@ func TestNewTaskHandler(t *testing.T) {
 		t.Errorf("Handler must not be nil")
 	}
 }
+
+func TestTaskHandler_ListTasks(t *testing.T) {
+	repository := memory.NewInMemoryRepository()
+	startedAt := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, task := range []*Task{
+		{
+			ID:        "1",
+			Type:      ProvisionMaster,
+			Status:    statuses.Success,
+			Config:    &steps.Config{ClusterID: "kube1"},
+			StartedAt: &startedAt,
+		},
+		{
+			ID:     "2",
+			Type:   ProvisionNode,
+			Status: statuses.Error,
+			Config: &steps.Config{ClusterID: "kube2"},
+			StepStatuses: []StepStatus{
+				{StepName: "docker", Status: statuses.Success},
+				{StepName: "kubelet", Status: statuses.TimedOut},
+			},
+		},
+	} {
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+		require.NoError(t, repository.Put(context.Background(), Prefix, task.ID, data))
+	}
+
+	testCases := []struct {
+		description  string
+		query        string
+		expectedCode int
+		expectedIDs  []string
+	}{
+		{
+			description:  "all",
+			expectedCode: http.StatusOK,
+			expectedIDs:  []string{"1", "2"},
+		},
+		{
+			description:  "kind",
+			query:        "type=node",
+			expectedCode: http.StatusOK,
+			expectedIDs:  []string{"2"},
+		},
+		{
+			description:  "cluster and status",
+			query:        "clusterId=kube1&status=success",
+			expectedCode: http.StatusOK,
+			expectedIDs:  []string{"1"},
+		},
+		{
+			description:  "failed step",
+			query:        "failedStep=kubelet",
+			expectedCode: http.StatusOK,
+			expectedIDs:  []string{"2"},
+		},
+		{
+			description:  "time range",
+			query:        "since=2019-05-01T00:00:00Z&until=2019-05-02T00:00:00Z",
+			expectedCode: http.StatusOK,
+			expectedIDs:  []string{"1"},
+		},
+		{
+			description:  "wrong time",
+			query:        "since=yesterday",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			description:  "wrong limit",
+			query:        "limit=-1",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	router := mux.NewRouter()
+	NewTaskHandler(repository, nil, nil, "").Register(router)
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/tasks?"+testCase.query, nil)
+
+		router.ServeHTTP(resp, req)
+
+		require.Equal(t, testCase.expectedCode, resp.Code)
+		if resp.Code != http.StatusOK {
+			continue
+		}
+
+		var summaries []TaskSummary
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summaries))
+
+		ids := make([]string, 0)
+		for _, summary := range summaries {
+			ids = append(ids, summary.ID)
+		}
+		require.Equal(t, testCase.expectedIDs, ids)
+	}
+}
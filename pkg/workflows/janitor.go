@@ -0,0 +1,302 @@
+package workflows
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+)
+
+// RetentionPolicy decides which finished tasks are removed along with
+// their logs, zero values disable corresponding limits.
+type RetentionPolicy struct {
+	// MaxAge is how long finished tasks are kept after they have finished
+	MaxAge time.Duration
+	// FailedMaxAge is used instead of MaxAge for failed tasks, failed
+	// tasks younger than that are not removed by KeepPerCluster either.
+	FailedMaxAge time.Duration
+	// KeepPerCluster is number of the latest finished tasks of a cluster
+	// that are kept regardless of their age.
+	KeepPerCluster int
+	// ArchiveDir is a directory where logs and records of removed tasks
+	// are put as gzipped tar bundles, nothing is archived if it is empty.
+	ArchiveDir string
+}
+
+// Enabled reports whether policy removes any tasks
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.FailedMaxAge > 0 || p.KeepPerCluster > 0
+}
+
+// InUseFunc returns IDs of tasks that must be kept whatever the policy is
+type InUseFunc func(ctx context.Context) (map[string]bool, error)
+
+// Janitor removes finished tasks and their log files according to policy
+type Janitor struct {
+	repository storage.Interface
+	raw        storage.Interface
+	logDir     string
+	policy     RetentionPolicy
+	inUse      InUseFunc
+
+	now func() time.Time
+}
+
+// NewJanitor creates janitor of tasks of the repository, records of tasks
+// are archived as raw storage holds them, so that encrypted records are
+// not put into bundles in plain text.
+func NewJanitor(repository, raw storage.Interface, logDir string, policy RetentionPolicy, inUse InUseFunc) *Janitor {
+	return &Janitor{
+		repository: repository,
+		raw:        raw,
+		logDir:     logDir,
+		policy:     policy,
+		inUse:      inUse,
+		now:        time.Now,
+	}
+}
+
+// Run sweeps tasks every interval until context is done
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := j.Sweep(ctx); err != nil {
+			logrus.Errorf("task janitor: %v", err)
+		} else if len(removed) > 0 {
+			logrus.Infof("task janitor: removed %d tasks", len(removed))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sweep removes tasks that are not retained by policy and returns their
+// IDs, logs of the tasks are archived before removal if policy says so.
+func (j *Janitor) Sweep(ctx context.Context) ([]string, error) {
+	if !j.policy.Enabled() {
+		return nil, nil
+	}
+
+	tasks, _, err := ListTasks(ctx, j.repository, TaskFilter{}, paging.Options{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list tasks")
+	}
+
+	inUse := map[string]bool{}
+	if j.inUse != nil {
+		if inUse, err = j.inUse(ctx); err != nil {
+			return nil, errors.Wrap(err, "get tasks in use")
+		}
+	}
+
+	expired := j.expired(tasks, inUse)
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	if j.policy.ArchiveDir != "" {
+		if err := j.archive(ctx, expired); err != nil {
+			return nil, errors.Wrap(err, "archive tasks")
+		}
+	}
+
+	removed := make([]string, 0, len(expired))
+	for _, task := range expired {
+		if err := j.repository.Delete(ctx, Prefix, task.ID); err != nil {
+			return removed, errors.Wrapf(err, "delete task %s", task.ID)
+		}
+		removed = append(removed, task.ID)
+
+		if j.logDir == "" {
+			continue
+		}
+
+		logFile := filepath.Join(j.logDir, util.MakeFileName(task.ID))
+		if err := os.Remove(logFile); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("remove log of task %s: %v", task.ID, err)
+		}
+	}
+
+	return removed, nil
+}
+
+// expired returns finished tasks that are not retained by policy,
+// tasks of every cluster are ordered from the latest to the oldest.
+func (j *Janitor) expired(tasks []*Task, inUse map[string]bool) []*Task {
+	clusters := make(map[string][]*Task)
+	for _, task := range tasks {
+		if !isFinished(task) || inUse[task.ID] {
+			continue
+		}
+
+		clusterID := ""
+		if task.Config != nil {
+			clusterID = task.Config.ClusterID
+		}
+		clusters[clusterID] = append(clusters[clusterID], task)
+	}
+
+	now := j.now()
+	expired := make([]*Task, 0)
+
+	for _, clusterTasks := range clusters {
+		sort.Slice(clusterTasks, func(i, k int) bool {
+			return clusterTasks[i].FinishedAt.After(*clusterTasks[k].FinishedAt)
+		})
+
+		for i, task := range clusterTasks {
+			age := now.Sub(*task.FinishedAt)
+
+			maxAge := j.policy.MaxAge
+			if task.Status == statuses.Error && j.policy.FailedMaxAge > 0 {
+				maxAge = j.policy.FailedMaxAge
+				// failed tasks are kept for investigation even
+				// if the cluster has got a lot of new tasks
+				if age <= maxAge {
+					continue
+				}
+			}
+
+			if maxAge > 0 && age > maxAge ||
+				j.policy.KeepPerCluster > 0 && i >= j.policy.KeepPerCluster {
+				expired = append(expired, task)
+			}
+		}
+	}
+
+	return expired
+}
+
+// archive writes records and logs of tasks to a new bundle in archive dir
+func (j *Janitor) archive(ctx context.Context, tasks []*Task) error {
+	records := make(map[string][]byte, len(tasks))
+	for _, task := range tasks {
+		data, err := j.raw.Get(ctx, Prefix, task.ID)
+		if err != nil {
+			return errors.Wrapf(err, "get task %s", task.ID)
+		}
+		records[task.ID] = data
+	}
+
+	if err := os.MkdirAll(j.policy.ArchiveDir, 0755); err != nil {
+		return errors.Wrapf(err, "create %s", j.policy.ArchiveDir)
+	}
+
+	now := j.now()
+	name := filepath.Join(j.policy.ArchiveDir,
+		fmt.Sprintf("tasks-%s.tar.gz", now.UTC().Format("20060102T150405.000000000")))
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "create %s", name)
+	}
+
+	if err := writeBundle(f, tasks, records, j.logDir, now); err != nil {
+		f.Close()
+		os.Remove(name)
+		return errors.Wrapf(err, "write %s", name)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return errors.Wrapf(err, "close %s", name)
+	}
+
+	return nil
+}
+
+func writeBundle(w io.Writer, tasks []*Task, records map[string][]byte, logDir string, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, task := range tasks {
+		data := records[task.ID]
+		if err := writeBundleFile(tw, task.ID+".json", int64(len(data)), modTime,
+			bytes.NewReader(data)); err != nil {
+			return err
+		}
+
+		if logDir == "" {
+			continue
+		}
+
+		if err := writeBundleLog(tw, logDir, util.MakeFileName(task.ID)); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "close tar")
+	}
+
+	return errors.Wrap(gz.Close(), "close gzip")
+}
+
+func writeBundleLog(tw *tar.Writer, logDir, name string) error {
+	f, err := os.Open(filepath.Join(logDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "open log %s", name)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "stat log %s", name)
+	}
+
+	return writeBundleFile(tw, name, info.Size(), info.ModTime(), f)
+}
+
+func writeBundleFile(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "write %s header", name)
+	}
+
+	if _, err := io.CopyN(tw, r, size); err != nil {
+		return errors.Wrapf(err, "write %s", name)
+	}
+
+	return nil
+}
+
+func isFinished(task *Task) bool {
+	if task.FinishedAt == nil {
+		return false
+	}
+
+	switch task.Status {
+	case statuses.Success, statuses.Error, statuses.Cancelled:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package workflows
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/storage/encrypted"
+	"github.com/supergiant/control/pkg/storage/memory"
+	"github.com/supergiant/control/pkg/util"
+	"github.com/supergiant/control/pkg/workflows/statuses"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestJanitorSweep(t *testing.T) {
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	hoursAgo := func(h int) *time.Time {
+		finishedAt := now.Add(-time.Duration(h) * time.Hour)
+		return &finishedAt
+	}
+
+	tasks := []*Task{
+		{ID: "old", Status: statuses.Success, FinishedAt: hoursAgo(48)},
+		{ID: "recent", Status: statuses.Success, FinishedAt: hoursAgo(1)},
+		{ID: "failed", Status: statuses.Error, FinishedAt: hoursAgo(48)},
+		{ID: "failed-old", Status: statuses.Error, FinishedAt: hoursAgo(100)},
+		{ID: "running", Status: statuses.Executing},
+		{ID: "in-use", Status: statuses.Success, FinishedAt: hoursAgo(48)},
+		{ID: "first", Status: statuses.Success, FinishedAt: hoursAgo(3)},
+		{ID: "second", Status: statuses.Success, FinishedAt: hoursAgo(2)},
+	}
+	// only the latest task of the other cluster is kept by count
+	for _, task := range tasks[6:] {
+		task.Config = &steps.Config{ClusterID: "other"}
+	}
+
+	logDir, err := ioutil.TempDir("", "janitor")
+	require.NoError(t, err)
+	defer os.RemoveAll(logDir)
+
+	repository := memory.NewInMemoryRepository()
+	for _, task := range tasks {
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+		require.NoError(t, repository.Put(context.Background(), Prefix, task.ID, data))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(logDir, util.MakeFileName(task.ID)),
+			[]byte("log of "+task.ID), 0600))
+	}
+
+	archiveDir := filepath.Join(logDir, "archive")
+	j := NewJanitor(repository, repository, logDir, RetentionPolicy{
+		MaxAge:         24 * time.Hour,
+		FailedMaxAge:   72 * time.Hour,
+		KeepPerCluster: 1,
+		ArchiveDir:     archiveDir,
+	}, func(context.Context) (map[string]bool, error) {
+		return map[string]bool{"in-use": true}, nil
+	})
+	j.now = func() time.Time { return now }
+
+	removed, err := j.Sweep(context.Background())
+	require.NoError(t, err)
+	sort.Strings(removed)
+	require.Equal(t, []string{"failed-old", "first", "old"}, removed)
+
+	for _, task := range tasks {
+		_, err := repository.Get(context.Background(), Prefix, task.ID)
+		_, logErr := os.Stat(filepath.Join(logDir, util.MakeFileName(task.ID)))
+
+		if contains(removed, task.ID) {
+			require.Error(t, err, task.ID)
+			require.True(t, os.IsNotExist(logErr), task.ID)
+		} else {
+			require.NoError(t, err, task.ID)
+			require.NoError(t, logErr, task.ID)
+		}
+	}
+
+	bundles, err := ioutil.ReadDir(archiveDir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+
+	files := readBundle(t, filepath.Join(archiveDir, bundles[0].Name()))
+	require.Len(t, files, 6)
+	require.Equal(t, "log of old", files[util.MakeFileName("old")])
+
+	task := &Task{}
+	require.NoError(t, json.Unmarshal([]byte(files["failed-old.json"]), task))
+	require.Equal(t, statuses.Error, task.Status)
+
+	// nothing is left to remove
+	removed, err = j.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, removed)
+}
+
+func TestJanitorSweepDisabled(t *testing.T) {
+	finishedAt := time.Now().Add(-time.Hour * 24 * 365)
+	data, err := json.Marshal(&Task{ID: "old", Status: statuses.Success, FinishedAt: &finishedAt})
+	require.NoError(t, err)
+
+	repository := memory.NewInMemoryRepository()
+	require.NoError(t, repository.Put(context.Background(), Prefix, "old", data))
+
+	removed, err := NewJanitor(repository, repository, "", RetentionPolicy{}, nil).Sweep(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, removed)
+
+	_, err = repository.Get(context.Background(), Prefix, "old")
+	require.NoError(t, err)
+}
+
+func contains(ids []string, id string) bool {
+	for _, s := range ids {
+		if s == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestJanitorArchiveEncrypted(t *testing.T) {
+	keyring, err := encrypted.NewKeyring([]string{
+		"k1:" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("1", 32))),
+	})
+	require.NoError(t, err)
+
+	raw := memory.NewInMemoryRepository()
+	repository := encrypted.NewRepository(raw, keyring)
+
+	finishedAt := time.Now().Add(-time.Hour * 48)
+	task := &Task{
+		ID:         "old",
+		Status:     statuses.Success,
+		FinishedAt: &finishedAt,
+		Config: &steps.Config{
+			AWSConfig: steps.AWSConfig{Secret: "aws-secret-key"},
+		},
+	}
+	data, err := json.Marshal(task)
+	require.NoError(t, err)
+	require.NoError(t, repository.Put(context.Background(), Prefix, task.ID, data))
+
+	archiveDir, err := ioutil.TempDir("", "janitor")
+	require.NoError(t, err)
+	defer os.RemoveAll(archiveDir)
+
+	removed, err := NewJanitor(repository, raw, "", RetentionPolicy{
+		MaxAge:     24 * time.Hour,
+		ArchiveDir: archiveDir,
+	}, nil).Sweep(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"old"}, removed)
+
+	bundles, err := ioutil.ReadDir(archiveDir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+
+	// records are archived as they are stored
+	files := readBundle(t, filepath.Join(archiveDir, bundles[0].Name()))
+	require.Len(t, files, 1)
+	require.NotContains(t, files["old.json"], "aws-secret-key")
+}
+
+func readBundle(t *testing.T, name string) map[string]string {
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(data)
+	}
+
+	return files
+}
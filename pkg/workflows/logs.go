@@ -0,0 +1,202 @@
+package workflows
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/util"
+)
+
+// LogFormat is a format of task logs
+type LogFormat string
+
+const (
+	// LogFormatText keeps output of steps as it is
+	LogFormatText LogFormat = "txt"
+	// LogFormatJSON writes every line of task log as a LogEntry
+	LogFormatJSON LogFormat = "json"
+
+	// maxLogLineSize limits lines of task log read by StepLogs
+	maxLogLineSize = 1 << 20
+)
+
+var logFormat = LogFormatText
+
+// LogEntry is a line of task log written in JSON format
+type LogEntry struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Step  string    `json:"step,omitempty"`
+	Msg   string    `json:"msg"`
+}
+
+// ParseLogFormat checks that format is one of known task log formats
+func ParseLogFormat(format string) (LogFormat, error) {
+	switch LogFormat(format) {
+	case LogFormatText, LogFormatJSON:
+		return LogFormat(format), nil
+	}
+
+	return "", errors.Errorf("unknown task log format %q", format)
+}
+
+// SetLogFormat sets format of logs written by tasks started afterwards
+func SetLogFormat(format LogFormat) {
+	m.Lock()
+	defer m.Unlock()
+	logFormat = format
+}
+
+func getLogFormat() LogFormat {
+	m.RLock()
+	defer m.RUnlock()
+	return logFormat
+}
+
+// taskLogger returns logger of messages written by workflow engine, every
+// message is tagged with the step it is about.
+func taskLogger(out io.Writer, format LogFormat) *logrus.Logger {
+	log := util.GetLogger(out)
+	if format == LogFormatJSON {
+		log.Formatter = &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		}
+	}
+
+	return log
+}
+
+// stepOutput returns writer given to the step, output of steps is written
+// to task log as it is unless logs are written in JSON format.
+func stepOutput(out io.Writer, format LogFormat, stepName string) io.Writer {
+	if format != LogFormatJSON {
+		return out
+	}
+
+	return &stepLogWriter{
+		step: stepName,
+		out:  out,
+	}
+}
+
+// flushOutput writes the last line of step output that has no line break
+func flushOutput(out io.Writer) {
+	if w, ok := out.(*stepLogWriter); ok {
+		if err := w.Flush(); err != nil {
+			logrus.Errorf("flush output of step %s: %v", w.step, err)
+		}
+	}
+}
+
+// stepLogWriter turns every line of step output into a LogEntry
+type stepLogWriter struct {
+	m    sync.Mutex
+	step string
+	out  io.Writer
+	buf  []byte
+}
+
+func (w *stepLogWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if err := w.writeEntry(w.buf[:i]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *stepLogWriter) Flush() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	err := w.writeEntry(w.buf)
+	w.buf = nil
+
+	return err
+}
+
+func (w *stepLogWriter) writeEntry(line []byte) error {
+	msg := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(msg) == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(LogEntry{
+		Time:  time.Now(),
+		Level: logrus.InfoLevel.String(),
+		Step:  w.step,
+		Msg:   msg,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the whole entry is written at once, so that entries
+	// of steps running at the same time do not mix
+	_, err = w.out.Write(append(data, '\n'))
+	return err
+}
+
+// StepLogs returns lines of task log that belong to the step. Lines written
+// in JSON format are matched by their step, output of steps in text format
+// is not tagged, so text lines written from start of the step till its end
+// are returned and they may contain output of steps run at the same time.
+func StepLogs(r io.Reader, stepName string) ([]string, error) {
+	var (
+		lines   []string
+		running bool
+		marker  = "[" + stepName + "] - "
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLogLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		entry := LogEntry{}
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &entry) == nil {
+			if entry.Step == stepName {
+				lines = append(lines, line)
+			}
+			continue
+		}
+
+		i := strings.Index(line, marker)
+		if i < 0 {
+			if running {
+				lines = append(lines, line)
+			}
+			continue
+		}
+
+		lines = append(lines, line)
+		switch event := line[i+len(marker):]; {
+		case strings.HasPrefix(event, "started"):
+			running = true
+		case strings.HasPrefix(event, "success"), strings.HasPrefix(event, "failed"):
+			running = false
+		}
+	}
+
+	return lines, scanner.Err()
+}
@@ -0,0 +1,134 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+func TestParseLogFormat(t *testing.T) {
+	for _, format := range []LogFormat{LogFormatText, LogFormatJSON} {
+		f, err := ParseLogFormat(string(format))
+		require.NoError(t, err)
+		require.Equal(t, format, f)
+	}
+
+	_, err := ParseLogFormat("xml")
+	require.Error(t, err)
+}
+
+func TestStepLogWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := stepOutput(buf, LogFormatJSON, "docker")
+
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line\r\n\nlast"))
+	require.NoError(t, err)
+	flushOutput(w)
+
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := LogEntry{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		require.Equal(t, "docker", entry.Step)
+		require.Equal(t, "info", entry.Level)
+		require.False(t, entry.Time.IsZero())
+		msgs = append(msgs, entry.Msg)
+	}
+
+	require.Equal(t, []string{"first line", "second line", "last"}, msgs)
+	require.Equal(t, buf, stepOutput(buf, LogFormatText, "docker"))
+}
+
+func TestStepLogs(t *testing.T) {
+	testCases := []struct {
+		description string
+		log         string
+		expected    []string
+	}{
+		{
+			description: "json",
+			log: `{"level":"info","msg":"[docker] - started","step":"docker","time":"2019-05-01T10:00:00Z"}
+{"level":"info","msg":"install","step":"docker","time":"2019-05-01T10:00:01Z"}
+{"level":"info","msg":"download","step":"kubelet","time":"2019-05-01T10:00:01Z"}
+not a json line
+{"level":"info","msg":"[docker] - success","step":"docker","time":"2019-05-01T10:00:02Z"}`,
+			expected: []string{
+				`{"level":"info","msg":"[docker] - started","step":"docker","time":"2019-05-01T10:00:00Z"}`,
+				`{"level":"info","msg":"install","step":"docker","time":"2019-05-01T10:00:01Z"}`,
+				`{"level":"info","msg":"[docker] - success","step":"docker","time":"2019-05-01T10:00:02Z"}`,
+			},
+		},
+		{
+			description: "text",
+			log: `level=info msg="[ssh] - started"
+connected
+level=info msg="[ssh] - success"
+level=info msg="[docker] - started"
+install
+level=info msg="[docker] - attempt 1 of 2 failed: error, retry in 1s"
+install again
+level=info msg="[docker] - success"
+level=info msg="[kubelet] - started"
+level=info msg="[docker] - rolling back"`,
+			expected: []string{
+				`level=info msg="[docker] - started"`,
+				"install",
+				`level=info msg="[docker] - attempt 1 of 2 failed: error, retry in 1s"`,
+				"install again",
+				`level=info msg="[docker] - success"`,
+				`level=info msg="[docker] - rolling back"`,
+			},
+		},
+		{
+			description: "unknown step",
+			log:         `level=info msg="[ssh] - started"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		lines, err := StepLogs(strings.NewReader(testCase.log), "docker")
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, lines)
+	}
+}
+
+func TestTaskRunJSONLogs(t *testing.T) {
+	SetLogFormat(LogFormatJSON)
+	defer SetLogFormat(LogFormatText)
+
+	wf := []steps.Step{
+		&MockStep{name: "step1", messages: []string{"hello from step1"}},
+		&MockStep{name: "step2", messages: []string{"hello from step2"}},
+	}
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", &MockRepository{
+		storage: make(map[string][]byte),
+	})
+	require.NoError(t, err)
+
+	buffer := &bufferCloser{}
+	require.NoError(t, <-task.Run(context.Background(), steps.Config{}, buffer))
+
+	lines, err := StepLogs(strings.NewReader(buffer.String()), "step2")
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+
+	var msgs []string
+	for _, line := range lines {
+		entry := LogEntry{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		msgs = append(msgs, entry.Msg)
+	}
+	require.Equal(t, []string{"[step2] - started", "hello from step2", "[step2] - success"}, msgs)
+}
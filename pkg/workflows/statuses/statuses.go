@@ -8,4 +8,12 @@ const (
 	Success   Status = "success"
 	Error     Status = "error"
 	Cancelled Status = "cancelled"
+	// TimedOut is set to steps that have not finished in time
+	TimedOut Status = "timed_out"
+
+	// Steps that have succeeded before failure of the task are rolled back
+	// in reverse order when the task is configured to roll back on failure
+	RollingBack    Status = "rolling_back"
+	RolledBack     Status = "rolled_back"
+	RollbackFailed Status = "rollback_failed"
 )
@@ -20,6 +20,7 @@ type Associater interface {
 
 type AssociateRouteTableStep struct {
 	getRouteTableSvc func(config steps.AWSConfig) (Associater, error)
+	disassociate     steps.Step
 }
 
 // InitAssociateRouteTable adds the step to the registry
@@ -40,6 +41,7 @@ func NewAssociateRouteTableStep(ec2fn GetEC2Fn) *AssociateRouteTableStep {
 
 			return ec2Client, nil
 		},
+		disassociate: NewDisassociateRouteTableStep(ec2fn),
 	}
 }
 
@@ -86,7 +88,17 @@ func (s *AssociateRouteTableStep) Run(ctx context.Context, w io.Writer, cfg *ste
 	return nil
 }
 
+// Rollback removes associations of route table with subnets made by the step
 func (s *AssociateRouteTableStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.disassociate == nil || len(cfg.AWSConfig.RouteTableAssociationIDs) == 0 {
+		return nil
+	}
+
+	if err := s.disassociate.Run(ctx, w, cfg); err != nil {
+		return errors.Wrap(err, "disassociate route table")
+	}
+
+	cfg.AWSConfig.RouteTableAssociationIDs = nil
 	return nil
 }
 
@@ -99,5 +111,5 @@ func (*AssociateRouteTableStep) Description() string {
 }
 
 func (*AssociateRouteTableStep) Depends() []string {
-	return []string{StepCreateSubnets}
+	return []string{StepCreateRouteTable, StepCreateSubnets}
 }
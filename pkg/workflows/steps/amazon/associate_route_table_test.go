@@ -62,9 +62,9 @@ func TestNewAssociateRouteTableStepError(t *testing.T) {
 func TestAssociateRouteTableStep_Depends(t *testing.T) {
 	step := &AssociateRouteTableStep{}
 
-	if deps := step.Depends(); len(deps) != 1 || deps[0] != StepCreateSubnets {
+	if deps := step.Depends(); len(deps) != 2 || deps[0] != StepCreateRouteTable || deps[1] != StepCreateSubnets {
 		t.Errorf("Wron dependencies expected %v actual %v",
-			[]string{StepCreateSubnets}, deps)
+			[]string{StepCreateRouteTable, StepCreateSubnets}, deps)
 	}
 }
 func TestAssociateRouteTableStep_Name(t *testing.T) {
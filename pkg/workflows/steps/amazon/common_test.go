@@ -1,11 +1,25 @@
 package amazon
 
 import (
+	"context"
+	"io"
 	"testing"
 
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
+// fakeDeleteStep stands for delete steps that are run by rollback of create steps
+type fakeDeleteStep struct {
+	steps.Step
+	called bool
+	err    error
+}
+
+func (s *fakeDeleteStep) Run(context.Context, io.Writer, *steps.Config) error {
+	s.called = true
+	return s.err
+}
+
 func TestGetEC2(t *testing.T) {
 	api, err := GetEC2(steps.AWSConfig{})
 
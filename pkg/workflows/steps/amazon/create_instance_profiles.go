@@ -166,7 +166,8 @@ func (s StepCreateInstanceProfiles) Run(ctx context.Context, w io.Writer, cfg *s
 }
 
 func (s StepCreateInstanceProfiles) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
-	// TODO: implement instance profile removal
+	// TODO: implement instance profile removal, profiles are
+	// shared by all clusters until they get cluster specific names.
 	return nil
 }
 
@@ -178,8 +179,9 @@ func (StepCreateInstanceProfiles) Description() string {
 	return "Create EC2 Instance master/node profiles"
 }
 
+// IAM instance profiles are not bound to the VPC and may be created in parallel with it
 func (StepCreateInstanceProfiles) Depends() []string {
-	return nil
+	return []string{}
 }
 
 func ensureIAMProfile(ctx context.Context, iamS iamiface.IAMAPI, prefix, role string) (string, error) {
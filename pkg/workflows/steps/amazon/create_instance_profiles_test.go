@@ -305,7 +305,7 @@ func TestInitCreateInstanceProfiles(t *testing.T) {
 func TestStepCreateInstanceProfiles_Depends(t *testing.T) {
 	step := &StepCreateInstanceProfiles{}
 
-	if deps := step.Depends(); deps != nil {
+	if deps := step.Depends(); deps == nil || len(deps) != 0 {
 		t.Errorf("Unexpected deps value %v", deps)
 	}
 }
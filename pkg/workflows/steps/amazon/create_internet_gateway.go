@@ -19,6 +19,7 @@ const StepCreateInternetGateway = "create_internet_gateway"
 
 type CreateInternetGatewayStep struct {
 	getIGWService func(cfg steps.AWSConfig) (InternetGatewayCreater, error)
+	deleteGateway steps.Step
 }
 
 type InternetGatewayCreater interface {
@@ -45,6 +46,7 @@ func NewCreateInternetGatewayStep(ec2fn GetEC2Fn) *CreateInternetGatewayStep {
 
 			return ec2Client, nil
 		},
+		deleteGateway: NewDeleteInernetGateway(ec2fn),
 	}
 }
 
@@ -71,6 +73,7 @@ func (s *CreateInternetGatewayStep) Run(ctx context.Context, w io.Writer, cfg *s
 		}
 
 		cfg.AWSConfig.InternetGatewayID = *resp.InternetGateway.InternetGatewayId
+		cfg.AWSConfig.InternetGatewayCreated = true
 
 		// Tag gateway
 		ec2Tags := []*ec2.Tag{
@@ -117,7 +120,18 @@ func (s *CreateInternetGatewayStep) Run(ctx context.Context, w io.Writer, cfg *s
 	return nil
 }
 
+// Rollback detaches and deletes internet gateway created by the step
 func (s *CreateInternetGatewayStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.deleteGateway == nil || !cfg.AWSConfig.InternetGatewayCreated {
+		return nil
+	}
+
+	if err := s.deleteGateway.Run(ctx, w, cfg); err != nil {
+		return errors.Wrapf(err, "delete internet gateway %s", cfg.AWSConfig.InternetGatewayID)
+	}
+
+	cfg.AWSConfig.InternetGatewayID = ""
+	cfg.AWSConfig.InternetGatewayCreated = false
 	return nil
 }
 
@@ -130,5 +144,5 @@ func (*CreateInternetGatewayStep) Description() string {
 }
 
 func (*CreateInternetGatewayStep) Depends() []string {
-	return nil
+	return []string{StepCreateVPC}
 }
@@ -174,13 +174,35 @@ func TestCreateInternetGateway_Rollback(t *testing.T) {
 	if err := step.Rollback(context.Background(), nil, nil); err != nil {
 		t.Errorf("Unexpected error %v while rolling back", err)
 	}
+
+	deleteGateway := &fakeDeleteStep{}
+	step = &CreateInternetGatewayStep{deleteGateway: deleteGateway}
+	cfg := &steps.Config{
+		AWSConfig: steps.AWSConfig{
+			InternetGatewayID: "igw-1",
+		},
+	}
+
+	if err := step.Rollback(context.Background(), nil, cfg); err != nil || deleteGateway.called {
+		t.Errorf("Internet gateway from profile must be kept, error %v", err)
+	}
+
+	cfg.AWSConfig.InternetGatewayCreated = true
+	if err := step.Rollback(context.Background(), nil, cfg); err != nil || !deleteGateway.called {
+		t.Errorf("Created internet gateway must be deleted, error %v", err)
+	}
+
+	if cfg.AWSConfig.InternetGatewayID != "" {
+		t.Errorf("Deleted internet gateway must be removed from config")
+	}
 }
 
 func TestCreateInternetGatewayStep_Depends(t *testing.T) {
 	step := &CreateInternetGatewayStep{}
 
-	if deps := step.Depends(); deps != nil {
-		t.Error("Dependencies must be nil")
+	if deps := step.Depends(); len(deps) != 1 || deps[0] != StepCreateVPC {
+		t.Errorf("Wrong dependencies %v expected %v",
+			deps, []string{StepCreateVPC})
 	}
 }
 
@@ -34,6 +34,7 @@ type CreateLoadBalancerStep struct {
 	timeout                time.Duration
 	attemptCount           int
 	getLoadBalancerService func(cfg steps.AWSConfig) (LoadBalancerCreater, error)
+	deleteLoadBalancers    steps.Step
 }
 
 //InitCreateMachine adds the step to the registry
@@ -57,6 +58,7 @@ func NewCreateLoadBalancerStep(getELBFn GetELBFn) *CreateLoadBalancerStep {
 
 			return elbInstance, nil
 		},
+		deleteLoadBalancers: NewDeleteLoadBalancerStep(getELBFn),
 	}
 }
 
@@ -245,6 +247,18 @@ func (s *CreateLoadBalancerStep) Depends() []string {
 	return []string{StepCreateSubnets, StepCreateSecurityGroups}
 }
 
+// Rollback deletes external and internal load balancers of the cluster
 func (s *CreateLoadBalancerStep) Rollback(ctx context.Context, out io.Writer, cfg *steps.Config) error {
+	if s.deleteLoadBalancers == nil || cfg.AWSConfig.ExternalLoadBalancerName == "" &&
+		cfg.AWSConfig.InternalLoadBalancerName == "" {
+		return nil
+	}
+
+	if err := s.deleteLoadBalancers.Run(ctx, out, cfg); err != nil {
+		return errors.Wrap(err, "delete load balancers")
+	}
+
+	cfg.AWSConfig.ExternalLoadBalancerName = ""
+	cfg.AWSConfig.InternalLoadBalancerName = ""
 	return nil
 }
@@ -225,6 +225,10 @@ func (s *StepCreateInstance) Run(ctx context.Context, w io.Writer, cfg *steps.Co
 		cfg.Node.PublicIp = *i.PublicIpAddress
 		cfg.Node.PrivateIp = *i.PrivateIpAddress
 		log.Infof("[%s] - found public ip - %s for node %s", s.Name(), cfg.Node.PublicIp, nodeName)
+	} else if i := findInstanceWithPrivateAddr(out.Reservations); i != nil && len(cfg.Kube.SSHConfig.Bastions) > 0 {
+		// machines in private subnets are reached through bastions
+		cfg.Node.PrivateIp = *i.PrivateIpAddress
+		log.Infof("[%s] - found private ip - %s for node %s", s.Name(), cfg.Node.PrivateIp, nodeName)
 	} else {
 		log.Errorf("[%s] - failed to find public IP address", s.Name())
 		cfg.Node.State = model.MachineStateError
@@ -266,6 +270,17 @@ func findInstanceWithPublicAddr(reservations []*ec2.Reservation) *ec2.Instance {
 	return nil
 }
 
+func findInstanceWithPrivateAddr(reservations []*ec2.Reservation) *ec2.Instance {
+	for _, r := range reservations {
+		for _, i := range r.Instances {
+			if i.PrivateIpAddress != nil {
+				return i
+			}
+		}
+	}
+	return nil
+}
+
 func (*StepCreateInstance) Name() string {
 	return StepNameCreateEC2Instance
 }
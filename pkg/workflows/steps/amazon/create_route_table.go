@@ -23,8 +23,9 @@ type Service interface {
 }
 
 type CreateRouteTableStep struct {
-	getService func(config steps.AWSConfig) (Service, error)
-	GetEC2     GetEC2Fn
+	getService       func(config steps.AWSConfig) (Service, error)
+	GetEC2           GetEC2Fn
+	deleteRouteTable steps.Step
 }
 
 // InitCreateRouteTable adds the step to the registry
@@ -45,6 +46,7 @@ func NewCreateRouteTableStep(ec2fn GetEC2Fn) *CreateRouteTableStep {
 
 			return ec2Client, nil
 		},
+		deleteRouteTable: NewDeleteRouteTableStep(ec2fn),
 	}
 }
 
@@ -75,6 +77,7 @@ func (s *CreateRouteTableStep) Run(ctx context.Context, w io.Writer, cfg *steps.
 	}
 
 	cfg.AWSConfig.RouteTableID = *createResp.RouteTable.RouteTableId
+	cfg.AWSConfig.RouteTableCreated = true
 	logrus.Infof("Create route table %s", cfg.AWSConfig.RouteTableID)
 
 	// Tag route table
@@ -121,7 +124,18 @@ func (s *CreateRouteTableStep) Run(ctx context.Context, w io.Writer, cfg *steps.
 	return nil
 }
 
+// Rollback deletes route table created by the step
 func (s *CreateRouteTableStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.deleteRouteTable == nil || !cfg.AWSConfig.RouteTableCreated {
+		return nil
+	}
+
+	if err := s.deleteRouteTable.Run(ctx, w, cfg); err != nil {
+		return errors.Wrapf(err, "delete route table %s", cfg.AWSConfig.RouteTableID)
+	}
+
+	cfg.AWSConfig.RouteTableID = ""
+	cfg.AWSConfig.RouteTableCreated = false
 	return nil
 }
 
@@ -134,5 +148,5 @@ func (*CreateRouteTableStep) Description() string {
 }
 
 func (*CreateRouteTableStep) Depends() []string {
-	return []string{StepCreateInternetGateway}
+	return []string{StepCreateVPC, StepCreateInternetGateway}
 }
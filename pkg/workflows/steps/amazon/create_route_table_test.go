@@ -210,9 +210,9 @@ func TestCreateRouteTableStep_Description(t *testing.T) {
 func TestCreateRouteTableStep_Depends(t *testing.T) {
 	step := NewCreateRouteTableStep(GetEC2)
 
-	if deps := step.Depends(); len(deps) != 1 || deps[0] != StepCreateInternetGateway {
+	if deps := step.Depends(); len(deps) != 2 || deps[0] != StepCreateVPC || deps[1] != StepCreateInternetGateway {
 		t.Errorf("Wrong dependencies %v expected %v",
-			deps, []string{StepCreateInternetGateway})
+			deps, []string{StepCreateVPC, StepCreateInternetGateway})
 	}
 }
 
@@ -25,6 +25,7 @@ type secGroupService interface {
 type CreateSecurityGroupsStep struct {
 	getSvc         func(config steps.AWSConfig) (secGroupService, error)
 	findOutboundIP func() (string, error)
+	deleteGroups   steps.Step
 }
 
 func NewCreateSecurityGroupsStep(fn GetEC2Fn) *CreateSecurityGroupsStep {
@@ -38,6 +39,7 @@ func NewCreateSecurityGroupsStep(fn GetEC2Fn) *CreateSecurityGroupsStep {
 			return EC2, nil
 		},
 		findOutboundIP: FindExternalIP,
+		deleteGroups:   NewDeleteSecurityGroupService(fn),
 	}
 }
 
@@ -58,6 +60,11 @@ func (s *CreateSecurityGroupsStep) Run(ctx context.Context, w io.Writer, cfg *st
 
 	logrus.Debugf("Create security groups for VPC %s",
 		cfg.AWSConfig.VPCID)
+	// groups are removed by rollback only when both of them are created
+	if cfg.AWSConfig.MastersSecurityGroupID == "" && cfg.AWSConfig.NodesSecurityGroupID == "" {
+		cfg.AWSConfig.SecurityGroupsCreated = true
+	}
+
 	if cfg.AWSConfig.MastersSecurityGroupID == "" {
 		groupName := fmt.Sprintf("%s-masters-secgroup", cfg.ClusterID)
 
@@ -230,9 +237,21 @@ func (*CreateSecurityGroupsStep) Description() string {
 }
 
 func (*CreateSecurityGroupsStep) Depends() []string {
-	return nil
+	return []string{StepCreateVPC}
 }
 
-func (*CreateSecurityGroupsStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes security groups created by the step
+func (s *CreateSecurityGroupsStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.deleteGroups == nil || !cfg.AWSConfig.SecurityGroupsCreated {
+		return nil
+	}
+
+	if err := s.deleteGroups.Run(ctx, w, cfg); err != nil {
+		return errors.Wrap(err, "delete security groups")
+	}
+
+	cfg.AWSConfig.MastersSecurityGroupID = ""
+	cfg.AWSConfig.NodesSecurityGroupID = ""
+	cfg.AWSConfig.SecurityGroupsCreated = false
 	return nil
 }
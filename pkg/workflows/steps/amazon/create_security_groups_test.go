@@ -292,8 +292,8 @@ func TestNewCreateSecurityGroupsStepErr(t *testing.T) {
 func TestCreateSecurityGroupsStep_Depends(t *testing.T) {
 	s := &CreateSecurityGroupsStep{}
 
-	if deps := s.Depends(); deps != nil {
-		t.Errorf("Deps must be nil")
+	if deps := s.Depends(); len(deps) != 1 || deps[0] != StepCreateVPC {
+		t.Errorf("Deps must be %v actual %v", []string{StepCreateVPC}, deps)
 	}
 }
 
@@ -312,6 +312,30 @@ func TestCreateSecurityGroupsStep_Rollback(t *testing.T) {
 	if err := s.Rollback(context.Background(), &bytes.Buffer{}, &steps.Config{}); err != nil {
 		t.Errorf("Unexpected value of err %v", err)
 	}
+
+	for _, created := range []bool{false, true} {
+		deleteGroups := &fakeDeleteStep{}
+		s := &CreateSecurityGroupsStep{deleteGroups: deleteGroups}
+		cfg := &steps.Config{
+			AWSConfig: steps.AWSConfig{
+				MastersSecurityGroupID: "sg-1",
+				NodesSecurityGroupID:   "sg-2",
+				SecurityGroupsCreated:  created,
+			},
+		}
+
+		if err := s.Rollback(context.Background(), &bytes.Buffer{}, cfg); err != nil {
+			t.Errorf("Unexpected value of err %v", err)
+		}
+
+		if deleteGroups.called != created {
+			t.Errorf("Groups created %v must be deleted %v", created, deleteGroups.called)
+		}
+
+		if created && (cfg.AWSConfig.MastersSecurityGroupID != "" || cfg.AWSConfig.NodesSecurityGroupID != "") {
+			t.Errorf("Deleted security groups must be removed from config")
+		}
+	}
 }
 
 func TestCreateSecurityGroupsStep_Description(t *testing.T) {
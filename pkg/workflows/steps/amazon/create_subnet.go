@@ -35,6 +35,7 @@ type CreateSubnetsStep struct {
 	accountGetter     accountGetter
 	getSvc            func(steps.AWSConfig) (subnetSvc, error)
 	zoneGetterFactory func(context.Context, accountGetter, *steps.Config) (account.ZonesGetter, error)
+	deleteSubnets     steps.Step
 }
 
 func NewCreateSubnetStep(fn GetEC2Fn, getter accountGetter) *CreateSubnetsStep {
@@ -59,10 +60,14 @@ func NewCreateSubnetStep(fn GetEC2Fn, getter accountGetter) *CreateSubnetsStep {
 				return nil, errors.Wrapf(err, "Get cloud account")
 			}
 
-			zoneGetter, err := account.NewZonesGetter(acc, cfg)
+			zoneGetter, err := account.NewAWSFinder(acc, cfg)
+			if err != nil {
+				return nil, err
+			}
 
-			return zoneGetter, err
+			return zoneGetter, nil
 		},
+		deleteSubnets: NewDeleteSubnets(fn),
 	}
 }
 
@@ -134,7 +139,7 @@ func (s *CreateSubnetsStep) Run(ctx context.Context, w io.Writer, cfg *steps.Con
 		out, err := svc.CreateSubnetWithContext(ctx, input)
 		if err != nil {
 			logrus.Debugf("Create subnet cause error %s", err.Error())
-			return errors.Wrap(ErrCreateSubnet, err.Error())
+			return wrapAPIError(ErrCreateSubnet, err)
 		}
 
 		modifyReq := &ec2.ModifySubnetAttributeInput{
@@ -148,7 +153,7 @@ func (s *CreateSubnetsStep) Run(ctx context.Context, w io.Writer, cfg *steps.Con
 
 		if err != nil {
 			logrus.Debugf("Modify subnet cause error %s", err.Error())
-			return errors.Wrap(ErrCreateSubnet, err.Error())
+			return wrapAPIError(ErrCreateSubnet, err)
 		}
 
 		// Store subnet in subnets map
@@ -167,9 +172,19 @@ func (*CreateSubnetsStep) Description() string {
 }
 
 func (*CreateSubnetsStep) Depends() []string {
-	return nil
+	return []string{StepCreateVPC}
 }
 
-func (*CreateSubnetsStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes subnets created by the step
+func (s *CreateSubnetsStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.deleteSubnets == nil || len(cfg.AWSConfig.Subnets) == 0 {
+		return nil
+	}
+
+	if err := s.deleteSubnets.Run(ctx, w, cfg); err != nil {
+		return errors.Wrap(err, "delete subnets")
+	}
+
+	cfg.AWSConfig.Subnets = nil
 	return nil
 }
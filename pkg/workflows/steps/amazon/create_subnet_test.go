@@ -17,6 +17,7 @@ import (
 	"github.com/supergiant/control/pkg/clouds"
 	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
@@ -187,7 +188,21 @@ func TestInitCreateSubnet(t *testing.T) {
 	}
 }
 
+type awsProvider struct {
+	providers.Provider
+}
+
+func (awsProvider) Name() clouds.Name {
+	return clouds.AWS
+}
+
+func (awsProvider) BindCredentials(credentials map[string]string, config *steps.Config) error {
+	return nil
+}
+
 func TestNewCreateSubnetStep2(t *testing.T) {
+	providers.Register(awsProvider{})
+
 	testCases := []struct {
 		description string
 		getAccErr   error
@@ -310,8 +325,8 @@ func TestCreateSubnetsStep_Description(t *testing.T) {
 func TestCreateSubnetsStep_Depends(t *testing.T) {
 	step := &CreateSubnetsStep{}
 
-	if deps := step.Depends(); deps != nil {
-		t.Errorf("%s dependencies must be nil", StepCreateSubnets)
+	if deps := step.Depends(); len(deps) != 1 || deps[0] != StepCreateVPC {
+		t.Errorf("%s dependencies must be %v", StepCreateSubnets, []string{StepCreateVPC})
 	}
 }
 
@@ -18,11 +18,14 @@ const StepCreateVPC = "aws_create_vpc"
 //CreateVPCStep represents creation of an virtual private cloud in AWS
 type CreateVPCStep struct {
 	GetEC2 GetEC2Fn
+
+	deleteVPC steps.Step
 }
 
 func NewCreateVPCStep(fn GetEC2Fn) *CreateVPCStep {
 	return &CreateVPCStep{
-		GetEC2: fn,
+		GetEC2:    fn,
+		deleteVPC: NewDeleteVPC(fn),
 	}
 }
 
@@ -48,9 +51,10 @@ func (c *CreateVPCStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config)
 		}
 		out, err := EC2.CreateVpcWithContext(ctx, input)
 		if err != nil {
-			return errors.Wrap(ErrCreateVPC, err.Error())
+			return wrapAPIError(ErrCreateVPC, err)
 		}
 		cfg.AWSConfig.VPCID = *out.Vpc.VpcId
+		cfg.AWSConfig.VPCCreated = true
 
 		vpcattr := &ec2.ModifyVpcAttributeInput{
 			EnableDnsHostnames: &ec2.AttributeBooleanValue{
@@ -60,7 +64,7 @@ func (c *CreateVPCStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config)
 		}
 		_, err = EC2.ModifyVpcAttributeWithContext(ctx, vpcattr)
 		if err != nil {
-			return errors.Wrap(ErrCreateVPC, err.Error())
+			return wrapAPIError(ErrCreateVPC, err)
 		}
 
 		desc := &ec2.DescribeVpcsInput{
@@ -88,7 +92,7 @@ func (c *CreateVPCStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config)
 		})
 		if err != nil {
 			log.Errorf("[%s] - failed to read VPC data", c.Name())
-			return errors.Wrap(ErrReadVPC, err.Error())
+			return wrapAPIError(ErrReadVPC, err)
 		}
 
 		var defaultVPCID string
@@ -116,10 +120,22 @@ func (*CreateVPCStep) Description() string {
 	return "create a vpc in aws or reuse existing one"
 }
 
+// VPC does not need any other resource, so the step is started right away
 func (*CreateVPCStep) Depends() []string {
-	return nil
+	return []string{}
 }
 
-func (*CreateVPCStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes VPC created by the step, VPC given in profile is kept
+func (c *CreateVPCStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if c.deleteVPC == nil || !cfg.AWSConfig.VPCCreated {
+		return nil
+	}
+
+	if err := c.deleteVPC.Run(ctx, w, cfg); err != nil {
+		return errors.Wrapf(err, "delete vpc %s", cfg.AWSConfig.VPCID)
+	}
+
+	cfg.AWSConfig.VPCID = ""
+	cfg.AWSConfig.VPCCreated = false
 	return nil
 }
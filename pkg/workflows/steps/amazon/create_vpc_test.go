@@ -228,8 +228,8 @@ func TestNewCreateVPCStepErr(t *testing.T) {
 func TestCreateVPCStep_Depends(t *testing.T) {
 	s := &CreateVPCStep{}
 
-	if deps := s.Depends(); deps != nil {
-		t.Errorf("deps must not be nil")
+	if deps := s.Depends(); deps == nil || len(deps) != 0 {
+		t.Errorf("deps must be empty actual %v", deps)
 	}
 }
 
@@ -259,4 +259,41 @@ func TestCreateVPCStep_Rollback(t *testing.T) {
 		&steps.Config{}); err != nil {
 		t.Errorf("Unexpected error while rolback")
 	}
+
+	for _, tc := range []struct {
+		name       string
+		created    bool
+		deleteErr  error
+		expectedID string
+	}{
+		{
+			name:       "vpc from profile",
+			expectedID: "vpc-1",
+		},
+		{
+			name:    "created vpc",
+			created: true,
+		},
+		{
+			name:       "delete error",
+			created:    true,
+			deleteErr:  errors.New("delete"),
+			expectedID: "vpc-1",
+		},
+	} {
+		deleteVPC := &fakeDeleteStep{err: tc.deleteErr}
+		s := &CreateVPCStep{deleteVPC: deleteVPC}
+		cfg := &steps.Config{
+			AWSConfig: steps.AWSConfig{
+				VPCID:      "vpc-1",
+				VPCCreated: tc.created,
+			},
+		}
+
+		err := s.Rollback(context.Background(), &bytes.Buffer{}, cfg)
+
+		require.Equal(t, tc.deleteErr, errors.Cause(err), tc.name)
+		require.Equal(t, tc.created, deleteVPC.called, tc.name)
+		require.Equal(t, tc.expectedID, cfg.AWSConfig.VPCID, tc.name)
+	}
 }
@@ -25,8 +25,9 @@ type keyImporter interface {
 // KeyPairStep represents creation of keypair in aws
 // since there is hard cap on keypairs per account supergiant will create one per cluster
 type KeyPairStep struct {
-	GetEC2 GetEC2Fn
-	getSvc func(steps.AWSConfig) (keyImporter, error)
+	GetEC2        GetEC2Fn
+	getSvc        func(steps.AWSConfig) (keyImporter, error)
+	deleteKeyPair steps.Step
 }
 
 //InitImportKeyPair add the step to the registry
@@ -45,6 +46,7 @@ func NewImportKeyPairStep(fn GetEC2Fn) *KeyPairStep {
 
 			return EC2, nil
 		},
+		deleteKeyPair: NewDeleteKeyPairStep(fn),
 	}
 }
 
@@ -65,22 +67,17 @@ func (s *KeyPairStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config) e
 		return errors.New("Cluster ID is too short")
 	}
 
-	// NOTE(stgleb): Add unique part to key pair name that allows to
-	// create cluster with the same name and avoid name collision of key pairs.
-	bootstrapKeyPairName := util.MakeKeyName(fmt.Sprintf("%s-%s",
-		cfg.ClusterName,
-		cfg.ClusterID[:4]),
-		false)
+	keyPairName := bootstrapKeyPairName(cfg)
 	log.Infof("[%s] - importing cluster bootstrap key as keypair %s",
-		s.Name(), bootstrapKeyPairName)
+		s.Name(), keyPairName)
 	req := &ec2.ImportKeyPairInput{
-		KeyName:           &bootstrapKeyPairName,
+		KeyName:           &keyPairName,
 		PublicKeyMaterial: []byte(cfg.Kube.SSHConfig.BootstrapPublicKey),
 	}
 
 	output, err := svc.ImportKeyPairWithContext(ctx, req)
 	if err != nil {
-		return errors.Wrap(ErrImportKeyPair, err.Error())
+		return wrapAPIError(ErrImportKeyPair, err)
 	}
 
 	cfg.AWSConfig.KeyPairName = *output.KeyName
@@ -99,13 +96,24 @@ func (s *KeyPairStep) Run(ctx context.Context, w io.Writer, cfg *steps.Config) e
 	if err != nil {
 		logrus.Debugf("WaitUntilKeyPairExists caused %s", err.Error())
 		return errors.Wrap(err, fmt.Sprintf("wait until key pair found %s",
-			bootstrapKeyPairName))
+			keyPairName))
 	}
 
 	return nil
 }
 
+// Rollback deletes key pair imported by the step, key pair given in profile is kept
 func (s *KeyPairStep) Rollback(ctx context.Context, w io.Writer, cfg *steps.Config) error {
+	if s.deleteKeyPair == nil || len(cfg.ClusterID) < 4 ||
+		cfg.AWSConfig.KeyPairName != bootstrapKeyPairName(cfg) {
+		return nil
+	}
+
+	if err := s.deleteKeyPair.Run(ctx, w, cfg); err != nil {
+		return errors.Wrapf(err, "delete key pair %s", cfg.AWSConfig.KeyPairName)
+	}
+
+	cfg.AWSConfig.KeyPairName = ""
 	return nil
 }
 
@@ -117,6 +125,16 @@ func (*KeyPairStep) Description() string {
 	return "If no keypair is present in config, creates a new keypair"
 }
 
+// Key pair is imported independently of network resources
 func (*KeyPairStep) Depends() []string {
-	return nil
+	return []string{}
+}
+
+// NOTE(stgleb): Add unique part to key pair name that allows to
+// create cluster with the same name and avoid name collision of key pairs.
+func bootstrapKeyPairName(cfg *steps.Config) string {
+	return util.MakeKeyName(fmt.Sprintf("%s-%s",
+		cfg.ClusterName,
+		cfg.ClusterID[:4]),
+		false)
 }
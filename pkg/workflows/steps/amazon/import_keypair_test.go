@@ -180,8 +180,8 @@ func TestNewImportKeyPairStepErr(t *testing.T) {
 func TestKeyPairStep_Depends(t *testing.T) {
 	s := &KeyPairStep{}
 
-	if deps := s.Depends(); deps != nil {
-		t.Errorf("dependencies must be nil")
+	if deps := s.Depends(); deps == nil || len(deps) != 0 {
+		t.Errorf("dependencies must be empty actual %v", deps)
 	}
 }
 
@@ -201,6 +201,25 @@ func TestKeyPairStep_Rollback(t *testing.T) {
 	if err := s.Rollback(context.Background(), &bytes.Buffer{}, &steps.Config{}); err != nil {
 		t.Errorf("Unexpected error when rollback %v", err)
 	}
+
+	deleteKeyPair := &fakeDeleteStep{}
+	s = &KeyPairStep{deleteKeyPair: deleteKeyPair}
+	cfg := &steps.Config{
+		ClusterID:   "1234abcd",
+		ClusterName: "test",
+		AWSConfig: steps.AWSConfig{
+			KeyPairName: "profile-key",
+		},
+	}
+
+	if err := s.Rollback(context.Background(), &bytes.Buffer{}, cfg); err != nil || deleteKeyPair.called {
+		t.Errorf("Key pair from profile must be kept, error %v", err)
+	}
+
+	cfg.AWSConfig.KeyPairName = bootstrapKeyPairName(cfg)
+	if err := s.Rollback(context.Background(), &bytes.Buffer{}, cfg); err != nil || !deleteKeyPair.called {
+		t.Errorf("Imported key pair must be deleted, error %v", err)
+	}
 }
 
 func TestKeyPairStep_Name(t *testing.T) {
@@ -0,0 +1,66 @@
+package amazon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// RetryPolicy is used by workflows for steps that call AWS API,
+// only requests rejected because of rate limits are retried.
+var RetryPolicy = steps.RetryPolicy{
+	Attempts:   5,
+	Backoff:    time.Second * 2,
+	MaxBackoff: time.Second * 30,
+	Multiplier: 2,
+	Retryable:  IsThrottled,
+}
+
+// apiError is an error of a step caused by AWS API. errors.Cause returns
+// the error of the step, while IsThrottled still finds the error of the API.
+type apiError struct {
+	err    error
+	apiErr error
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%v: %v", e.apiErr, e.err)
+}
+
+func (e *apiError) Cause() error {
+	return e.err
+}
+
+// wrapAPIError wraps error returned by AWS API into the error of the step
+func wrapAPIError(err, apiErr error) error {
+	return &apiError{
+		err:    err,
+		apiErr: apiErr,
+	}
+}
+
+// IsThrottled reports whether AWS request has been throttled or failed to
+// reach the API, codes of AWS errors found in the chain of causes are checked.
+func IsThrottled(err error) bool {
+	for cause := err; cause != nil; {
+		if e, ok := cause.(*apiError); ok {
+			return IsThrottled(e.apiErr)
+		}
+
+		if awsErr, ok := cause.(awserr.Error); ok {
+			return request.IsErrorThrottle(awsErr) || request.IsErrorRetryable(awsErr)
+		}
+
+		causer, ok := cause.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		cause = causer.Cause()
+	}
+
+	return false
+}
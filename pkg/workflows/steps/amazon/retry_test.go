@@ -0,0 +1,59 @@
+package amazon
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+)
+
+func TestIsThrottled(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{
+			err: nil,
+		},
+		{
+			err:      awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil),
+			expected: true,
+		},
+		{
+			err:      wrapAPIError(ErrCreateVPC, awserr.New("Throttling", "Rate exceeded", nil)),
+			expected: true,
+		},
+		{
+			err:      errors.Wrap(awserr.New("RequestError", "send request failed", nil), "create vpc"),
+			expected: true,
+		},
+		{
+			err: wrapAPIError(ErrCreateVPC, awserr.New("VpcLimitExceeded", "The maximum number of VPCs has been reached.", nil)),
+		},
+		{
+			// codes are not looked up in error text
+			err: errors.Wrap(ErrCreateVPC, "Throttling: Rate exceeded"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		if throttled := IsThrottled(testCase.err); throttled != testCase.expected {
+			t.Errorf("Wrong result for %v expected %v actual %v",
+				testCase.err, testCase.expected, throttled)
+		}
+	}
+}
+
+func TestWrapAPIError(t *testing.T) {
+	apiErr := awserr.New("Throttling", "Rate exceeded", nil)
+	err := wrapAPIError(ErrCreateVPC, apiErr)
+
+	if cause := errors.Cause(err); cause != ErrCreateVPC {
+		t.Errorf("Wrong cause expected %v actual %v", ErrCreateVPC, cause)
+	}
+
+	if msg := fmt.Sprintf("%v: %v", apiErr, ErrCreateVPC); err.Error() != msg {
+		t.Errorf("Wrong message expected %s actual %s", msg, err.Error())
+	}
+}
@@ -61,3 +61,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
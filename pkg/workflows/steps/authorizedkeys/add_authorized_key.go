@@ -74,3 +74,7 @@ func (*Step) Depends() []string {
 func (*Step) Rollback(context.Context, io.Writer, *steps.Config) error {
 	return nil
 }
+
+func (*Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
@@ -18,11 +19,13 @@ const CreateGroupStepName = "CreateResourceGroup"
 type GroupsClientFn func(a autorest.Authorizer, subscriptionID string) GroupsInterface
 
 type CreateGroupStep struct {
+	sdk            SDKInterface
 	groupsClientFn GroupsClientFn
 }
 
 func NewCreateGroupStep() *CreateGroupStep {
 	return &CreateGroupStep{
+		sdk:            NewSDK(),
 		groupsClientFn: GroupsClientFor,
 	}
 }
@@ -44,7 +47,34 @@ func (s *CreateGroupStep) Run(ctx context.Context, output io.Writer, config *ste
 	return errors.Wrap(err, "create resource group")
 }
 
-func (s *CreateGroupStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes resource group of the cluster, resources created
+// by the other steps belong to the group and are deleted along with it.
+func (s *CreateGroupStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if config == nil {
+		return errors.Wrap(sgerrors.ErrNilEntity, "config")
+	}
+	if s.groupsClientFn == nil {
+		return errors.Wrap(sgerrors.ErrNilEntity, "base client builder")
+	}
+
+	// authorizer is not saved, so it is lost when the task is restarted
+	if err := ensureAuthorizer(s.sdk, config); err != nil {
+		return errors.Wrap(err, "ensure authorization")
+	}
+
+	name := toResourceGroupName(config.ClusterID, config.ClusterName)
+	groupsClient := s.groupsClientFn(config.GetAzureAuthorizer(), config.AzureConfig.SubscriptionID)
+
+	logrus.Debugf("deleting %s azure resource group", name)
+	f, err := groupsClient.Delete(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "delete %s resource group", name)
+	}
+
+	if err = f.WaitForCompletionRef(ctx, s.sdk.RestClient(config.GetAzureAuthorizer(), config.AzureConfig.SubscriptionID)); err != nil {
+		return errors.Wrapf(err, "delete %s resource group", name)
+	}
+
 	return nil
 }
 
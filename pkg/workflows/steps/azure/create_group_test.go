@@ -18,7 +18,7 @@ func TestCreateGroupStep(t *testing.T) {
 	require.NotNil(t, s.groupsClientFn, "base client shouldn't be nil")
 
 	var nilStringSlice []string
-	require.Equal(t, nil, s.Rollback(context.Background(), nil, nil), "rollback not implemented")
+	require.Equal(t, sgerrors.ErrNilEntity, errors.Cause(s.Rollback(context.Background(), nil, nil)), "rollback nil config")
 	require.Equal(t, nilStringSlice, s.Depends(), "depends not implemented")
 	require.Equal(t, CreateGroupStepName, s.Name(), "check step name")
 	require.Equal(t, "Azure: Create ResourceGroup", s.Description(), "check description")
@@ -67,3 +67,42 @@ func TestCreateGroupStep_Run(t *testing.T) {
 		require.Equalf(t, tc.expectedErr, errors.Cause(err), "TC: %s", tc.name)
 	}
 }
+
+func TestCreateGroupStep_Rollback(t *testing.T) {
+	cfg := &steps.Config{}
+	cfg.SetAzureAuthorizer(autorest.NullAuthorizer{})
+
+	for _, tc := range []struct {
+		name        string
+		inp         *steps.Config
+		createGroup CreateGroupStep
+		expectedErr error
+	}{
+		{
+			name:        "nil steps config",
+			createGroup: CreateGroupStep{},
+			expectedErr: sgerrors.ErrNilEntity,
+		},
+		{
+			name:        "nil groups client builder",
+			inp:         cfg,
+			createGroup: CreateGroupStep{},
+			expectedErr: sgerrors.ErrNilEntity,
+		},
+		{
+			name: "delete group error",
+			inp:  cfg,
+			createGroup: CreateGroupStep{
+				sdk: NewSDK(),
+				groupsClientFn: func(a autorest.Authorizer, subscriptionID string) GroupsInterface {
+					return fakeGroupsClient{deleteErr: errFake}
+				},
+			},
+			expectedErr: errFake,
+		},
+	} {
+		err := tc.createGroup.Rollback(context.Background(), nil, tc.inp)
+
+		require.Equalf(t, tc.expectedErr, errors.Cause(err), "TC: %s", tc.name)
+	}
+}
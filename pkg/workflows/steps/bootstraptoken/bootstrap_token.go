@@ -83,3 +83,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
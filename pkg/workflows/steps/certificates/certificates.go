@@ -4,15 +4,22 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
 
+	"github.com/supergiant/control/pkg/runner"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
-const StepName = "certificates"
+const (
+	StepName = "certificates"
+
+	caCertPath = "/etc/kubernetes/pki/ca.crt"
+	caKeyPath  = "/etc/kubernetes/pki/ca.key"
+)
 
 type Step struct {
 	template *template.Template
@@ -46,6 +53,31 @@ func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		return errors.Wrap(err, "write certificates step")
 	}
 
+	if !config.IsBootstrap {
+		return nil
+	}
+
+	// Use CA generated on control side, it is uploaded to keep the key out of logs
+	ft, ok := config.Runner.(runner.FileTransfer)
+	if !ok {
+		return errors.Wrap(runner.ErrNoTransfer, "write certificates step")
+	}
+
+	files := []struct {
+		file    runner.File
+		content string
+	}{
+		{runner.File{Path: caCertPath, Mode: 0644}, config.CertificatesConfig.CACert},
+		{runner.File{Path: caKeyPath, Mode: 0600}, config.CertificatesConfig.CAKey},
+	}
+
+	for _, f := range files {
+		err := ft.Upload(ctx, f.file, strings.NewReader(f.content), int64(len(f.content)))
+		if err != nil {
+			return errors.Wrap(err, "write certificates step")
+		}
+	}
+
 	return nil
 }
 
@@ -60,3 +92,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/supergiant/control/pkg/pki"
 	"github.com/supergiant/control/pkg/profile"
 	"github.com/supergiant/control/pkg/runner"
+	"github.com/supergiant/control/pkg/runner/dry"
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -114,6 +115,55 @@ func TestWriteCertificates(t *testing.T) {
 	output.Reset()
 }
 
+func TestWriteCertificatesBootstrap(t *testing.T) {
+	err := templatemanager.Init("../../../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _ := templatemanager.GetTemplate(StepName)
+	if tpl == nil {
+		t.Fatal("template not found")
+	}
+
+	caPair, err := pki.NewCAPair(nil)
+	if err != nil {
+		t.Errorf("unexpected error creating PKI bundle %v", err)
+	}
+
+	r := dry.NewDryRunner()
+	cfg := &steps.Config{
+		IsBootstrap: true,
+		Runner:      r,
+		CertificatesConfig: steps.CertificatesConfig{
+			CAKey:  string(caPair.Key),
+			CACert: string(caPair.Cert),
+		},
+	}
+
+	output := new(bytes.Buffer)
+	err = New(tpl).Run(context.Background(), output, cfg)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if strings.Contains(r.GetOutput(), string(caPair.Key)) {
+		t.Errorf("CA key must not be written to the script %s", r.GetOutput())
+	}
+
+	for _, path := range []string{caCertPath, caKeyPath} {
+		if !strings.Contains(r.GetOutput(), path) {
+			t.Errorf("%s has not been uploaded %s", path, r.GetOutput())
+		}
+	}
+
+	cfg.Runner = &fakeRunner{}
+	err = New(tpl).Run(context.Background(), output, cfg)
+	if errors.Cause(err) != runner.ErrNoTransfer {
+		t.Errorf("Wrong error expected %v actual %v", runner.ErrNoTransfer, err)
+	}
+}
+
 func TestWriteCertificatesError(t *testing.T) {
 	errMsg := "error has occurred"
 
@@ -66,3 +66,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return []string{network.StepName}
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
@@ -155,6 +155,13 @@ type AWSConfig struct {
 	Subnets map[string]string `json:"subnets"`
 	// Map az to route table association
 	RouteTableAssociationIDs map[string]string `json:"routeTableAssociationIds"`
+
+	// Resources that have been created for the cluster rather than
+	// given in profile, rollback removes only these ones.
+	VPCCreated             bool `json:"vpcCreated,omitempty"`
+	SecurityGroupsCreated  bool `json:"securityGroupsCreated,omitempty"`
+	InternetGatewayCreated bool `json:"internetGatewayCreated,omitempty"`
+	RouteTableCreated      bool `json:"routeTableCreated,omitempty"`
 }
 
 type NetworkConfig struct {
@@ -304,6 +311,10 @@ type Config struct {
 	ConfigMap          ConfigMap          `json:"configMap"`
 	ApplyConfig        ApplyConfig        `json:"applyConfig"`
 
+	// RollbackOnFailure makes failed task roll back all its steps
+	// that have succeeded, so cloud resources are not left behind.
+	RollbackOnFailure bool `json:"rollbackOnFailure"`
+
 	ExternalDNSName string `json:"externalDnsName"`
 	InternalDNSName string `json:"internalDnsName"`
 
@@ -327,6 +338,9 @@ type Config struct {
 	nodeChan      chan model.Machine
 	kubeStateChan chan model.KubeState
 	configChan    chan *Config
+
+	// base is the config a fork has been made of
+	base *Config
 }
 
 type ConfigMap struct {
@@ -354,12 +368,14 @@ func NewConfig(clusterName, cloudAccountName string, profile profile.Profile) (*
 				User:      user,
 				Timeout:   30,
 				PublicKey: profile.PublicKey,
+				Bastions:  profile.Bastions,
 			},
 			ExposedAddresses: profile.ExposedAddresses,
 			APIServerPort:    ensurePort(profile.K8SAPIPort),
 		},
-		Provider:    profile.Provider,
-		ClusterName: clusterName,
+		Provider:          profile.Provider,
+		ClusterName:       clusterName,
+		RollbackOnFailure: profile.RollbackOnFailure,
 		DigitalOceanConfig: DOConfig{
 			Region: profile.Region,
 		},
@@ -473,11 +489,12 @@ func NewConfigFromKube(profile *profile.Profile, k *model.Kube) (*Config, error)
 	}
 
 	cfg := &Config{
-		ClusterID:      k.ID,
-		Provider:       profile.Provider,
-		ClusterName:    k.Name,
-		K8SVersion:     k.K8SVersion,
-		BootstrapToken: k.BootstrapToken,
+		ClusterID:         k.ID,
+		Provider:          profile.Provider,
+		ClusterName:       k.Name,
+		K8SVersion:        k.K8SVersion,
+		BootstrapToken:    k.BootstrapToken,
+		RollbackOnFailure: profile.RollbackOnFailure,
 		DigitalOceanConfig: DOConfig{
 			Region: profile.Region,
 		},
@@ -591,6 +608,7 @@ func NewConfigFromKube(profile *profile.Profile, k *model.Kube) (*Config, error)
 		User:      user,
 		Timeout:   10,
 		PublicKey: profile.PublicKey,
+		Bastions:  profile.Bastions,
 	}
 
 	return cfg, nil
@@ -181,3 +181,7 @@ func getSubnet(azSubnets map[string]string) string {
 	}
 	return ""
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanResource
+}
@@ -184,7 +184,31 @@ func (s *CreateLoadBalancerStep) Run(ctx context.Context, output io.Writer, conf
 	return nil
 }
 
-func (s *CreateLoadBalancerStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes load balancers created by the step, the one
+// created before failure of the step is deleted too.
+func (s *CreateLoadBalancerStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if config.DigitalOceanConfig.ExternalLoadBalancerID == "" &&
+		config.DigitalOceanConfig.InternalLoadBalancerID == "" {
+		return nil
+	}
+
+	lbSvc := s.getServices(config.DigitalOceanConfig.AccessToken)
+
+	for _, id := range []*string{
+		&config.DigitalOceanConfig.ExternalLoadBalancerID,
+		&config.DigitalOceanConfig.InternalLoadBalancerID,
+	} {
+		if *id == "" {
+			continue
+		}
+
+		logrus.Debugf("Delete load balancer %s", *id)
+		if _, err := lbSvc.Delete(ctx, *id); err != nil {
+			return errors.Wrapf(err, "delete load balancer %s", *id)
+		}
+		*id = ""
+	}
+
 	return nil
 }
 
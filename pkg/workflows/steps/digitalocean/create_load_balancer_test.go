@@ -221,4 +221,32 @@ func TestCreateLoadBalancerStep_Rollback(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error while rollback %v", err)
 	}
+
+	svc := &MockLBService{}
+	svc.On("Delete", mock.Anything, "external").Return(nil, nil)
+	svc.On("Delete", mock.Anything, "internal").
+		Return(nil, errors.New("error"))
+
+	s = CreateLoadBalancerStep{
+		getServices: func(string) LoadBalancerService {
+			return svc
+		},
+	}
+	config := &steps.Config{}
+	config.DigitalOceanConfig.ExternalLoadBalancerID = "external"
+	config.DigitalOceanConfig.InternalLoadBalancerID = "internal"
+
+	err = s.Rollback(context.Background(), ioutil.Discard, config)
+
+	if err == nil || !strings.Contains(err.Error(), "internal") {
+		t.Errorf("Error must mention internal load balancer %v", err)
+	}
+
+	if config.DigitalOceanConfig.ExternalLoadBalancerID != "" {
+		t.Errorf("External load balancer ID must be cleared")
+	}
+
+	if config.DigitalOceanConfig.InternalLoadBalancerID != "internal" {
+		t.Errorf("Internal load balancer ID must be kept")
+	}
 }
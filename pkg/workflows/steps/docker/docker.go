@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -35,7 +36,7 @@ func New(tpl *template.Template) *Step {
 }
 
 func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), t.script,
+	err := steps.RunTemplate(ctx, t.script,
 		config.Runner, out, config.DockerConfig)
 	if err != nil {
 		return errors.Wrap(err, "install docker step")
@@ -56,6 +57,21 @@ func (t *Step) Description() string {
 	return "Install docker"
 }
 
+// RetryPolicy lets the step survive temporary failures of package mirrors,
+// docker installation script may be run more than once.
+func (t *Step) RetryPolicy() steps.RetryPolicy {
+	return steps.RetryPolicy{
+		Attempts:   3,
+		Backoff:    time.Second * 10,
+		MaxBackoff: time.Minute,
+		Multiplier: 2,
+	}
+}
+
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
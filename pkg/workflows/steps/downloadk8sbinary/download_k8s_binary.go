@@ -35,7 +35,7 @@ func New(tpl *template.Template) *Step {
 }
 
 func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), s.script,
+	err := steps.RunTemplate(ctx, s.script,
 		config.Runner, out, config.DownloadK8sBinary)
 	if err != nil {
 		return errors.Wrap(err, "download k8s binary step")
@@ -59,3 +59,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
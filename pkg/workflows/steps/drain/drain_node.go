@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/model"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/sgerrors"
@@ -20,7 +21,7 @@ const StepName = "drain"
 
 type Step struct {
 	script    *template.Template
-	getRunner func(string, *steps.Config) (runner.Runner, error)
+	getRunner func(model.Machine, *steps.Config) (runner.Runner, error)
 }
 
 func (s *Step) Rollback(context.Context, io.Writer, *steps.Config) error {
@@ -40,7 +41,7 @@ func Init() {
 func New(script *template.Template) *Step {
 	t := &Step{
 		script: script,
-		getRunner: func(masterIp string, config *steps.Config) (runner.Runner, error) {
+		getRunner: func(master model.Machine, config *steps.Config) (runner.Runner, error) {
 			if config.Provider == clouds.AWS {
 				//on aws default user name on ubuntu images are not root but ubuntu
 				//https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/AccessingInstancesLinux.html
@@ -48,11 +49,13 @@ func New(script *template.Template) *Step {
 			}
 
 			cfg := ssh.Config{
-				Host:    masterIp,
-				Port:    config.Kube.SSHConfig.Port,
-				User:    config.Kube.SSHConfig.User,
-				Timeout: 10,
-				Key:     []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+				Host:     config.Kube.SSHConfig.Host(master),
+				Port:     config.Kube.SSHConfig.Port,
+				User:     config.Kube.SSHConfig.User,
+				Timeout:  10,
+				Key:      []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+				Bastions: ssh.Bastions(config.Kube.SSHConfig.Bastions),
+				HostKey:  master.HostKey,
 			}
 
 			sshRunner, err := ssh.NewRunner(cfg)
@@ -75,7 +78,7 @@ func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 		return errors.Wrapf(sgerrors.ErrNotFound, "master node not found")
 	}
 
-	r, err := s.getRunner(masterNode.PublicIp, config)
+	r, err := s.getRunner(*masterNode, config)
 
 	if err != nil {
 		return errors.Wrapf(err, "get runner")
@@ -101,3 +104,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return nil
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
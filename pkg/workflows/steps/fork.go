@@ -0,0 +1,202 @@
+package steps
+
+import (
+	"reflect"
+
+	"github.com/supergiant/control/pkg/model"
+)
+
+// Fork returns a copy of the config for a step that runs along with other
+// steps of the task, so the step does not race with them modifying it. The
+// changes the step has made are merged back into the config with Join.
+func (c *Config) Fork() *Config {
+	base := c.copy()
+	fork := base.copy()
+	fork.base = base
+
+	return fork
+}
+
+// Join merges the changes made to the fork since it has been created into
+// the config. A field changed by several forks gets the value of the fork
+// joined last, maps are merged key by key.
+func (c *Config) Join(fork *Config) {
+	if fork == nil || fork.base == nil {
+		return
+	}
+	base := fork.base
+	fork.base = nil
+
+	dst := reflect.ValueOf(c).Elem()
+	forkValue := reflect.ValueOf(fork).Elem()
+	baseValue := reflect.ValueOf(base).Elem()
+
+	for _, i := range configFields() {
+		merge(dst.Field(i), baseValue.Field(i), forkValue.Field(i))
+	}
+
+	c.m1.Lock()
+	if c.Masters.internal == nil {
+		c.Masters.internal = make(map[string]*model.Machine)
+	}
+	merge(reflect.ValueOf(c.Masters.internal), reflect.ValueOf(base.Masters.internal),
+		reflect.ValueOf(fork.Masters.internal))
+	c.m1.Unlock()
+
+	c.m2.Lock()
+	if c.Nodes.internal == nil {
+		c.Nodes.internal = make(map[string]*model.Machine)
+	}
+	merge(reflect.ValueOf(c.Nodes.internal), reflect.ValueOf(base.Nodes.internal),
+		reflect.ValueOf(fork.Nodes.internal))
+	c.m2.Unlock()
+
+	if a := fork.GetAzureAuthorizer(); !reflect.DeepEqual(a, base.GetAzureAuthorizer()) {
+		c.SetAzureAuthorizer(a)
+	}
+}
+
+// copy makes a deep copy of the config, the runner, the repository and
+// the channels are shared with the copy.
+func (c *Config) copy() *Config {
+	cp := &Config{
+		repository:     c.repository,
+		azureAthorizer: c.GetAzureAuthorizer(),
+		nodeChan:       c.nodeChan,
+		kubeStateChan:  c.kubeStateChan,
+		configChan:     c.configChan,
+	}
+
+	dst := reflect.ValueOf(cp).Elem()
+	src := reflect.ValueOf(c).Elem()
+	for _, i := range configFields() {
+		copyValue(dst.Field(i), src.Field(i))
+	}
+
+	c.m1.RLock()
+	cp.Masters.internal = copyMachines(c.Masters.internal)
+	c.m1.RUnlock()
+
+	c.m2.RLock()
+	cp.Nodes.internal = copyMachines(c.Nodes.internal)
+	c.m2.RUnlock()
+
+	return cp
+}
+
+func copyMachines(m map[string]*model.Machine) map[string]*model.Machine {
+	cp := make(map[string]*model.Machine, len(m))
+	copyValue(reflect.ValueOf(&cp).Elem(), reflect.ValueOf(m))
+
+	return cp
+}
+
+// configFields returns indices of exported fields of the config, but masters
+// and nodes that are guarded by their locks.
+func configFields() []int {
+	t := reflect.TypeOf(Config{})
+	fields := make([]int, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type == reflect.TypeOf(Map{}) {
+			continue
+		}
+		fields = append(fields, i)
+	}
+
+	return fields
+}
+
+// opaque structs are copied and compared as a whole, as their fields
+// can't be reached, time.Time is one of them.
+func opaque(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyValue deep copies src to dst, values of interfaces are shared
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if opaque(src.Type()) {
+			dst.Set(src)
+			return
+		}
+		for i := 0; i < src.NumField(); i++ {
+			copyValue(dst.Field(i), src.Field(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		for _, key := range src.MapKeys() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			copyValue(v, src.MapIndex(key))
+			m.SetMapIndex(key, v)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyValue(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		copyValue(p.Elem(), src.Elem())
+		dst.Set(p)
+	default:
+		dst.Set(src)
+	}
+}
+
+// merge sets the parts of dst that differ between base and fork to
+// the values of fork, dst must not be a nil map.
+func merge(dst, base, fork reflect.Value) {
+	switch fork.Kind() {
+	case reflect.Struct:
+		if opaque(fork.Type()) {
+			break
+		}
+		for i := 0; i < fork.NumField(); i++ {
+			merge(dst.Field(i), base.Field(i), fork.Field(i))
+		}
+		return
+	case reflect.Map:
+		if fork.IsNil() || base.IsNil() || dst.IsNil() {
+			break
+		}
+		for _, key := range fork.MapKeys() {
+			v := fork.MapIndex(key)
+			if b := base.MapIndex(key); !b.IsValid() || !reflect.DeepEqual(b.Interface(), v.Interface()) {
+				dst.SetMapIndex(key, v)
+			}
+		}
+		for _, key := range base.MapKeys() {
+			if !fork.MapIndex(key).IsValid() {
+				dst.SetMapIndex(key, reflect.Value{})
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(base.Interface(), fork.Interface()) {
+		dst.Set(fork)
+	}
+}
@@ -0,0 +1,62 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/runner/dry"
+)
+
+func TestConfigForkJoin(t *testing.T) {
+	cfg := &Config{
+		AWSConfig: AWSConfig{
+			Region:  "us-east-1",
+			Subnets: map[string]string{"us-east-1a": "subnet-1", "us-east-1b": "subnet-2"},
+		},
+		GCEConfig: GCEConfig{
+			InternalForwardingRuleName: "internal",
+		},
+		Masters: NewMap(map[string]*model.Machine{"m1": {ID: "m1", Name: "m1"}}),
+		Nodes:   NewMap(map[string]*model.Machine{}),
+	}
+
+	vpc := cfg.Fork()
+	keyPair := cfg.Fork()
+
+	// forks are deep copies, changes are not seen until they are joined
+	require.Equal(t, "internal", vpc.GCEConfig.InternalForwardingRuleName)
+	vpc.AWSConfig.VPCID = "vpc-1"
+	vpc.AWSConfig.Subnets["us-east-1c"] = "subnet-3"
+	delete(vpc.AWSConfig.Subnets, "us-east-1a")
+	vpc.AddMaster(&model.Machine{ID: "m2", Name: "m2"})
+	vpc.Runner = dry.NewDryRunner()
+	require.Empty(t, cfg.AWSConfig.VPCID)
+	require.Len(t, cfg.AWSConfig.Subnets, 2)
+	require.Len(t, cfg.GetMasters(), 1)
+
+	keyPair.AWSConfig.KeyPairName = "key"
+	keyPair.AWSConfig.Subnets["us-east-1b"] = "subnet-4"
+	keyPair.AddNode(&model.Machine{ID: "n1", Name: "n1"})
+
+	cfg.Join(vpc)
+	cfg.Join(keyPair)
+
+	require.Equal(t, "vpc-1", cfg.AWSConfig.VPCID)
+	require.Equal(t, "key", cfg.AWSConfig.KeyPairName)
+	require.Equal(t, "us-east-1", cfg.AWSConfig.Region)
+	require.Equal(t, map[string]string{
+		"us-east-1b": "subnet-4",
+		"us-east-1c": "subnet-3",
+	}, cfg.AWSConfig.Subnets)
+	require.Equal(t, "internal", cfg.GCEConfig.InternalForwardingRuleName)
+	require.Equal(t, vpc.Runner, cfg.Runner)
+	require.Len(t, cfg.GetMasters(), 2)
+	require.Len(t, cfg.GetNodes(), 1)
+
+	// fork is joined once
+	vpc.AWSConfig.VPCID = "vpc-2"
+	cfg.Join(vpc)
+	require.Equal(t, "vpc-1", cfg.AWSConfig.VPCID)
+}
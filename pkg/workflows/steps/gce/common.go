@@ -42,6 +42,7 @@ type computeService struct {
 	insertHealthCheck          func(context.Context, steps.GCEConfig, *compute.HealthCheck) (*compute.Operation, error)
 	addHealthCheckToTargetPool func(context.Context, steps.GCEConfig, string, *compute.TargetPoolsAddHealthCheckRequest) (*compute.Operation, error)
 	getHealthCheck             func(context.Context, steps.GCEConfig, string) (*compute.HealthCheck, error)
+	deleteHealthCheck          func(context.Context, steps.GCEConfig, string) (*compute.Operation, error)
 }
 
 func Init(getter accountGetter) {
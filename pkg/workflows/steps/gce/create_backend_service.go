@@ -17,11 +17,18 @@ import (
 const CreateBackendServiceStepName = "gce_create_backend_service"
 
 type CreateBackendServiceStep struct {
-	getComputeSvc func(context.Context, steps.GCEConfig) (*computeService, error)
+	getComputeSvc        func(context.Context, steps.GCEConfig) (*computeService, error)
+	deleteBackendService steps.Step
 }
 
 func NewCreateBackendServiceStep() (*CreateBackendServiceStep, error) {
+	deleteBackendService, err := NewDeleteBackendServiceStep()
+	if err != nil {
+		return nil, err
+	}
+
 	return &CreateBackendServiceStep{
+		deleteBackendService: deleteBackendService,
 		getComputeSvc: func(ctx context.Context, config steps.GCEConfig) (*computeService, error) {
 			client, err := gcesdk.GetClient(ctx, config)
 
@@ -104,13 +111,24 @@ func (s *CreateBackendServiceStep) Name() string {
 }
 
 func (s *CreateBackendServiceStep) Depends() []string {
-	return []string{CreateInstanceGroupsStepName, CreateNetworksStepName}
+	return []string{CreateInstanceGroupsStepName, CreateNetworksStepName, CreateHealthCheckStepName}
 }
 
 func (s *CreateBackendServiceStep) Description() string {
 	return "Create backend service"
 }
 
-func (s *CreateBackendServiceStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes backend service created by the step
+func (s *CreateBackendServiceStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if s.deleteBackendService == nil || config.GCEConfig.BackendServiceName == "" {
+		return nil
+	}
+
+	if err := s.deleteBackendService.Run(ctx, output, config); err != nil {
+		return errors.Wrapf(err, "delete backend service %s", config.GCEConfig.BackendServiceName)
+	}
+
+	config.GCEConfig.BackendServiceName = ""
+	config.GCEConfig.BackendServiceLink = ""
 	return nil
 }
@@ -20,6 +20,7 @@ type CreateForwardingRules struct {
 	attemptCount int
 
 	getComputeSvc func(context.Context, steps.GCEConfig) (*computeService, error)
+	deleteRules   steps.Step
 }
 
 func NewCreateForwardingRulesStep() *CreateForwardingRules {
@@ -42,6 +43,7 @@ func NewCreateForwardingRulesStep() *CreateForwardingRules {
 				},
 			}, nil
 		},
+		deleteRules: NewDeleteForwardingRulesStep(),
 	}
 }
 
@@ -145,13 +147,25 @@ func (s *CreateForwardingRules) Name() string {
 }
 
 func (s *CreateForwardingRules) Depends() []string {
-	return []string{CreateTargetPullStepName, CreateIPAddressStepName}
+	return []string{CreateTargetPullStepName, CreateIPAddressStepName, CreateNetworksStepName, CreateBackendServiceStepName}
 }
 
 func (s *CreateForwardingRules) Description() string {
 	return "Create forwarding rules to pass traffic to nodes"
 }
 
-func (s *CreateForwardingRules) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes external and internal forwarding rules of the cluster
+func (s *CreateForwardingRules) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if s.deleteRules == nil || config.GCEConfig.ExternalForwardingRuleName == "" &&
+		config.GCEConfig.InternalForwardingRuleName == "" {
+		return nil
+	}
+
+	if err := s.deleteRules.Run(ctx, output, config); err != nil {
+		return errors.Wrap(err, "delete forwarding rules")
+	}
+
+	config.GCEConfig.ExternalForwardingRuleName = ""
+	config.GCEConfig.InternalForwardingRuleName = ""
 	return nil
 }
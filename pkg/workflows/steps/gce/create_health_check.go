@@ -35,6 +35,9 @@ func NewCreateHealthCheckStep() *CreateHealthCheck {
 				getHealthCheck: func(ctx context.Context, config steps.GCEConfig, healthCheckName string) (*compute.HealthCheck, error) {
 					return client.HealthChecks.Get(config.ProjectID, healthCheckName).Do()
 				},
+				deleteHealthCheck: func(ctx context.Context, config steps.GCEConfig, healthCheckName string) (*compute.Operation, error) {
+					return client.HealthChecks.Delete(config.ProjectID, healthCheckName).Do()
+				},
 			}, nil
 		},
 	}
@@ -52,7 +55,7 @@ func (s *CreateHealthCheck) Run(ctx context.Context, output io.Writer,
 	}
 
 	healthCheck := &compute.HealthCheck{
-		Name:               fmt.Sprintf("hc-%s", config.ClusterID),
+		Name:               healthCheckName(config.ClusterID),
 		CheckIntervalSec:   10,
 		HealthyThreshold:   3,
 		UnhealthyThreshold: 3,
@@ -96,6 +99,27 @@ func (s *CreateHealthCheck) Description() string {
 	return "Create health checks"
 }
 
-func (s *CreateHealthCheck) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes health check created by the step
+func (s *CreateHealthCheck) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	// NOTE: config keeps link to the health check rather than its name
+	if config.GCEConfig.HealthCheckName == "" {
+		return nil
+	}
+
+	svc, err := s.getComputeSvc(ctx, config.GCEConfig)
+	if err != nil {
+		return errors.Wrapf(err, "%s getting service caused", CreateHealthCheckStepName)
+	}
+
+	name := healthCheckName(config.ClusterID)
+	if _, err = svc.deleteHealthCheck(ctx, config.GCEConfig, name); err != nil && !isNotFound(err) {
+		return errors.Wrapf(err, "delete health check %s", name)
+	}
+
+	config.GCEConfig.HealthCheckName = ""
 	return nil
 }
+
+func healthCheckName(clusterID string) string {
+	return fmt.Sprintf("hc-%s", clusterID)
+}
@@ -19,11 +19,18 @@ type CreateInstanceGroupsStep struct {
 
 	getComputeSvc     func(context.Context, steps.GCEConfig) (*computeService, error)
 	zoneGetterFactory func(context.Context, accountGetter, *steps.Config) (account.ZonesGetter, error)
+	deleteGroups      steps.Step
 }
 
 func NewCreateInstanceGroupsStep(getter accountGetter) (*CreateInstanceGroupsStep, error) {
+	deleteGroups, err := NewDeleteInstanceGroupStep()
+	if err != nil {
+		return nil, err
+	}
+
 	return &CreateInstanceGroupsStep{
 		accountGetter: getter,
+		deleteGroups:  deleteGroups,
 		zoneGetterFactory: func(ctx context.Context, accountGetter accountGetter,
 			cfg *steps.Config) (account.ZonesGetter, error) {
 			acc, err := accountGetter.Get(ctx, cfg.CloudAccountName)
@@ -34,9 +41,12 @@ func NewCreateInstanceGroupsStep(getter accountGetter) (*CreateInstanceGroupsSte
 				return nil, errors.Wrapf(err, "Get cloud account")
 			}
 
-			zoneGetter, err := account.NewZonesGetter(acc, cfg)
+			zoneGetter, err := account.NewGCEFinder(acc, cfg)
+			if err != nil {
+				return nil, err
+			}
 
-			return zoneGetter, err
+			return zoneGetter, nil
 		},
 		getComputeSvc: func(ctx context.Context, config steps.GCEConfig) (*computeService, error) {
 			client, err := gcesdk.GetClient(ctx, config)
@@ -139,6 +149,17 @@ func (s *CreateInstanceGroupsStep) Description() string {
 	return "Create instance group for master nodes"
 }
 
-func (s *CreateInstanceGroupsStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes instance groups created by the step in all zones
+func (s *CreateInstanceGroupsStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if s.deleteGroups == nil || len(config.GCEConfig.InstanceGroupNames) == 0 {
+		return nil
+	}
+
+	if err := s.deleteGroups.Run(ctx, output, config); err != nil {
+		return errors.Wrap(err, "delete instance groups")
+	}
+
+	config.GCEConfig.InstanceGroupNames = make(map[string]string)
+	config.GCEConfig.InstanceGroupLinks = make(map[string]string)
 	return nil
 }
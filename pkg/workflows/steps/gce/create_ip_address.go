@@ -17,9 +17,10 @@ import (
 const CreateIPAddressStepName = "gce_create_ip_address"
 
 type CreateAddressStep struct {
-	Timeout       time.Duration
-	AttemptCount  int
-	getComputeSvc func(context.Context, steps.GCEConfig) (*computeService, error)
+	Timeout         time.Duration
+	AttemptCount    int
+	getComputeSvc   func(context.Context, steps.GCEConfig) (*computeService, error)
+	deleteAddresses steps.Step
 }
 
 func NewCreateAddressStep() *CreateAddressStep {
@@ -42,6 +43,7 @@ func NewCreateAddressStep() *CreateAddressStep {
 				},
 			}, nil
 		},
+		deleteAddresses: NewDeleteIpAddressStep(),
 	}
 }
 
@@ -156,6 +158,20 @@ func (s *CreateAddressStep) Description() string {
 	return "Create static ip addresses"
 }
 
-func (s *CreateAddressStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback releases static addresses reserved by the step
+func (s *CreateAddressStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if s.deleteAddresses == nil || config.GCEConfig.ExternalAddressName == "" &&
+		config.GCEConfig.InternalAddressName == "" {
+		return nil
+	}
+
+	if err := s.deleteAddresses.Run(ctx, output, config); err != nil {
+		return errors.Wrap(err, "delete ip addresses")
+	}
+
+	config.GCEConfig.ExternalAddressName = ""
+	config.GCEConfig.ExternalIPAddressLink = ""
+	config.GCEConfig.InternalAddressName = ""
+	config.GCEConfig.InternalIPAddressLink = ""
 	return nil
 }
@@ -15,7 +15,8 @@ import (
 const CreateTargetPullStepName = "gce_create_target_pool"
 
 type CreateTargetPoolStep struct {
-	getComputeSvc func(context.Context, steps.GCEConfig) (*computeService, error)
+	getComputeSvc    func(context.Context, steps.GCEConfig) (*computeService, error)
+	deleteTargetPool steps.Step
 }
 
 func NewCreateTargetPoolStep() *CreateTargetPoolStep {
@@ -36,6 +37,7 @@ func NewCreateTargetPoolStep() *CreateTargetPoolStep {
 				},
 			}, nil
 		},
+		deleteTargetPool: NewDeleteTargetPoolStep(),
 	}
 }
 
@@ -89,6 +91,17 @@ func (s *CreateTargetPoolStep) Description() string {
 	return "Create target pool"
 }
 
-func (s *CreateTargetPoolStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+// Rollback deletes target pool created by the step
+func (s *CreateTargetPoolStep) Rollback(ctx context.Context, output io.Writer, config *steps.Config) error {
+	if s.deleteTargetPool == nil || config.GCEConfig.TargetPoolName == "" {
+		return nil
+	}
+
+	if err := s.deleteTargetPool.Run(ctx, output, config); err != nil {
+		return errors.Wrapf(err, "delete target pool %s", config.GCEConfig.TargetPoolName)
+	}
+
+	config.GCEConfig.TargetPoolName = ""
+	config.GCEConfig.TargetPoolLink = ""
 	return nil
 }
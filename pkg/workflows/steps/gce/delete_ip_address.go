@@ -54,7 +54,7 @@ func (s *DeleteIpAddressStep) Run(ctx context.Context, output io.Writer,
 		logrus.Errorf("Error deleting external address %s %v", config.GCEConfig.ExternalAddressName, err)
 	}
 
-	_, err = svc.deleteIpAddress(ctx, config.GCEConfig, config.GCEConfig.ExternalAddressName)
+	_, err = svc.deleteIpAddress(ctx, config.GCEConfig, config.GCEConfig.InternalAddressName)
 
 	if err != nil {
 		logrus.Errorf("Error deleting internal address %s %v", config.GCEConfig.InternalAddressName, err)
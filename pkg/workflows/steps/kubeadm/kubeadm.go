@@ -9,8 +9,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/certificates"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
 )
 
@@ -41,7 +43,7 @@ func New(script *template.Template) *Step {
 }
 
 func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	config.KubeadmConfig.Provider = toCloudProviderOpt(config.Provider)
+	config.KubeadmConfig.Provider = cloudProvider(config.Provider)
 	config.KubeadmConfig.IsBootstrap = config.IsBootstrap
 	config.KubeadmConfig.IsMaster = config.IsMaster
 	config.KubeadmConfig.InternalDNSName = config.InternalDNSName
@@ -78,16 +80,20 @@ func (t *Step) Description() string {
 }
 
 func (s *Step) Depends() []string {
-	return []string{docker.StepName}
+	return []string{docker.StepName, certificates.StepName}
 }
 
 // TODO: cloud profiles is deprecated by kubernetes, use controller-managers
-func toCloudProviderOpt(cloudName clouds.Name) string {
-	switch cloudName {
-	case clouds.AWS:
-		return "aws"
-	case clouds.GCE:
-		return "gce"
+func cloudProvider(cloudName clouds.Name) string {
+	p, err := providers.Get(cloudName)
+	// kubernetes is run without cloud provider in unknown clouds
+	if err != nil {
+		return ""
 	}
-	return ""
+
+	return p.KubernetesCloudProvider()
+}
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
 	"text/template"
@@ -15,10 +16,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/certificates"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
 )
 
@@ -128,8 +131,9 @@ func TestStepName(t *testing.T) {
 func TestDepends(t *testing.T) {
 	s := Step{}
 
-	if len(s.Depends()) != 1 && s.Depends()[0] != docker.StepName {
-		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), []string{docker.StepName})
+	expected := []string{docker.StepName, certificates.StepName}
+	if !reflect.DeepEqual(s.Depends(), expected) {
+		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), expected)
 	}
 }
 
@@ -188,17 +192,31 @@ func TestStep_Description(t *testing.T) {
 	}
 }
 
-func TestToCloudProviderOpt(t *testing.T) {
+type fakeProvider struct {
+	providers.Provider
+	name clouds.Name
+}
+
+func (p fakeProvider) Name() clouds.Name {
+	return p.name
+}
+
+func (p fakeProvider) KubernetesCloudProvider() string {
+	return "fake"
+}
+
+func TestCloudProvider(t *testing.T) {
+	providers.Register(fakeProvider{name: "fake"})
+
 	for _, tc := range []struct {
 		in  clouds.Name
 		out string
 	}{
-		{clouds.AWS, "aws"},
-		{clouds.GCE, "gce"},
-		{clouds.DigitalOcean, ""},
+		{"fake", "fake"},
+		{"unknown", ""},
 	} {
-		if toCloudProviderOpt(tc.in) != tc.out {
-			t.Logf("toCloudProvider(%s) = %s", tc.in, tc.out)
+		if out := cloudProvider(tc.in); out != tc.out {
+			t.Errorf("cloudProvider(%s) expected %s actual %s", tc.in, tc.out, out)
 		}
 	}
 }
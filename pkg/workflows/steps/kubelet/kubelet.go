@@ -9,7 +9,9 @@ import (
 	"github.com/pkg/errors"
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/util"
 )
 
@@ -87,5 +89,9 @@ func (t *Step) Description() string {
 }
 
 func (s *Step) Depends() []string {
-	return []string{docker.StepName}
+	return []string{docker.StepName, kubeadm.StepName, bootstraptoken.StepName}
+}
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
 }
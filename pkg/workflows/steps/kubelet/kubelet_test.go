@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
 	"text/template"
@@ -14,7 +15,9 @@ import (
 	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
+	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 )
 
 type fakeRunner struct {
@@ -102,8 +105,9 @@ func TestStepName(t *testing.T) {
 func TestDepends(t *testing.T) {
 	s := Step{}
 
-	if len(s.Depends()) != 1 && s.Depends()[0] != docker.StepName {
-		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), []string{docker.StepName})
+	expected := []string{docker.StepName, kubeadm.StepName, bootstraptoken.StepName}
+	if !reflect.DeepEqual(s.Depends(), expected) {
+		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), expected)
 	}
 }
 
@@ -11,6 +11,7 @@ import (
 	tm "github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/workflows/steps"
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
+	"github.com/supergiant/control/pkg/workflows/steps/poststart"
 )
 
 const StepName = "network"
@@ -42,7 +43,7 @@ func (t *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) err
 
 	config.NetworkConfig.IsBootstrap = config.IsBootstrap
 	logrus.Debugf("cluster %s: network config: %+v", config.ClusterName, config.NetworkConfig)
-	err := steps.RunTemplate(context.Background(), t.script, config.Runner, out, config.NetworkConfig)
+	err := steps.RunTemplate(ctx, t.script, config.Runner, out, config.NetworkConfig)
 	if err != nil {
 		return errors.Wrap(err, "configure network step")
 	}
@@ -63,5 +64,9 @@ func (t *Step) Description() string {
 }
 
 func (s *Step) Depends() []string {
-	return []string{kubeadm.StepName}
+	return []string{kubeadm.StepName, poststart.StepName}
+}
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
 	"text/template"
@@ -16,8 +17,8 @@ import (
 	"github.com/supergiant/control/pkg/templatemanager"
 	"github.com/supergiant/control/pkg/testutils"
 	"github.com/supergiant/control/pkg/workflows/steps"
+	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/steps/poststart"
-	"github.com/supergiant/control/pkg/workflows/steps/ssh"
 )
 
 type fakeRunner struct {
@@ -154,8 +155,9 @@ func TestStepName(t *testing.T) {
 func TestDepends(t *testing.T) {
 	s := Step{}
 
-	if len(s.Depends()) != 1 && s.Depends()[0] != ssh.StepName {
-		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), []string{poststart.StepName})
+	expected := []string{kubeadm.StepName, poststart.StepName}
+	if !reflect.DeepEqual(s.Depends(), expected) {
+		t.Errorf("Wrong dependency list %v expected %v", s.Depends(), expected)
 	}
 }
 
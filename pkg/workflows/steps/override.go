@@ -0,0 +1,60 @@
+package steps
+
+import (
+	"time"
+)
+
+// overrideStep replaces retry policy or timeout declared by the step
+type overrideStep struct {
+	Step
+	policy  *RetryPolicy
+	timeout *time.Duration
+}
+
+func (s overrideStep) RetryPolicy() RetryPolicy {
+	if s.policy != nil {
+		return *s.policy
+	}
+
+	return GetRetryPolicy(s.Step)
+}
+
+func (s overrideStep) Timeout(config *Config) time.Duration {
+	if s.timeout != nil {
+		return *s.timeout
+	}
+
+	return GetTimeout(s.Step, config)
+}
+
+func (s overrideStep) PlanKind() PlanKind {
+	return GetPlanKind(s.Step)
+}
+
+func override(step Step) overrideStep {
+	if s, ok := step.(overrideStep); ok {
+		return s
+	}
+
+	return overrideStep{
+		Step: step,
+	}
+}
+
+// WithRetry overrides retry policy of the step, it lets
+// a workflow choose how its steps are retried.
+func WithRetry(step Step, policy RetryPolicy) Step {
+	s := override(step)
+	s.policy = &policy
+
+	return s
+}
+
+// WithTimeout overrides time the step is allowed to run,
+// zero timeout lets the step run without deadline.
+func WithTimeout(step Step, timeout time.Duration) Step {
+	s := override(step)
+	s.timeout = &timeout
+
+	return s
+}
@@ -0,0 +1,30 @@
+package steps
+
+// PlanKind tells how planning of provisioning treats a step
+type PlanKind int
+
+const (
+	// PlanUnknown steps can't be planned, plans of workflows that have
+	// such steps are rejected.
+	PlanUnknown PlanKind = iota
+	// PlanScript steps only run scripts on the machine with config.Runner,
+	// planning runs them with dry runner to render the scripts.
+	PlanScript
+	// PlanResource steps call cloud or kubernetes API, planning does not
+	// run them but reports them as resources they would create.
+	PlanResource
+)
+
+// Planner is implemented by steps that can be planned
+type Planner interface {
+	PlanKind() PlanKind
+}
+
+// GetPlanKind returns how planning treats the step
+func GetPlanKind(step Step) PlanKind {
+	if p, ok := step.(Planner); ok {
+		return p.PlanKind()
+	}
+
+	return PlanUnknown
+}
@@ -0,0 +1,224 @@
+// Package plugin runs steps implemented by external executables.
+//
+// Plugin is an executable file in plugins directory that is called with
+// one of the commands as its only argument:
+//
+//	describe - writes Description of the step as JSON to stdout
+//	run      - runs the step
+//	rollback - undoes what run has done
+//
+// Run and rollback read Request as JSON from stdin, everything they write
+// to stdout and stderr goes to the task log, non zero exit code fails the step.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/runner/ssh"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const (
+	CommandDescribe = "describe"
+	CommandRun      = "run"
+	CommandRollback = "rollback"
+
+	// describeTimeout limits time plugin is given to describe itself
+	describeTimeout = time.Second * 10
+)
+
+// Description is what plugin tells about the step it implements
+type Description struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Depends has the same meaning as steps.Step Depends,
+	// null runs the step after the previous one.
+	Depends []string `json:"depends"`
+}
+
+// Request is written to stdin of plugin on run and rollback
+type Request struct {
+	Config *steps.Config `json:"config"`
+	SSH    SSHDetails    `json:"ssh"`
+}
+
+// SSHDetails are needed to connect to the node the step is run on
+type SSHDetails struct {
+	Host       string `json:"host"`
+	Port       string `json:"port"`
+	User       string `json:"user"`
+	Timeout    int    `json:"timeout"`
+	PrivateKey string `json:"privateKey"`
+	// HostKey is a public key the node presents in authorized_keys
+	// format, it is empty until the node has been connected to.
+	HostKey string `json:"hostKey,omitempty"`
+	// Bastions are jump hosts the node is reached through in order,
+	// they have user and private key of the node when not set.
+	Bastions []profile.BastionHost `json:"bastions,omitempty"`
+}
+
+// Step runs plugin executable
+type Step struct {
+	path string
+	desc Description
+}
+
+// Load describes executables of the directory and registers them as steps,
+// plugin is not allowed to replace a step that has been registered already.
+func Load(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "read plugins dir %s", dir)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || f.Mode()&0111 == 0 {
+			continue
+		}
+
+		step, err := New(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+
+		if steps.GetStep(step.Name()) != nil {
+			return errors.Errorf("plugin %s: step %s is already registered",
+				f.Name(), step.Name())
+		}
+
+		steps.RegisterStep(step.Name(), step)
+		logrus.Infof("registered step %s of plugin %s", step.Name(), f.Name())
+	}
+
+	return nil
+}
+
+// New asks plugin executable to describe its step
+func New(path string) (*Step, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, path, CommandDescribe)
+	cmd.Stderr = stderr
+
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "describe plugin %s: %s", path,
+			strings.TrimSpace(stderr.String()))
+	}
+
+	desc := Description{}
+	if err := json.Unmarshal(data, &desc); err != nil {
+		return nil, errors.Wrapf(err, "decode description of plugin %s", path)
+	}
+
+	if strings.TrimSpace(desc.Name) == "" {
+		return nil, errors.Errorf("plugin %s has no step name", path)
+	}
+
+	return &Step{
+		path: path,
+		desc: desc,
+	}, nil
+}
+
+func (s *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	// plugins are not able to tell what they would do
+	if config.DryRun {
+		return nil
+	}
+
+	return s.exec(ctx, CommandRun, out, config)
+}
+
+func (s *Step) Rollback(ctx context.Context, out io.Writer, config *steps.Config) error {
+	if config.DryRun {
+		return nil
+	}
+
+	return s.exec(ctx, CommandRollback, out, config)
+}
+
+func (s *Step) Name() string {
+	return s.desc.Name
+}
+
+func (s *Step) Description() string {
+	return s.desc.Description
+}
+
+func (s *Step) Depends() []string {
+	return s.desc.Depends
+}
+
+func (s *Step) exec(ctx context.Context, command string, out io.Writer, config *steps.Config) error {
+	req, err := json.Marshal(Request{
+		Config: config,
+		SSH:    sshDetails(config),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "marshal request to plugin %s", s.Name())
+	}
+
+	cmd := exec.CommandContext(ctx, s.path, command)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// plugin is run in the directory it lives in, so
+	// it is able to find files it has been shipped with.
+	cmd.Dir = filepath.Dir(s.path)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "%s plugin %s", command, s.Name())
+		}
+		return errors.Wrapf(err, "%s plugin %s", command, s.Name())
+	}
+
+	return nil
+}
+
+// sshDetails tells how the node is reached the same way ssh runner does
+func sshDetails(config *steps.Config) SSHDetails {
+	sshConfig := config.Kube.SSHConfig
+	details := SSHDetails{
+		Host:       sshConfig.Host(config.Node),
+		Port:       sshConfig.Port,
+		User:       sshConfig.User,
+		Timeout:    sshConfig.Timeout,
+		PrivateKey: sshConfig.BootstrapPrivateKey,
+		HostKey:    config.Node.HostKey,
+	}
+
+	for _, bastion := range sshConfig.Bastions {
+		if bastion.Port == "" {
+			bastion.Port = ssh.DefaultPort
+		}
+		if bastion.User == "" {
+			bastion.User = details.User
+		}
+		if bastion.PrivateKey == "" {
+			bastion.PrivateKey = details.PrivateKey
+		}
+		details.Bastions = append(details.Bastions, bastion)
+	}
+
+	return details
+}
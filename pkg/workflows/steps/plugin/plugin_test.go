@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/supergiant/control/pkg/model"
+	"github.com/supergiant/control/pkg/profile"
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+const echoPlugin = `#!/bin/sh
+case "$1" in
+describe)
+	echo '{"name": "pluginEcho", "description": "echo request", "depends": ["docker"]}'
+	;;
+run)
+	echo "running"
+	cat > request.json
+	;;
+rollback)
+	echo "rollback failed" >&2
+	exit 3
+	;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestStep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	step, err := New(writePlugin(t, dir, "echo", echoPlugin))
+	require.NoError(t, err)
+	require.Equal(t, "pluginEcho", step.Name())
+	require.Equal(t, "echo request", step.Description())
+	require.Equal(t, []string{"docker"}, step.Depends())
+
+	config := &steps.Config{
+		ClusterName: "test",
+		Node: model.Machine{
+			PublicIp:  "10.0.0.1",
+			PrivateIp: "192.168.0.1",
+			HostKey:   "ssh-ed25519 AAAA",
+		},
+		Kube: model.Kube{
+			SSHConfig: model.SSHConfig{
+				User:                "root",
+				Port:                "22",
+				BootstrapPrivateKey: "key",
+				Bastions: []profile.BastionHost{
+					{Host: "10.0.0.2", User: "jump"},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, step.Run(context.Background(), out, config))
+	require.Equal(t, "running\n", out.String())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "request.json"))
+	require.NoError(t, err)
+
+	req := Request{}
+	require.NoError(t, json.Unmarshal(data, &req))
+	require.Equal(t, "test", req.Config.ClusterName)
+	require.Equal(t, SSHDetails{
+		Host:       "192.168.0.1",
+		Port:       "22",
+		User:       "root",
+		PrivateKey: "key",
+		HostKey:    "ssh-ed25519 AAAA",
+		Bastions: []profile.BastionHost{
+			{Host: "10.0.0.2", Port: "22", User: "jump", PrivateKey: "key"},
+		},
+	}, req.SSH)
+
+	out.Reset()
+	err = step.Rollback(context.Background(), out, config)
+	require.Error(t, err)
+	require.Equal(t, "rollback failed\n", out.String())
+
+	config.DryRun = true
+	out.Reset()
+	require.NoError(t, step.Rollback(context.Background(), out, config))
+	require.Empty(t, out.String())
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writePlugin(t, dir, "echo", echoPlugin)
+	// files that are not executable are not plugins
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("docs"), 0644))
+
+	require.NoError(t, Load(""))
+	require.NoError(t, Load(dir))
+	require.NotNil(t, steps.GetStep("pluginEcho"))
+
+	// registered steps are not replaced
+	err = Load(dir)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "already registered"))
+
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho '{}'\n")
+	require.Error(t, Load(dir))
+}
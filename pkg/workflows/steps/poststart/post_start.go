@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -77,6 +78,16 @@ func (s *Step) Description() string {
 	return "Post start step executes after provisioning"
 }
 
+// Timeout is disabled as the step waits for API server
+// within the time set by PostStartConfig.Timeout.
+func (s *Step) Timeout(*steps.Config) time.Duration {
+	return 0
+}
+
 func (s *Step) Depends() []string {
 	return []string{kubelet.StepName}
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
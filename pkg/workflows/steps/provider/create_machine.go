@@ -2,18 +2,13 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
-	"github.com/supergiant/control/pkg/workflows/steps/azure"
-	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
-	"github.com/supergiant/control/pkg/workflows/steps/gce"
 )
 
 const (
@@ -32,10 +27,12 @@ func (s StepCreateMachine) Run(ctx context.Context, out io.Writer, cfg *steps.Co
 		return nil
 	}
 
-	step, err := createMachineStepFor(cfg.Provider)
+	p, err := providers.Get(cfg.Provider)
 	if err != nil {
 		return err
 	}
+
+	step := p.CreateMachineStep()
 	// TODO: check it on steps.GetStep()
 	if step == nil {
 		return errors.Wrap(sgerrors.ErrRawError, "createMachine step not found")
@@ -60,16 +57,6 @@ func (s StepCreateMachine) Rollback(context.Context, io.Writer, *steps.Config) e
 	return nil
 }
 
-func createMachineStepFor(provider clouds.Name) (steps.Step, error) {
-	switch provider {
-	case clouds.AWS:
-		return steps.GetStep(amazon.StepNameCreateEC2Instance), nil
-	case clouds.DigitalOcean:
-		return steps.GetStep(digitalocean.CreateMachineStepName), nil
-	case clouds.GCE:
-		return steps.GetStep(gce.CreateInstanceStepName), nil
-	case clouds.Azure:
-		return steps.GetStep(azure.CreateVMStepName), nil
-	}
-	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
+func (s StepCreateMachine) PlanKind() steps.PlanKind {
+	return steps.PlanResource
 }
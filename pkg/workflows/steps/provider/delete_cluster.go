@@ -2,17 +2,12 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
-	"github.com/supergiant/control/pkg/workflows/steps/azure"
-	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
-	"github.com/supergiant/control/pkg/workflows/steps/gce"
 )
 
 const (
@@ -27,11 +22,11 @@ func (s DeleteCluster) Run(ctx context.Context, out io.Writer, cfg *steps.Config
 		return errors.New("invalid config")
 	}
 
-	steps, err := cleanUpStepsFor(cfg.Provider)
+	p, err := providers.Get(cfg.Provider)
 	if err != nil {
 		return errors.Wrap(err, DeleteClusterStepName)
 	}
-	for _, s := range steps {
+	for _, s := range p.DeleteClusterSteps() {
 		if err = s.Run(ctx, out, cfg); err != nil {
 			return errors.Wrap(err, DeleteClusterStepName)
 		}
@@ -55,42 +50,3 @@ func (s DeleteCluster) Depends() []string {
 func (s DeleteCluster) Rollback(context.Context, io.Writer, *steps.Config) error {
 	return nil
 }
-
-func cleanUpStepsFor(provider clouds.Name) ([]steps.Step, error) {
-	// TODO: use provider interface
-	switch provider {
-	case clouds.AWS:
-		return []steps.Step{
-			steps.GetStep(amazon.DeleteClusterMachinesStepName),
-			steps.GetStep(amazon.DeleteLoadBalancerStepName),
-			steps.GetStep(amazon.DeleteSecurityGroupsStepName),
-			steps.GetStep(amazon.DisassociateRouteTableStepName),
-			steps.GetStep(amazon.DeleteSubnetsStepName),
-			steps.GetStep(amazon.DeleteRouteTableStepName),
-			steps.GetStep(amazon.DeleteInternetGatewayStepName),
-			steps.GetStep(amazon.DeleteKeyPairStepName),
-			steps.GetStep(amazon.DeleteVPCStepName),
-		}, nil
-	case clouds.DigitalOcean:
-		return []steps.Step{
-			steps.GetStep(digitalocean.DeleteClusterMachines),
-			steps.GetStep(digitalocean.DeleteDeleteKeysStepName),
-			steps.GetStep(digitalocean.DeleteLoadBalancerStepName),
-		}, nil
-	case clouds.GCE:
-		return []steps.Step{
-			steps.GetStep(gce.DeleteClusterStepName),
-			steps.GetStep(gce.DeleteForwardingRulesStepName),
-			steps.GetStep(gce.DeleteBackendServicStepName),
-			steps.GetStep(gce.DeleteTargetPoolStepName),
-			steps.GetStep(gce.DeleteInstanceGroupStepName),
-			steps.GetStep(gce.DeleteIpAddressStepName),
-		}, nil
-	case clouds.Azure:
-		return []steps.Step{
-			steps.GetStep(azure.GetAuthorizerStepName),
-			steps.GetStep(azure.DeleteClusterStepName),
-		}, nil
-	}
-	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
-}
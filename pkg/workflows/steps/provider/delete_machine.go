@@ -2,17 +2,12 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
-	"github.com/supergiant/control/pkg/workflows/steps/azure"
-	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
-	"github.com/supergiant/control/pkg/workflows/steps/gce"
 )
 
 const (
@@ -27,11 +22,16 @@ func (s StepDeleteMachine) Run(ctx context.Context, out io.Writer, cfg *steps.Co
 		return errors.New("invalid config")
 	}
 
-	step, err := deleteMachineStepFor(cfg.Provider)
+	p, err := providers.Get(cfg.Provider)
 	if err != nil {
 		return err
 	}
 
+	step := p.DeleteMachineStep()
+	if step == nil {
+		return errors.Errorf("%s: delete machine step not found", cfg.Provider)
+	}
+
 	return step.Run(ctx, out, cfg)
 }
 
@@ -50,17 +50,3 @@ func (s StepDeleteMachine) Depends() []string {
 func (s StepDeleteMachine) Rollback(context.Context, io.Writer, *steps.Config) error {
 	return nil
 }
-
-func deleteMachineStepFor(provider clouds.Name) (steps.Step, error) {
-	switch provider {
-	case clouds.AWS:
-		return steps.GetStep(amazon.DeleteNodeStepName), nil
-	case clouds.DigitalOcean:
-		return steps.GetStep(digitalocean.DeleteMachineStepName), nil
-	case clouds.GCE:
-		return steps.GetStep(gce.DeleteNodeStepName), nil
-	case clouds.Azure:
-		return steps.GetStep(azure.DeleteVMStepName), nil
-	}
-	return nil, errors.New(fmt.Sprintf("unknown provider: %s", provider))
-}
@@ -7,13 +7,8 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
-
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
-	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
-	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
-	"github.com/supergiant/control/pkg/workflows/steps/ssh"
 )
 
 const (
@@ -24,20 +19,13 @@ type ImportClusterStep struct {
 }
 
 func (s ImportClusterStep) Run(ctx context.Context, out io.Writer, cfg *steps.Config) error {
-	var importSteps []steps.Step
+	p, err := providers.Get(cfg.Provider)
+	if err != nil {
+		return errors.Wrap(err, ImportClusterStepName)
+	}
 
-	switch cfg.Provider {
-	case clouds.AWS:
-		importSteps = []steps.Step{
-			steps.GetStep(amazon.ImportClusterMachinesStepName),
-			steps.GetStep(amazon.ImportSubnetsStepName),
-			steps.GetStep(amazon.ImportInternetGatewayStepName),
-			steps.GetStep(amazon.ImporRouteTablesStepName),
-			steps.GetStep(ssh.StepName),
-			steps.GetStep(authorizedkeys.StepName),
-			steps.GetStep(bootstraptoken.StepName),
-		}
-	default:
+	importSteps := p.ImportSteps()
+	if importSteps == nil {
 		return errors.New(fmt.Sprintf("unsupported provider: %s", cfg.Provider))
 	}
 
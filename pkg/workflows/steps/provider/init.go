@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"github.com/supergiant/control/pkg/workflows/steps"
+)
+
+// Init registers provider agnostic steps, so they can be referenced
+// by name in workflow definitions. RegisterInstanceToLoadBalancer is
+// left out as its name is taken by the amazon step it delegates to.
+func Init() {
+	steps.RegisterStep(CreateMachineStep, StepCreateMachine{})
+	steps.RegisterStep(DeleteMachineStep, StepDeleteMachine{})
+	steps.RegisterStep(DeleteClusterStepName, DeleteCluster{})
+	steps.RegisterStep(ImportClusterStepName, ImportClusterStep{})
+	steps.RegisterStep(PostStartCluster, StepPostStartCluster{})
+}
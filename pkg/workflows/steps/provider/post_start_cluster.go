@@ -2,14 +2,12 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 )
 
 const (
@@ -23,12 +21,12 @@ func (s StepPostStartCluster) Run(ctx context.Context, out io.Writer, cfg *steps
 		return errors.New("invalid config")
 	}
 
-	postStartClusterStep, err := postStartCluster(cfg.Provider)
+	p, err := providers.Get(cfg.Provider)
 	if err != nil {
 		return errors.Wrap(err, PostStartCluster)
 	}
 
-	for _, s := range postStartClusterStep {
+	for _, s := range p.PostStartSteps() {
 		if err = s.Run(ctx, out, cfg); err != nil {
 			return errors.Wrap(err, PostStartCluster)
 		}
@@ -53,19 +51,6 @@ func (s StepPostStartCluster) Rollback(context.Context, io.Writer, *steps.Config
 	return nil
 }
 
-func postStartCluster(provider clouds.Name) ([]steps.Step, error) {
-	switch provider {
-	case clouds.AWS:
-		return []steps.Step{
-			steps.GetStep(amazon.StepCreateTags),
-		}, nil
-	case clouds.DigitalOcean:
-		return []steps.Step{}, nil
-	case clouds.Azure:
-		return []steps.Step{}, nil
-	case clouds.GCE:
-		// TODO(stgleb): Add non-bootstrap master instances to instance groups
-		return []steps.Step{}, nil
-	}
-	return nil, errors.Wrapf(fmt.Errorf("unknown provider: %s", provider), PostStartCluster)
+func (s StepPostStartCluster) PlanKind() steps.PlanKind {
+	return steps.PlanResource
 }
@@ -2,14 +2,12 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
 
-	"github.com/supergiant/control/pkg/clouds"
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 )
 
 const (
@@ -24,24 +22,18 @@ func (s *RegisterInstanceToLoadBalancer) Run(ctx context.Context, out io.Writer,
 		return errors.New("invalid config")
 	}
 
-	var step steps.Step
-
-	switch cfg.Provider {
-	case clouds.AWS:
-		step = steps.GetStep(amazon.RegisterInstanceStepName)
-	// TODO(stgleb): rest of providers TBD
-	case clouds.DigitalOcean:
-		// Load balancing in DO is made by tags
-		return nil
-	case clouds.GCE:
-		return nil
-	case clouds.Azure:
-		return nil
-	default:
-		return errors.Wrapf(fmt.Errorf("unknown provider: %s", cfg.Provider), RegisterInstanceStepName)
+	p, err := providers.Get(cfg.Provider)
+	if err != nil {
+		return errors.Wrap(err, RegisterInstanceStepName)
 	}
 
-	return step.Run(ctx, out, cfg)
+	for _, s := range p.RegisterMachineSteps() {
+		if err = s.Run(ctx, out, cfg); err != nil {
+			return errors.Wrap(err, RegisterInstanceStepName)
+		}
+	}
+
+	return nil
 }
 
 func (s *RegisterInstanceToLoadBalancer) Name() string {
@@ -59,3 +51,7 @@ func (s *RegisterInstanceToLoadBalancer) Depends() []string {
 func (s *RegisterInstanceToLoadBalancer) Rollback(context.Context, io.Writer, *steps.Config) error {
 	return nil
 }
+
+func (s *RegisterInstanceToLoadBalancer) PlanKind() steps.PlanKind {
+	return steps.PlanResource
+}
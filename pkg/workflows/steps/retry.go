@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+// DefaultRetryPolicy runs a step once, it is used for
+// steps that do not declare a policy of their own.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts: 1,
+}
+
+// RetryPolicy describes how many times a failed step is run again and
+// how long the engine waits between attempts. Delay before the n-th
+// retry is Backoff * Multiplier^(n-1) capped by MaxBackoff.
+type RetryPolicy struct {
+	// Attempts is the total number of runs including the first one
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Multiplier float64
+	// Retryable classifies errors of the step, IsRetryable is used if it is nil
+	Retryable func(error) bool
+}
+
+// Retrier is implemented by steps that declare their own retry policy
+type Retrier interface {
+	RetryPolicy() RetryPolicy
+}
+
+// GetRetryPolicy returns retry policy declared by step or the default one
+func GetRetryPolicy(step Step) RetryPolicy {
+	if r, ok := step.(Retrier); ok {
+		return r.RetryPolicy()
+	}
+
+	return DefaultRetryPolicy
+}
+
+// ShouldRetry reports whether a step that has failed with err
+// after the given number of attempts must be run again.
+func (p RetryPolicy) ShouldRetry(attempts int, err error) bool {
+	if err == nil || attempts >= p.Attempts {
+		return false
+	}
+
+	if p.Retryable != nil {
+		return IsRetryable(err) && p.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// Delay returns time to wait before the next run of a step
+// that has failed the given number of attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+
+	for i := 1; i < attempts && p.Multiplier > 1; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return delay
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Cause() error {
+	return e.err
+}
+
+// Permanent marks error of a step as the one that is not fixed by running
+// the step again, such steps fail regardless of their retry policy.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return permanentError{err: err}
+}
+
+// IsRetryable reports whether err may be caused by a transient problem,
+// cancelled steps and errors marked as permanent are not retried.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// permanent error may be wrapped by the step
+	for cause := err; cause != nil; {
+		if _, ok := cause.(permanentError); ok {
+			return false
+		}
+
+		causer, ok := cause.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		cause = causer.Cause()
+	}
+
+	// step that has timed out may still be running
+	cause := errors.Cause(err)
+	return cause != context.Canceled && cause != context.DeadlineExceeded &&
+		cause != sgerrors.ErrTimeoutExceeded
+}
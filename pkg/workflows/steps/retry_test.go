@@ -0,0 +1,89 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/supergiant/control/pkg/sgerrors"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{
+		Attempts:   5,
+		Backoff:    time.Second,
+		MaxBackoff: time.Second * 5,
+		Multiplier: 2,
+	}
+
+	for attempts, expected := range []time.Duration{
+		time.Second, time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5,
+	} {
+		if delay := policy.Delay(attempts); delay != expected {
+			t.Errorf("Wrong delay after %d attempts expected %s actual %s",
+				attempts, expected, delay)
+		}
+	}
+
+	if delay := (RetryPolicy{Backoff: time.Second}).Delay(3); delay != time.Second {
+		t.Errorf("Constant delay expected %s actual %s", time.Second, delay)
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := RetryPolicy{
+		Attempts: 3,
+	}
+	err := errors.New("error")
+
+	if !policy.ShouldRetry(1, err) || !policy.ShouldRetry(2, err) {
+		t.Errorf("Step must be retried")
+	}
+
+	if policy.ShouldRetry(3, err) {
+		t.Errorf("Step must not be retried after the last attempt")
+	}
+
+	if policy.ShouldRetry(1, nil) {
+		t.Errorf("Successful step must not be retried")
+	}
+
+	if policy.ShouldRetry(1, errors.Wrap(Permanent(err), "step")) {
+		t.Errorf("Permanent error must not be retried")
+	}
+
+	if policy.ShouldRetry(1, errors.Wrap(context.Canceled, "step")) {
+		t.Errorf("Cancelled step must not be retried")
+	}
+
+	if policy.ShouldRetry(1, errors.Wrap(sgerrors.ErrTimeoutExceeded, "step")) {
+		t.Errorf("Timed out step must not be retried")
+	}
+
+	policy.Retryable = func(error) bool { return false }
+	if policy.ShouldRetry(1, err) {
+		t.Errorf("Error must be classified by the policy")
+	}
+
+	if DefaultRetryPolicy.ShouldRetry(1, err) {
+		t.Errorf("Default policy must not retry steps")
+	}
+}
+
+type mockStep struct {
+	Step
+}
+
+func TestWithRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 2}
+
+	if p := GetRetryPolicy(mockStep{}); p.Attempts != DefaultRetryPolicy.Attempts {
+		t.Errorf("Expected default policy actual %+v", p)
+	}
+
+	if p := GetRetryPolicy(WithRetry(mockStep{}, policy)); p.Attempts != policy.Attempts {
+		t.Errorf("Expected policy %+v actual %+v", policy, p)
+	}
+}
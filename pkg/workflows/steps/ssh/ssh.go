@@ -30,12 +30,16 @@ func (s *Step) Run(ctx context.Context, writer io.Writer, config *steps.Config)
 	}
 
 	cfg := ssh.Config{
-		Host:    config.Node.PublicIp,
+		Host:    config.Kube.SSHConfig.Host(config.Node),
 		Port:    config.Kube.SSHConfig.Port,
 		User:    config.Kube.SSHConfig.User,
 		Timeout: config.Kube.SSHConfig.Timeout,
 		// TODO(stgleb): Use secure storage for private keys instead carrying them in plain text
-		Key: []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+		Key:      []byte(config.Kube.SSHConfig.BootstrapPrivateKey),
+		Bastions: ssh.Bastions(config.Kube.SSHConfig.Bastions),
+		// key of a new machine is trusted on first use and stored
+		// along with the node by the task after the step connected
+		HostKey: config.Node.HostKey,
 	}
 
 	config.Runner, err = ssh.NewRunner(cfg)
@@ -60,3 +64,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return []string{"node"}
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
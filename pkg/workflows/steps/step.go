@@ -10,6 +10,10 @@ type Step interface {
 	Run(context.Context, io.Writer, *Config) error
 	Name() string
 	Description() string
+	// Depends returns names of steps that must be completed before this
+	// step is started. Nil or list of steps that are absent in the
+	// workflow means the step runs after the previous one, empty list
+	// lets the step run as soon as the workflow starts.
 	Depends() []string
 	Rollback(context.Context, io.Writer, *Config) error
 }
@@ -40,7 +40,7 @@ func New(script *template.Template) *Step {
 }
 
 func (j *Step) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
-	err := steps.RunTemplate(context.Background(), j.script, config.Runner, out, config.TillerConfig)
+	err := steps.RunTemplate(ctx, j.script, config.Runner, out, config.TillerConfig)
 
 	if err != nil {
 		return errors.Wrap(err, "install tiller step")
@@ -64,3 +64,7 @@ func (s *Step) Description() string {
 func (s *Step) Depends() []string {
 	return []string{poststart.StepName}
 }
+
+func (s *Step) PlanKind() steps.PlanKind {
+	return steps.PlanScript
+}
@@ -0,0 +1,29 @@
+package steps
+
+import (
+	"time"
+)
+
+// DefaultTimeout limits run time of steps that do not declare a timeout
+// when config of the task has no timeout either.
+const DefaultTimeout = time.Minute * 30
+
+// Timeouter is implemented by steps that need a timeout other than
+// the one of the config, zero timeout lets the step run without deadline.
+type Timeouter interface {
+	Timeout(*Config) time.Duration
+}
+
+// GetTimeout returns time the step is allowed to run, steps that do not
+// declare a timeout are limited by timeout of the config if it is set.
+func GetTimeout(step Step, config *Config) time.Duration {
+	if t, ok := step.(Timeouter); ok {
+		return t.Timeout(config)
+	}
+
+	if config != nil && config.Timeout > 0 {
+		return config.Timeout
+	}
+
+	return DefaultTimeout
+}
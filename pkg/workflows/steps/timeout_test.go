@@ -0,0 +1,38 @@
+package steps
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTimeout(t *testing.T) {
+	if timeout := GetTimeout(mockStep{}, nil); timeout != DefaultTimeout {
+		t.Errorf("Expected default timeout %s actual %s", DefaultTimeout, timeout)
+	}
+
+	config := &Config{Timeout: time.Hour}
+	if timeout := GetTimeout(mockStep{}, config); timeout != time.Hour {
+		t.Errorf("Expected timeout of config %s actual %s", time.Hour, timeout)
+	}
+
+	step := WithTimeout(mockStep{}, time.Minute)
+	if timeout := GetTimeout(step, config); timeout != time.Minute {
+		t.Errorf("Expected timeout %s actual %s", time.Minute, timeout)
+	}
+
+	// overrides are combined
+	step = WithRetry(step, RetryPolicy{Attempts: 3})
+	if timeout := GetTimeout(step, config); timeout != time.Minute {
+		t.Errorf("Expected timeout %s actual %s", time.Minute, timeout)
+	}
+
+	if policy := GetRetryPolicy(step); policy.Attempts != 3 {
+		t.Errorf("Expected 3 attempts actual %d", policy.Attempts)
+	}
+
+	// step that is only retried is limited by the config
+	step = WithRetry(mockStep{}, RetryPolicy{Attempts: 3})
+	if timeout := GetTimeout(step, config); timeout != time.Hour {
+		t.Errorf("Expected timeout of config %s actual %s", time.Hour, timeout)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"io"
 	"runtime/debug"
+	"sync"
+	"time"
 
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
@@ -13,13 +15,16 @@ import (
 
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage"
-	"github.com/supergiant/control/pkg/util"
 	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
 
 type TaskType string
 
+// stepGracePeriod is time a timed out step is given to return after
+// its context is cancelled, the step is abandoned afterwards.
+var stepGracePeriod = time.Second * 30
+
 const (
 	MasterTask       = "master"
 	NodeTask         = "node"
@@ -33,14 +38,26 @@ const (
 // and written to persistent storage through repository, it executes
 // particular workflow of steps.
 type Task struct {
+	SchemaVersion int `json:"schemaVersion"`
+
 	ID           string          `json:"id"`
 	Type         string          `json:"type"`
 	Config       *steps.Config   `json:"config"`
 	Status       statuses.Status `json:"status"`
 	StepStatuses []StepStatus    `json:"stepsStatuses"`
 
+	// StartedAt and FinishedAt are times of the latest run of the task
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
 	workflow   Workflow
 	repository storage.Interface
+	// revision of the task record in the storage, task
+	// updates its record only if nobody else has changed it.
+	revision int64
+	// aborted is set when another run of the task has taken
+	// over its record, this run must not go on then.
+	aborted error
 }
 
 func NewTask(config *steps.Config, taskType string, repository storage.Interface) (*Task, error) {
@@ -102,6 +119,7 @@ func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser)
 		defer func() {
 			if r := recover(); r != nil {
 				t.Status = statuses.Error
+				t.finish()
 				if err := t.sync(ctx); err != nil {
 					logrus.Errorf("sync error %v for task %s", err, t.ID)
 				}
@@ -111,28 +129,20 @@ func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser)
 		}()
 
 		t.Config = &config
+		startedAt := time.Now()
+		t.StartedAt = &startedAt
+		t.FinishedAt = nil
 
 		// Save task state before first step
 		if err := t.sync(ctx); err != nil {
 			logrus.Errorf("Error saving task state %v", err)
 		}
 
-		startIndex := 0
-		// Skip successfully finished steps in case of restart
-		for index, stepStatus := range t.StepStatuses {
-			if stepStatus.Status != statuses.Success {
-				startIndex = index
-				break
-			}
-		}
-
-		logrus.Debugf("start task from step #%d startIndex %s",
-			startIndex, t.StepStatuses[startIndex].StepName)
-
-		// Start from the first step
-		err := t.startFrom(ctx, t.ID, out, startIndex)
+		// Successfully finished steps are skipped in case of restart
+		err := t.runSteps(ctx, out)
 
 		if err != nil {
+			t.finish()
 			if ctx.Err() == context.Canceled {
 				t.Status = statuses.Cancelled
 				// Save task in cancelled state
@@ -153,6 +163,7 @@ func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser)
 
 		// Set task state to success and save this state
 		t.Status = statuses.Success
+		t.finish()
 
 		if err := t.sync(ctx); err != nil {
 			logrus.Errorf("failed to sync task %s to db: %v", t.ID, err)
@@ -169,60 +180,422 @@ func (t *Task) Run(ctx context.Context, config steps.Config, out io.WriteCloser)
 	return errChan
 }
 
-// start task execution from particular step
-func (w *Task) startFrom(ctx context.Context, id string, out io.Writer, i int) error {
-	// Start workflow from the last failed step
-	wsLog := util.GetLogger(out)
-	for index := i; index < len(w.StepStatuses); index++ {
-		step := w.workflow[index]
+// Interrupt marks task that is not run by anybody anymore as failed,
+// steps left in executing state get the reason as their error message.
+func (t *Task) Interrupt(ctx context.Context, reason string) error {
+	for i := range t.StepStatuses {
+		if t.StepStatuses[i].Status == statuses.Executing {
+			t.StepStatuses[i].Status = statuses.Error
+			t.StepStatuses[i].ErrMsg = reason
+		}
+	}
 
-		wsLog.Infof("[%s] - started", step.Name())
-		logrus.Info(step.Name())
+	t.Status = statuses.Error
+	t.finish()
 
-		// sync to storage with task in executing state
-		w.Status = statuses.Executing
-		w.StepStatuses[index].Status = statuses.Executing
+	return t.sync(ctx)
+}
 
-		if err := w.sync(ctx); err != nil {
-			logrus.Errorf("sync error %v", err)
+// FailedStep returns name of the step that has failed or timed out
+func (t *Task) FailedStep() string {
+	for _, stepStatus := range t.StepStatuses {
+		if stepStatus.Status == statuses.Error || stepStatus.Status == statuses.TimedOut {
+			return stepStatus.StepName
 		}
+	}
+
+	return ""
+}
+
+func (t *Task) finish() {
+	finishedAt := time.Now()
+	t.FinishedAt = &finishedAt
+}
+
+// stepResult is sent by a finished step to the task
+type stepResult struct {
+	index int
+	err   error
+	// abandoned step has not returned after timeout and may still
+	// be running, it is neither retried nor rolled back.
+	abandoned bool
+
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+func (r stepResult) attempt(status statuses.Status) Attempt {
+	a := Attempt{
+		Status:     status,
+		StartedAt:  r.startedAt,
+		FinishedAt: r.finishedAt,
+		Duration:   r.finishedAt.Sub(r.startedAt),
+	}
+
+	if r.err != nil {
+		a.ErrMsg = r.err.Error()
+	}
+
+	return a
+}
+
+// runSteps runs steps that have not succeeded yet, a step is started as soon as
+// all steps it depends on have succeeded. Only this goroutine modifies the task,
+// steps report their results through the channel. Each step runs on its own fork
+// of the task config, the changes it has made are joined when it finishes. A failed step is run again
+// while its retry policy allows, after a step fails for good no more steps are
+// started, the steps that are already running are waited for.
+func (w *Task) runSteps(ctx context.Context, out io.Writer) error {
+	deps, err := w.workflow.dependencies()
+	if err != nil {
+		return errors.Wrapf(err, "task %s", w.ID)
+	}
+
+	if len(w.StepStatuses) != len(w.workflow) {
+		return errors.Errorf("task %s has %d step statuses for %d steps",
+			w.ID, len(w.StepStatuses), len(w.workflow))
+	}
+
+	// workflow could have been replaced by a definition since the task was created
+	for index, step := range w.workflow {
+		if name := w.StepStatuses[index].StepName; name != step.Name() {
+			return errors.Errorf("task %s has status of step %s for step %s of workflow %s",
+				w.ID, name, step.Name(), w.Type)
+		}
+	}
+
+	// Steps running at the same time share the output
+	out = &syncWriter{w: out}
+	format := getLogFormat()
+	wsLog := taskLogger(out, format)
+
+	var (
+		limit   = getStepConcurrency()
+		started = make([]bool, len(w.workflow))
+		done    = make([]bool, len(w.workflow))
+		results = make(chan stepResult, len(w.workflow))
+		running = 0
+		failure error
+		// attempts made by this run, attempts of previous runs of
+		// the task do not count against retry policy of the step
+		attempts = make([]int, len(w.workflow))
+		outputs  = make([]io.Writer, len(w.workflow))
+		configs  = make([]*steps.Config, len(w.workflow))
+	)
+
+	for index, stepStatus := range w.StepStatuses {
+		if stepStatus.Status == statuses.Success {
+			started[index] = true
+			done[index] = true
+		}
+	}
+
+	for {
+		for index, step := range w.workflow {
+			if failure != nil || w.aborted != nil || ctx.Err() != nil || running >= limit {
+				break
+			}
+
+			if started[index] || !allResolved(deps[index], done) {
+				continue
+			}
+
+			wsLog.WithField("step", step.Name()).Infof("[%s] - started", step.Name())
+			logrus.Info(step.Name())
+
+			// sync to storage with task in executing state
+			startedAt := time.Now()
+			w.Status = statuses.Executing
+			w.StepStatuses[index].Status = statuses.Executing
+			w.StepStatuses[index].StartedAt = &startedAt
+			w.StepStatuses[index].FinishedAt = nil
+			w.StepStatuses[index].Duration = 0
 
-		if err := step.Run(ctx, out, w.Config); err != nil {
-			// Mark step status as error
-			w.StepStatuses[index].Status = statuses.Error
-			w.Status = statuses.Error
-			w.StepStatuses[index].ErrMsg = err.Error()
 			if err := w.sync(ctx); err != nil {
-				logrus.Errorf("error syncing %v", err)
+				logrus.Errorf("sync error %v", err)
 			}
 
-			wsLog.Infof("[%s] - failed: %s", step.Name(), err.Error())
-			if err2 := w.sync(ctx); err2 != nil {
-				logrus.Errorf("sync error %v for step %s", err2, step.Name())
+			started[index] = true
+			running++
+			outputs[index] = stepOutput(out, format, step.Name())
+			configs[index] = w.Config.Fork()
+
+			go func(index int, step steps.Step, config *steps.Config) {
+				results <- runAttempt(ctx, index, step, outputs[index], config)
+			}(index, step, configs[index])
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		step := w.workflow[result.index]
+		stepLog := wsLog.WithField("step", step.Name())
+		attempts[result.index]++
+		// failed attempts are joined too, so that rollback knows about
+		// resources the step has created, but config of the abandoned
+		// step may still be changed.
+		if !result.abandoned {
+			w.Config.Join(configs[result.index])
+			w.recordHostKey()
+		}
+
+		if result.err != nil {
+			stepStatus := statuses.Error
+			if sgerrors.IsTimeoutExceeded(result.err) {
+				stepStatus = statuses.TimedOut
 			}
 
-			if err3 := step.Rollback(ctx, out, w.Config); err3 != nil {
-				logrus.Errorf("rollback: step %s : %v", step.Name(), err3)
+			w.StepStatuses[result.index].Attempts = append(w.StepStatuses[result.index].Attempts,
+				result.attempt(stepStatus))
+
+			policy := steps.GetRetryPolicy(step)
+			if failure == nil && w.aborted == nil && ctx.Err() == nil && !result.abandoned && policy.ShouldRetry(attempts[result.index], result.err) {
+				delay := policy.Delay(attempts[result.index])
+				stepLog.Infof("[%s] - attempt %d of %d failed: %s, retry in %s", step.Name(),
+					attempts[result.index], policy.Attempts, result.err.Error(), delay)
+
+				if err := w.sync(ctx); err != nil {
+					logrus.Errorf("sync error %v for step %s", err, step.Name())
+				}
+
+				running++
+				configs[result.index] = w.Config.Fork()
+				go func(index int, step steps.Step, config *steps.Config) {
+					select {
+					case <-time.After(delay):
+						results <- runAttempt(ctx, index, step, outputs[index], config)
+					case <-ctx.Done():
+						now := time.Now()
+						results <- stepResult{
+							index:      index,
+							err:        ctx.Err(),
+							startedAt:  now,
+							finishedAt: now,
+						}
+					}
+				}(result.index, step, configs[result.index])
+				continue
 			}
 
-			return err
-		} else {
-			wsLog.Infof("[%s] - success", step.Name())
-			// Mark step as success
-			w.StepStatuses[index].Status = statuses.Success
-			w.StepStatuses[index].ErrMsg = ""
-			w.Status = statuses.Success
+			// Mark step status as error
+			flushOutput(outputs[result.index])
+			w.StepStatuses[result.index].Status = stepStatus
+			w.StepStatuses[result.index].ErrMsg = result.err.Error()
+			w.StepStatuses[result.index].finish(result.finishedAt)
+			w.Status = statuses.Error
 			if err := w.sync(ctx); err != nil {
 				logrus.Errorf("sync error %v for step %s", err, step.Name())
 			}
+
+			stepLog.Infof("[%s] - failed: %s", step.Name(), result.err.Error())
+
+			if result.abandoned {
+				logrus.Errorf("step %s has been abandoned without rollback", step.Name())
+			} else if err := step.Rollback(ctx, outputs[result.index], w.Config); err != nil {
+				logrus.Errorf("rollback: step %s : %v", step.Name(), err)
+			}
+
+			if failure == nil {
+				failure = result.err
+			}
+			continue
+		}
+
+		flushOutput(outputs[result.index])
+		stepLog.Infof("[%s] - success", step.Name())
+		// Mark step as success
+		done[result.index] = true
+		w.StepStatuses[result.index].Attempts = append(w.StepStatuses[result.index].Attempts,
+			result.attempt(statuses.Success))
+		w.StepStatuses[result.index].Status = statuses.Success
+		w.StepStatuses[result.index].ErrMsg = ""
+		w.StepStatuses[result.index].finish(result.finishedAt)
+		if err := w.sync(ctx); err != nil {
+			logrus.Errorf("sync error %v for step %s", err, step.Name())
+		}
+	}
+
+	// resources belong to the run that has taken over the task
+	if w.aborted != nil {
+		return w.aborted
+	}
+
+	if failure != nil {
+		if ctx.Err() == nil && w.Config != nil && w.Config.RollbackOnFailure {
+			w.rollback(ctx, out, format, wsLog, deps)
+		}
+		return failure
+	}
+
+	for index := range done {
+		if !done[index] {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.Errorf("step %s has not been run", w.workflow[index].Name())
 		}
 	}
 
 	return nil
 }
 
+// rollback undoes steps that have succeeded in reverse order, the failed
+// step has been rolled back already. Rollback goes on when a step fails to
+// roll back, so that as many resources as possible are cleaned up.
+func (w *Task) rollback(ctx context.Context, out io.Writer, format LogFormat, wsLog *logrus.Logger, deps [][]int) {
+	for _, index := range rollbackOrder(deps) {
+		if w.StepStatuses[index].Status != statuses.Success {
+			continue
+		}
+
+		step := w.workflow[index]
+		stepLog := wsLog.WithField("step", step.Name())
+		stepOut := stepOutput(out, format, step.Name())
+		stepLog.Infof("[%s] - rolling back", step.Name())
+
+		w.StepStatuses[index].Status = statuses.RollingBack
+		if err := w.sync(ctx); err != nil {
+			logrus.Errorf("sync error %v for step %s", err, step.Name())
+		}
+
+		err := callRollback(ctx, step, stepOut, w.Config)
+		flushOutput(stepOut)
+
+		if err != nil {
+			stepLog.Infof("[%s] - rollback failed: %s", step.Name(), err.Error())
+			logrus.Errorf("rollback: step %s : %v", step.Name(), err)
+
+			w.StepStatuses[index].Status = statuses.RollbackFailed
+			w.StepStatuses[index].ErrMsg = err.Error()
+		} else {
+			stepLog.Infof("[%s] - rolled back", step.Name())
+			w.StepStatuses[index].Status = statuses.RolledBack
+		}
+
+		if err := w.sync(ctx); err != nil {
+			logrus.Errorf("sync error %v for step %s", err, step.Name())
+		}
+	}
+}
+
+// runAttempt runs the step once and measures the time it took
+func runAttempt(ctx context.Context, index int, step steps.Step, out io.Writer, config *steps.Config) stepResult {
+	startedAt := time.Now()
+	abandoned, err := runStep(ctx, step, out, config)
+
+	return stepResult{
+		index:      index,
+		err:        err,
+		abandoned:  abandoned,
+		startedAt:  startedAt,
+		finishedAt: time.Now(),
+	}
+}
+
+// runStep runs the step with its timeout, step that does not return in time
+// has its context cancelled and is waited for during grace period. The step
+// that has not returned even then is abandoned running in background.
+func runStep(ctx context.Context, step steps.Step, out io.Writer, config *steps.Config) (bool, error) {
+	timeout := steps.GetTimeout(step, config)
+	if timeout <= 0 {
+		return false, callStep(ctx, step, out, config)
+	}
+
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- callStep(stepCtx, step, out, config)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && ctx.Err() == nil && stepCtx.Err() == context.DeadlineExceeded {
+			return false, errors.Wrapf(sgerrors.ErrTimeoutExceeded, "step %s has not finished in %s",
+				step.Name(), timeout)
+		}
+		return false, err
+	case <-stepCtx.Done():
+		// cancelled steps are waited for as before
+		if ctx.Err() != nil {
+			return false, <-errCh
+		}
+	}
+
+	select {
+	case <-errCh:
+		return false, errors.Wrapf(sgerrors.ErrTimeoutExceeded, "step %s has not finished in %s",
+			step.Name(), timeout)
+	case <-time.After(stepGracePeriod):
+		return true, errors.Wrapf(sgerrors.ErrTimeoutExceeded, "step %s has not finished in %s "+
+			"and has not returned in %s after cancellation", step.Name(), timeout, stepGracePeriod)
+	}
+}
+
+// callStep turns panic of a step running in its own goroutine into error
+func callStep(ctx context.Context, step steps.Step, out io.Writer, config *steps.Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			debug.PrintStack()
+			err = errors.Errorf("step %s: unexpected panic: %v", step.Name(), r)
+		}
+	}()
+
+	return step.Run(ctx, out, config)
+}
+
+// callRollback turns panic of a step rollback into error
+func callRollback(ctx context.Context, step steps.Step, out io.Writer, config *steps.Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			debug.PrintStack()
+			err = errors.Errorf("rollback of step %s: unexpected panic: %v", step.Name(), r)
+		}
+	}()
+
+	return step.Rollback(ctx, out, config)
+}
+
+// syncWriter serializes writes of steps running at the same time
+type syncWriter struct {
+	m sync.Mutex
+	w io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.w.Write(p)
+}
+
+// hostKeyRunner is a runner that verifies key of the host
+type hostKeyRunner interface {
+	HostKey() string
+}
+
+// recordHostKey stores the key the machine has been trusted with on first
+// connection, so that it is synced with the task and verified by later runs.
+func (w *Task) recordHostKey() {
+	if w.Config == nil || w.Config.Node.HostKey != "" {
+		return
+	}
+
+	if r, ok := w.Config.Runner.(hostKeyRunner); ok {
+		w.Config.Node.HostKey = r.HostKey()
+	}
+}
+
 // synchronize state of workflow to storage
 func (w *Task) sync(ctx context.Context) error {
+	if w.aborted != nil {
+		return w.aborted
+	}
+
+	w.SchemaVersion = TaskSchemaVersion
 	data, err := json.Marshal(w)
 	buf := &bytes.Buffer{}
 
@@ -236,5 +609,49 @@ func (w *Task) sync(ctx context.Context) error {
 		return err
 	}
 
-	return w.repository.Put(ctx, Prefix, w.ID, buf.Bytes())
+	revision, err := w.repository.PutIfRevision(ctx, Prefix, w.ID, buf.Bytes(), w.revision)
+	if sgerrors.IsConflict(err) {
+		revision, err = w.resolveConflict(ctx, buf.Bytes())
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "put task %s revision %d", w.ID, w.revision)
+	}
+
+	w.revision = revision
+	return nil
+}
+
+// resolveConflict handles the task record changed by somebody else. The record
+// is overwritten while it belongs to this run of the task, as it has been changed
+// by re-encryption or by recovery that has taken the task for interrupted. When
+// the task has been started again, this run is aborted and leaves the record as is.
+func (w *Task) resolveConflict(ctx context.Context, data []byte) (int64, error) {
+	stored, revision, err := w.repository.GetWithRevision(ctx, Prefix, w.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "read conflicting task")
+	}
+
+	current := struct {
+		StartedAt *time.Time `json:"startedAt"`
+	}{}
+	if err := json.Unmarshal(stored, &current); err != nil {
+		return 0, errors.Wrap(err, "read conflicting task")
+	}
+
+	if !sameTime(current.StartedAt, w.StartedAt) {
+		w.aborted = errors.Wrapf(sgerrors.ErrConflict, "task %s has been run again", w.ID)
+		return 0, w.aborted
+	}
+
+	logrus.Warnf("task %s has been modified concurrently, overwrite revision %d", w.ID, revision)
+	return w.repository.PutIfRevision(ctx, Prefix, w.ID, data, revision)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
 }
@@ -9,10 +9,14 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/supergiant/control/pkg/runner"
 	"github.com/supergiant/control/pkg/sgerrors"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/storage/watch"
 	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
 )
@@ -48,6 +52,28 @@ func (f *MockRepository) Delete(ctx context.Context, prefix string, key string)
 	return nil
 }
 
+func (f *MockRepository) Watch(ctx context.Context, prefix string) (<-chan watch.Event, error) {
+	return nil, nil
+}
+
+func (f *MockRepository) List(ctx context.Context, prefix string, opts paging.Options) (*paging.Page, error) {
+	return &paging.Page{}, nil
+}
+
+func (f *MockRepository) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *MockRepository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	return f.storage[prefix+key], 0, nil
+}
+
+func (f *MockRepository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	f.storage[prefix+key] = value
+
+	return revision + 1, nil
+}
+
 type MockStep struct {
 	name        string
 	description string
@@ -179,6 +205,55 @@ func TestTaskRunError(t *testing.T) {
 	}
 }
 
+type fakeRunner struct {
+	hostKey string
+}
+
+func (r *fakeRunner) Run(*runner.Command) error {
+	return nil
+}
+
+func (r *fakeRunner) HostKey() string {
+	return r.hostKey
+}
+
+type connectStep struct {
+	MockStep
+	runner runner.Runner
+}
+
+func (s *connectStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	config.Runner = s.runner
+	return nil
+}
+
+func TestTaskRunRecordsHostKey(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	wf := []steps.Step{
+		&connectStep{
+			MockStep: MockStep{name: "ssh"},
+			runner:   &fakeRunner{hostKey: "ssh-ed25519 AAAA"},
+		},
+		&MockStep{name: "docker", errs: []error{errors.New("error")}},
+	}
+
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+
+	err = <-task.Run(context.Background(), steps.Config{}, &bufferCloser{})
+	require.Error(t, err)
+
+	// key is stored although the task has failed before the last step
+	stored := &Task{}
+	require.NoError(t, json.Unmarshal(s.storage[Prefix+task.ID], stored))
+	require.Equal(t, "ssh-ed25519 AAAA", stored.Config.Node.HostKey)
+}
+
 func TestTaskRunSuccess(t *testing.T) {
 	s := &MockRepository{
 		storage: make(map[string][]byte),
@@ -275,6 +350,38 @@ func TestWorkflowRestart(t *testing.T) {
 	}
 }
 
+func TestWorkflowRestartReplaced(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	first, second := registerDefinitionSteps()
+	second.errs = []error{errors.New("error")}
+	workflowMap = make(map[string]Workflow)
+	definitionMap = make(map[string]Definition)
+	RegisterWorkFlow("mock", []steps.Step{first, second})
+
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+	require.Error(t, <-task.Run(context.Background(), steps.Config{}, &bufferCloser{}))
+
+	// workflow is replaced with other steps before the task is restarted
+	replacement := &MockStep{name: "definition_replacement"}
+	steps.RegisterStep(replacement.name, replacement)
+	require.NoError(t, RegisterDefinition(Definition{
+		Name:  "mock",
+		Steps: []StepDefinition{{Name: first.name}, {Name: replacement.name}},
+	}))
+
+	restored, err := DeserializeTask(s.storage[Prefix+task.ID], s)
+	require.NoError(t, err)
+
+	err = <-restored.Run(context.Background(), steps.Config{}, &bufferCloser{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), second.name)
+	require.Equal(t, 0, replacement.counter)
+}
+
 func TestRollback(t *testing.T) {
 	s := &MockRepository{
 		storage: make(map[string][]byte),
@@ -298,6 +405,90 @@ func TestRollback(t *testing.T) {
 	require.True(t, mockStep.rollback)
 }
 
+// rollbackStep records the order steps are rolled back in
+type rollbackStep struct {
+	MockStep
+	rolledBack *[]string
+	err        error
+}
+
+func (s *rollbackStep) Rollback(context.Context, io.Writer, *steps.Config) error {
+	*s.rolledBack = append(*s.rolledBack, s.name)
+	return s.err
+}
+
+func TestRollbackOnFailure(t *testing.T) {
+	testCases := []struct {
+		description string
+		enabled     bool
+
+		expectedRolledBack []string
+		expectedStatuses   []statuses.Status
+	}{
+		{
+			description:        "disabled",
+			expectedRolledBack: []string{"step3"},
+			expectedStatuses: []statuses.Status{
+				statuses.Success,
+				statuses.Success,
+				statuses.Error,
+			},
+		},
+		{
+			description:        "enabled",
+			enabled:            true,
+			expectedRolledBack: []string{"step3", "step2", "step1"},
+			expectedStatuses: []statuses.Status{
+				statuses.RolledBack,
+				statuses.RollbackFailed,
+				statuses.Error,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Log(testCase.description)
+
+		var rolledBack []string
+		wf := []steps.Step{
+			&rollbackStep{
+				MockStep:   MockStep{name: "step1"},
+				rolledBack: &rolledBack,
+			},
+			&rollbackStep{
+				MockStep:   MockStep{name: "step2"},
+				rolledBack: &rolledBack,
+				err:        errors.New("rollback error"),
+			},
+			&rollbackStep{
+				MockStep:   MockStep{name: "step3", errs: []error{errors.New("error")}},
+				rolledBack: &rolledBack,
+			},
+		}
+
+		workflowMap = make(map[string]Workflow)
+		RegisterWorkFlow("mock", wf)
+		task, err := NewTask(&steps.Config{}, "mock", &MockRepository{
+			storage: make(map[string][]byte),
+		})
+		require.NoError(t, err)
+
+		err = <-task.Run(context.Background(), steps.Config{
+			RollbackOnFailure: testCase.enabled,
+		}, &bufferCloser{})
+		require.Error(t, err)
+
+		require.Equal(t, testCase.expectedRolledBack, rolledBack)
+		for i, status := range testCase.expectedStatuses {
+			require.Equal(t, status, task.StepStatuses[i].Status, task.StepStatuses[i].StepName)
+		}
+
+		if testCase.enabled {
+			require.Equal(t, "rollback error", task.StepStatuses[1].ErrMsg)
+		}
+	}
+}
+
 type PanicStep struct {
 }
 
@@ -339,3 +530,255 @@ func TestPanicHandler(t *testing.T) {
 	err := <-errChan
 	require.Error(t, err)
 }
+
+func TestTaskRunRetry(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	errMsg := "temporary failure"
+	step := &MockStep{name: "step1", errs: []error{errors.New(errMsg), nil}}
+	wf := []steps.Step{
+		steps.WithRetry(step, steps.RetryPolicy{
+			Attempts: 2,
+			Backoff:  time.Millisecond,
+		}),
+	}
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+
+	buffer := &bufferCloser{}
+	err = <-task.Run(context.Background(), steps.Config{}, buffer)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, step.counter)
+	require.False(t, step.rollback)
+	require.Equal(t, statuses.Success, task.StepStatuses[0].Status)
+	attempts := task.StepStatuses[0].Attempts
+	require.Len(t, attempts, 2)
+	require.Equal(t, statuses.Error, attempts[0].Status)
+	require.Equal(t, errMsg, attempts[0].ErrMsg)
+	require.Equal(t, statuses.Success, attempts[1].Status)
+	require.Empty(t, attempts[1].ErrMsg)
+	require.Contains(t, buffer.String(), "attempt 1 of 2 failed")
+
+	// the second attempt starts after backoff of the first one
+	require.True(t, attempts[1].StartedAt.Sub(attempts[0].FinishedAt) >= time.Millisecond)
+	for _, attempt := range attempts {
+		require.Equal(t, attempt.FinishedAt.Sub(attempt.StartedAt), attempt.Duration)
+	}
+
+	stepStatus := task.StepStatuses[0]
+	require.NotNil(t, stepStatus.StartedAt)
+	require.NotNil(t, stepStatus.FinishedAt)
+	require.Equal(t, stepStatus.FinishedAt.Sub(*stepStatus.StartedAt), stepStatus.Duration)
+	require.True(t, stepStatus.Duration >= attempts[0].Duration+attempts[1].Duration)
+	require.NotNil(t, task.StartedAt)
+	require.NotNil(t, task.FinishedAt)
+	require.False(t, task.FinishedAt.Before(*task.StartedAt))
+}
+
+type hangingStep struct {
+	MockStep
+}
+
+func (s *hangingStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTaskRunTimeout(t *testing.T) {
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	wf := []steps.Step{
+		steps.WithTimeout(&hangingStep{MockStep{name: "step1"}}, time.Millisecond*10),
+		&MockStep{name: "step2"},
+	}
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+
+	err = <-task.Run(context.Background(), steps.Config{}, &bufferCloser{})
+	require.True(t, sgerrors.IsTimeoutExceeded(err), "unexpected error %v", err)
+
+	require.Equal(t, statuses.Error, task.Status)
+	require.Equal(t, statuses.TimedOut, task.StepStatuses[0].Status)
+	require.Equal(t, statuses.Todo, task.StepStatuses[1].Status)
+}
+
+// revisionRepository fails to put a record that has been changed concurrently
+type revisionRepository struct {
+	MockRepository
+	revisions map[string]int64
+}
+
+func newRevisionRepository() *revisionRepository {
+	return &revisionRepository{
+		MockRepository: MockRepository{storage: make(map[string][]byte)},
+		revisions:      make(map[string]int64),
+	}
+}
+
+func (r *revisionRepository) GetWithRevision(ctx context.Context, prefix string, key string) ([]byte, int64, error) {
+	return r.storage[prefix+key], r.revisions[prefix+key], nil
+}
+
+func (r *revisionRepository) PutIfRevision(ctx context.Context, prefix string, key string, value []byte, revision int64) (int64, error) {
+	if r.revisions[prefix+key] != revision {
+		return 0, sgerrors.ErrConflict
+	}
+
+	r.storage[prefix+key] = value
+	r.revisions[prefix+key]++
+
+	return r.revisions[prefix+key], nil
+}
+
+// change replaces the stored task the way another writer does
+func (r *revisionRepository) change(t *testing.T, id string, startedAt time.Time) {
+	data, err := json.Marshal(&Task{ID: id, Status: statuses.Error, StartedAt: &startedAt})
+	require.NoError(t, err)
+
+	r.storage[Prefix+id] = data
+	r.revisions[Prefix+id]++
+}
+
+func TestTaskSyncConflict(t *testing.T) {
+	startedAt := time.Now()
+
+	testCases := []struct {
+		name      string
+		startedAt time.Time
+
+		conflict bool
+	}{
+		{
+			name:      "record of the same run is overwritten",
+			startedAt: startedAt,
+		},
+		{
+			name:      "task has been run again",
+			startedAt: startedAt.Add(time.Minute),
+			conflict:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		repo := newRevisionRepository()
+		task := newTask("mock", nil, repo)
+		task.StartedAt = &startedAt
+		task.Status = statuses.Executing
+		require.NoError(t, task.sync(context.Background()), testCase.name)
+
+		repo.change(t, task.ID, testCase.startedAt)
+		stored := repo.storage[Prefix+task.ID]
+
+		err := task.sync(context.Background())
+		if !testCase.conflict {
+			require.NoError(t, err, testCase.name)
+			require.Contains(t, string(repo.storage[Prefix+task.ID]), statuses.Executing, testCase.name)
+			continue
+		}
+
+		require.True(t, sgerrors.IsConflict(err), testCase.name)
+		require.Equal(t, stored, repo.storage[Prefix+task.ID], testCase.name)
+
+		// record of the other run is not touched anymore
+		err = task.sync(context.Background())
+		require.True(t, sgerrors.IsConflict(err), testCase.name)
+		require.Equal(t, stored, repo.storage[Prefix+task.ID], testCase.name)
+	}
+}
+
+// restartStep simulates the task being run again by somebody else
+type restartStep struct {
+	MockStep
+	restart func()
+}
+
+func (s *restartStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	s.restart()
+	return nil
+}
+
+func TestTaskRunAborted(t *testing.T) {
+	repo := newRevisionRepository()
+	second := &MockStep{name: "step2"}
+
+	var task *Task
+	wf := []steps.Step{
+		&restartStep{
+			MockStep: MockStep{name: "step1"},
+			restart: func() {
+				repo.change(t, task.ID, time.Now().Add(time.Minute))
+			},
+		},
+		second,
+	}
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{RollbackOnFailure: true}, "mock", repo)
+	require.NoError(t, err)
+
+	err = <-task.Run(context.Background(), steps.Config{RollbackOnFailure: true}, &bufferCloser{})
+	require.True(t, sgerrors.IsConflict(err), "unexpected error %v", err)
+	require.Equal(t, 0, second.counter)
+	require.False(t, second.rollback)
+}
+
+// stubbornStep ignores cancellation and returns once released
+type stubbornStep struct {
+	MockStep
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *stubbornStep) Run(ctx context.Context, out io.Writer, config *steps.Config) error {
+	s.started <- struct{}{}
+	<-s.release
+	config.AWSConfig.VPCID = "vpc-1"
+	return nil
+}
+
+func TestTaskRunAbandoned(t *testing.T) {
+	defer func(gracePeriod time.Duration) {
+		stepGracePeriod = gracePeriod
+	}(stepGracePeriod)
+	stepGracePeriod = time.Millisecond * 10
+
+	s := &MockRepository{
+		storage: make(map[string][]byte),
+	}
+
+	step := &stubbornStep{
+		MockStep: MockStep{name: "step1"},
+		started:  make(chan struct{}, 2),
+		release:  make(chan struct{}),
+	}
+	defer close(step.release)
+
+	wf := []steps.Step{
+		steps.WithRetry(steps.WithTimeout(step, time.Millisecond*10), steps.RetryPolicy{
+			Attempts: 2,
+			Backoff:  time.Millisecond,
+		}),
+	}
+	workflowMap = make(map[string]Workflow)
+	RegisterWorkFlow("mock", wf)
+	task, err := NewTask(&steps.Config{}, "mock", s)
+	require.NoError(t, err)
+
+	err = <-task.Run(context.Background(), steps.Config{}, &bufferCloser{})
+	require.True(t, sgerrors.IsTimeoutExceeded(err), "unexpected error %v", err)
+
+	// abandoned step is neither retried nor rolled back
+	require.Len(t, step.started, 1)
+	require.False(t, step.rollback)
+	require.Equal(t, statuses.TimedOut, task.StepStatuses[0].Status)
+	require.Len(t, task.StepStatuses[0].Attempts, 1)
+}
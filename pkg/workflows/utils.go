@@ -1,11 +1,121 @@
 package workflows
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
 	"github.com/supergiant/control/pkg/runner/ssh"
 	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/paging"
+	"github.com/supergiant/control/pkg/workflows/statuses"
 )
 
+// TaskFilter selects tasks in listings, empty fields match any value.
+type TaskFilter struct {
+	Status statuses.Status
+	// Type is either name of task workflow or kind of the task, e.g. master
+	Type      string
+	ClusterID string
+	// Since and Until limit time the task has been started at
+	Since time.Time
+	Until time.Time
+	// FailedStep selects tasks where the step has failed or timed out
+	FailedStep string
+}
+
+func (f TaskFilter) Matches(task *Task) bool {
+	return (f.Status == "" || f.Status == task.Status) &&
+		(f.Type == "" || f.Type == task.Type || f.Type == TaskKind(task.Type)) &&
+		(f.ClusterID == "" || task.Config != nil && f.ClusterID == task.Config.ClusterID) &&
+		f.matchesTime(task) &&
+		(f.FailedStep == "" || f.FailedStep == task.FailedStep())
+}
+
+func (f TaskFilter) matchesTime(task *Task) bool {
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+
+	if task.StartedAt == nil {
+		return false
+	}
+
+	return (f.Since.IsZero() || !task.StartedAt.Before(f.Since)) &&
+		(f.Until.IsZero() || task.StartedAt.Before(f.Until))
+}
+
+// TaskKind returns kind of tasks the workflow is used for, kinds
+// group tasks of a kube, empty kind is returned for other workflows.
+func TaskKind(workflowType string) string {
+	switch workflowType {
+	case ProvisionMaster:
+		return MasterTask
+	case ProvisionNode:
+		return NodeTask
+	case PostProvision:
+		return ClusterTask
+	case DeleteCluster, DeleteNode:
+		return DeleteTask
+	case ImportCluster:
+		return ImportTask
+	}
+
+	if strings.HasSuffix(workflowType, Infra) {
+		return PreProvisionTask
+	}
+
+	return ""
+}
+
+// ListTasks returns a page of stored tasks that match the filter along
+// with continue token of the next page.
+func ListTasks(ctx context.Context, repository storage.Interface, filter TaskFilter, opts paging.Options) ([]*Task, string, error) {
+	tasks := make([]*Task, 0)
+
+	page, err := storage.ListFiltered(ctx, repository, Prefix, opts, func(item paging.Item) (bool, error) {
+		task := &Task{}
+		if err := json.Unmarshal(item.Value, task); err != nil {
+			logrus.Warnf("skip corrupted task %s: %v", item.Key, err)
+			return false, nil
+		}
+
+		if !filter.Matches(task) {
+			return false, nil
+		}
+
+		tasks = append(tasks, task)
+		return true, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tasks, page.Continue, nil
+}
+
+// LoadTask reads task from repository along with its revision, so
+// the task can be run again without overwriting concurrent changes.
+func LoadTask(ctx context.Context, id string, repository storage.Interface) (*Task, error) {
+	data, revision, err := repository.GetWithRevision(ctx, Prefix, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := DeserializeTask(data, repository)
+
+	if err != nil {
+		return nil, err
+	}
+
+	task.revision = revision
+	return task, nil
+}
+
 func DeserializeTask(data []byte, repository storage.Interface) (*Task, error) {
 	task := &Task{}
 	err := json.Unmarshal(data, task)
@@ -20,13 +130,15 @@ func DeserializeTask(data []byte, repository storage.Interface) (*Task, error) {
 	// NOTE(stgleb): If step has failed on machine creation state
 	// public ip will be blank and lead to error when restart
 	// TODO(stgleb): Move ssh runner creation to task Restart method
-	if task.Config != nil && task.Config.Node.PublicIp != "" {
+	if task.Config != nil && task.Config.Kube.SSHConfig.Host(task.Config.Node) != "" {
 		cfg := ssh.Config{
-			Host:    task.Config.Node.PublicIp,
-			Port:    task.Config.Kube.SSHConfig.Port,
-			User:    task.Config.Kube.SSHConfig.User,
-			Timeout: task.Config.Kube.SSHConfig.Timeout,
-			Key:     []byte(task.Config.Kube.SSHConfig.BootstrapPrivateKey),
+			Host:     task.Config.Kube.SSHConfig.Host(task.Config.Node),
+			Port:     task.Config.Kube.SSHConfig.Port,
+			User:     task.Config.Kube.SSHConfig.User,
+			Timeout:  task.Config.Kube.SSHConfig.Timeout,
+			Key:      []byte(task.Config.Kube.SSHConfig.BootstrapPrivateKey),
+			Bastions: ssh.Bastions(task.Config.Kube.SSHConfig.Bastions),
+			HostKey:  task.Config.Node.HostKey,
 		}
 
 		task.Config.Runner, err = ssh.NewRunner(cfg)
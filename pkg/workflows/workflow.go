@@ -1,24 +1,23 @@
 package workflows
 
 import (
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/supergiant/control/pkg/providers"
 	"github.com/supergiant/control/pkg/workflows/statuses"
 	"github.com/supergiant/control/pkg/workflows/steps"
-	"github.com/supergiant/control/pkg/workflows/steps/amazon"
 	"github.com/supergiant/control/pkg/workflows/steps/apply"
 	"github.com/supergiant/control/pkg/workflows/steps/authorizedkeys"
-	"github.com/supergiant/control/pkg/workflows/steps/azure"
 	"github.com/supergiant/control/pkg/workflows/steps/bootstraptoken"
 	"github.com/supergiant/control/pkg/workflows/steps/certificates"
 	"github.com/supergiant/control/pkg/workflows/steps/clustercheck"
 	"github.com/supergiant/control/pkg/workflows/steps/configmap"
-	"github.com/supergiant/control/pkg/workflows/steps/digitalocean"
 	"github.com/supergiant/control/pkg/workflows/steps/docker"
 	"github.com/supergiant/control/pkg/workflows/steps/downloadk8sbinary"
 	"github.com/supergiant/control/pkg/workflows/steps/drain"
 	"github.com/supergiant/control/pkg/workflows/steps/evacuate"
-	"github.com/supergiant/control/pkg/workflows/steps/gce"
 	"github.com/supergiant/control/pkg/workflows/steps/kubeadm"
 	"github.com/supergiant/control/pkg/workflows/steps/kubelet"
 	"github.com/supergiant/control/pkg/workflows/steps/network"
@@ -38,6 +37,33 @@ type StepStatus struct {
 	Status   statuses.Status `json:"status"`
 	StepName string          `json:"stepName"`
 	ErrMsg   string          `json:"errorMessage"`
+
+	// StartedAt and FinishedAt are times of the latest run of the step,
+	// Duration of the run includes all attempts and delays between them.
+	StartedAt  *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt *time.Time    `json:"finishedAt,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+
+	// Attempts holds outcome of every run of the step
+	Attempts []Attempt `json:"attempts,omitempty"`
+}
+
+// Attempt is a single run of a step, failed attempts are
+// followed by the next one if retry policy of the step allows.
+type Attempt struct {
+	Status statuses.Status `json:"status"`
+	ErrMsg string          `json:"errorMessage,omitempty"`
+
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt time.Time     `json:"finishedAt"`
+	Duration   time.Duration `json:"duration"`
+}
+
+func (s *StepStatus) finish(finishedAt time.Time) {
+	s.FinishedAt = &finishedAt
+	if s.StartedAt != nil {
+		s.Duration = finishedAt.Sub(*s.StartedAt)
+	}
 }
 
 // Workflow is a template for doing some actions
@@ -46,6 +72,13 @@ type Workflow []steps.Step
 const (
 	Prefix = "tasks"
 
+	// TaskSchemaVersion is a version of stored task record
+	TaskSchemaVersion = 1
+
+	// DefaultStepConcurrency is a number of independent steps
+	// of a single task that are allowed to run at the same time.
+	DefaultStepConcurrency = 4
+
 	PostProvision     = "PostProvision"
 	Infra             = "Infra"
 	AwsInfra          = "awsInfra"
@@ -71,47 +104,15 @@ type WorkflowSet struct {
 }
 
 var (
-	m           sync.RWMutex
-	workflowMap map[string]Workflow
+	m               sync.RWMutex
+	workflowMap     map[string]Workflow
+	definitionMap   map[string]Definition
+	stepConcurrency = DefaultStepConcurrency
 )
 
 func Init() {
 	workflowMap = make(map[string]Workflow)
-
-	awsInfra := []steps.Step{
-		steps.GetStep(amazon.StepFindAMI),
-		steps.GetStep(amazon.StepCreateVPC),
-		steps.GetStep(amazon.StepCreateSecurityGroups),
-		steps.GetStep(amazon.StepNameCreateInstanceProfiles),
-		steps.GetStep(amazon.ImportKeyPairStepName),
-		steps.GetStep(amazon.StepCreateInternetGateway),
-		steps.GetStep(amazon.StepCreateSubnets),
-		steps.GetStep(amazon.StepCreateRouteTable),
-		steps.GetStep(amazon.StepAssociateRouteTable),
-		steps.GetStep(amazon.StepCreateLoadBalancer),
-	}
-
-	digitalOceanInfra := []steps.Step{
-		steps.GetStep(digitalocean.CreateLoadBalancerStepName),
-	}
-
-	gceInfra := []steps.Step{
-		steps.GetStep(gce.CreateNetworksStepName),
-		steps.GetStep(gce.CreateIPAddressStepName),
-		steps.GetStep(gce.CreateTargetPullStepName),
-		steps.GetStep(gce.CreateInstanceGroupsStepName),
-		steps.GetStep(gce.CreateHealthCheckStepName),
-		steps.GetStep(gce.CreateBackendServiceStepName),
-		steps.GetStep(gce.CreateForwardingRulesStepName),
-	}
-
-	azureInfra := []steps.Step{
-		steps.GetStep(azure.GetAuthorizerStepName),
-		steps.GetStep(azure.CreateGroupStepName),
-		steps.GetStep(azure.CreateVNetAndSubnetsStepName),
-		steps.GetStep(azure.CreateSecurityGroupStepName),
-		steps.GetStep(azure.CreateLBStepName),
-	}
+	definitionMap = make(map[string]Definition)
 
 	masterWorkflow := []steps.Step{
 		// TODO(stgleb): Provider steps should also register itsels it step map
@@ -180,10 +181,9 @@ func Init() {
 	m.Lock()
 	defer m.Unlock()
 
-	workflowMap[AwsInfra] = awsInfra
-	workflowMap[DigitalOceanInfra] = digitalOceanInfra
-	workflowMap[GCEInfra] = gceInfra
-	workflowMap[AzureInfra] = azureInfra
+	for _, p := range providers.List() {
+		workflowMap[fmt.Sprintf("%s%s", p.Name(), Infra)] = p.InfraSteps()
+	}
 
 	workflowMap[ProvisionMaster] = masterWorkflow
 	workflowMap[ProvisionNode] = nodeWorkflow
@@ -199,6 +199,7 @@ func RegisterWorkFlow(workflowName string, workflow Workflow) {
 	m.Lock()
 	defer m.Unlock()
 	workflowMap[workflowName] = workflow
+	delete(definitionMap, workflowName)
 }
 
 func GetWorkflow(workflowName string) Workflow {
@@ -206,3 +207,21 @@ func GetWorkflow(workflowName string) Workflow {
 	defer m.RUnlock()
 	return workflowMap[workflowName]
 }
+
+// SetStepConcurrency limits number of steps of a task running at the same
+// time, limit of one makes tasks run their steps strictly one by one.
+func SetStepConcurrency(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	stepConcurrency = limit
+}
+
+func getStepConcurrency() int {
+	m.RLock()
+	defer m.RUnlock()
+	return stepConcurrency
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/supergiant/control/pkg/storage"
+	"github.com/supergiant/control/pkg/storage/etcd"
+	"github.com/supergiant/control/pkg/storage/storagetest"
+)
+
+const etcdEndpoint = "localhost:2379"
+
+func TestETCDConformance(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", etcdEndpoint, time.Second)
+	if err != nil {
+		t.Skipf("etcd is not available at %s: %v", etcdEndpoint, err)
+	}
+	conn.Close()
+
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return etcd.NewETCDRepository(etcd.Config{
+			Endpoints:   []string{etcdEndpoint},
+			DialTimeout: etcd.DefaultDialTimeout,
+		})
+	}, fmt.Sprintf("/conformance/%d/", time.Now().UnixNano()))
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/supergiant/control/pkg/sgerrors"
 	"github.com/supergiant/control/pkg/storage/file"
+	"github.com/supergiant/control/pkg/storage/watch"
 )
 
 const (
@@ -65,3 +66,56 @@ func TestStorageE2E(t *testing.T) {
 	resultSlice, err := s.GetAll(ctx, "NO_SUCH_PREFIX")
 	require.Empty(t, resultSlice)
 }
+
+func TestStorageWatchE2E(t *testing.T) {
+	s, err := file.NewFileRepository(fmt.Sprintf("/tmp/sg-storage-%d", time.Now().UnixNano()))
+	require.Nil(t, err, "setup file storage provider")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	events, err := s.Watch(ctx, testPrefix)
+	require.NoError(t, err)
+
+	err = s.Put(ctx, testPrefix, "1", []byte("test"))
+	require.NoError(t, err)
+
+	err = s.Delete(ctx, testPrefix, "1")
+	require.NoError(t, err)
+
+	e := <-events
+	require.Equal(t, watch.Put, e.Type)
+	require.Equal(t, testPrefix+"1", e.Key)
+	require.Equal(t, "test", string(e.Value))
+
+	e = <-events
+	require.Equal(t, watch.Delete, e.Type)
+	require.Equal(t, testPrefix+"1", e.Key)
+}
+
+func TestStorageRevisionE2E(t *testing.T) {
+	s, err := file.NewFileRepository(fmt.Sprintf("/tmp/sg-storage-%d", time.Now().UnixNano()))
+	require.Nil(t, err, "setup file storage provider")
+
+	ctx := context.Background()
+
+	rev, err := s.PutIfRevision(ctx, testPrefix, "1", []byte("test"), 0)
+	require.NoError(t, err)
+
+	_, err = s.PutIfRevision(ctx, testPrefix, "1", []byte("test"), 0)
+	require.True(t, sgerrors.IsConflict(err))
+
+	err = s.Put(ctx, testPrefix, "1", []byte("test2"))
+	require.NoError(t, err)
+
+	_, err = s.PutIfRevision(ctx, testPrefix, "1", []byte("test3"), rev)
+	require.True(t, sgerrors.IsConflict(err))
+
+	value, current, err := s.GetWithRevision(ctx, testPrefix, "1")
+	require.NoError(t, err)
+	require.Equal(t, "test2", string(value))
+	require.True(t, current > rev)
+
+	_, err = s.PutIfRevision(ctx, testPrefix, "1", []byte("test3"), current)
+	require.NoError(t, err)
+}